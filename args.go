@@ -0,0 +1,100 @@
+package pgxscan
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgtype"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// InsertArgs returns the columns and values of the struct src, see Scanner.InsertArgs.
+func InsertArgs(src interface{}) (columns []string, args []interface{}, err error) {
+	return defaultScanner.InsertArgs(src)
+}
+
+// InsertArgs returns the columns of the struct src, named the way the Scanner maps them,
+// and the matching field values, in field order. They are meant to build an INSERT statement.
+//
+// Fields of embedded structs are columns of src, while fields of other nested structs are not.
+// Fields of a nil embedded pointer are NULL.
+func (s *Scanner) InsertArgs(src interface{}) (columns []string, args []interface{}, err error) {
+	v := reflect.Indirect(reflect.ValueOf(src))
+	if v.Kind() != reflect.Struct {
+		return nil, nil, errors.Errorf("expected a struct or a pointer to a struct, got %T", src)
+	}
+
+	fields := s.columnFields(v.Type())
+	return columnNames(fields), columnValues(v, fields), nil
+}
+
+// columnFields returns the fields of the struct type t holding a column value.
+func (s *Scanner) columnFields(t reflect.Type) []*reflectx.FieldInfo {
+	var fields []*reflectx.FieldInfo
+	for _, fi := range s.mapper().TypeMap(t).Index {
+		if fi.Embedded || strings.Contains(fi.Path, ".") || !isColumnType(fi.Field.Type) {
+			continue
+		}
+		fields = append(fields, fi)
+	}
+	return fields
+}
+
+// columnNames returns the columns of fields, as returned by columnFields.
+func columnNames(fields []*reflectx.FieldInfo) []string {
+	columns := make([]string, len(fields))
+	for i, fi := range fields {
+		columns[i] = fi.Path
+	}
+	return columns
+}
+
+// columnValues returns the values of fields in the struct v, NULL for fields of nil embedded pointers.
+func columnValues(v reflect.Value, fields []*reflectx.FieldInfo) []interface{} {
+	values := make([]interface{}, len(fields))
+	for i, fi := range fields {
+		if f, ok := fieldByIndexes(v, fi.Index); ok {
+			values[i] = f.Interface()
+		}
+	}
+	return values
+}
+
+var (
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textEncoderType   = reflect.TypeOf((*pgtype.TextEncoder)(nil)).Elem()
+	binaryEncoderType = reflect.TypeOf((*pgtype.BinaryEncoder)(nil)).Elem()
+)
+
+// isColumnType reports whether a field of type t holds a single column value, as opposed
+// to a struct whose fields are mapped to columns themselves.
+func isColumnType(t reflect.Type) bool {
+	t = reflectx.Deref(t)
+	if t.Kind() != reflect.Struct || t == timeType {
+		return true
+	}
+
+	for _, i := range []reflect.Type{valuerType, textEncoderType, binaryEncoderType} {
+		if t.Implements(i) || reflect.PtrTo(t).Implements(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndexes returns the field of v found by the traversal indexes, without allocating
+// nil pointers along the way. It returns false if the traversal goes through a nil pointer.
+func fieldByIndexes(v reflect.Value, indexes []int) (reflect.Value, bool) {
+	for _, i := range indexes {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
@@ -0,0 +1,63 @@
+package pgxscan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSnakeEntity struct {
+	ID        string
+	SomeData  string
+	CreatedAt time.Time
+}
+
+func TestScannerInsertArgs(t *testing.T) {
+	conn := connect(t)
+
+	scanner := New(WithNameMapper(SnakeCase))
+
+	e := testSnakeEntity{
+		ID:        "insert-args-1",
+		SomeData:  "foo bar baz",
+		CreatedAt: time.Now(),
+	}
+	columns, args, err := scanner.InsertArgs(&e)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "some_data", "created_at"}, columns)
+	assert.Equal(t, []interface{}{e.ID, e.SomeData, e.CreatedAt}, args)
+
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	_, err = conn.Exec(
+		context.Background(),
+		fmt.Sprintf("INSERT INTO structscan_test (%s) VALUES (%s)", strings.Join(columns, ", "), strings.Join(placeholders, ", ")),
+		args...,
+	)
+	require.NoError(t, err)
+
+	result := new(testSnakeEntity)
+	err = scanner.Get(context.Background(), conn, result, "SELECT * FROM structscan_test WHERE id = $1", e.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e.ID, result.ID)
+	assert.Equal(t, e.SomeData, result.SomeData)
+	// compare unit timestamp to avoid milliseconds diff
+	assert.Equal(t, e.CreatedAt.Unix(), result.CreatedAt.Unix())
+
+	// the package-level functions keep lowercasing untagged fields
+	columns, _, err = InsertArgs(e)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "somedata", "createdat"}, columns)
+
+	// test some fail cases
+	_, _, err = scanner.InsertArgs("foo")
+	require.Error(t, err)
+	assert.Equal(t, "expected a struct or a pointer to a struct, got string", err.Error())
+}
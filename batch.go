@@ -0,0 +1,64 @@
+package pgxscan
+
+import (
+	"reflect"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// ScanBatch scans the results of the queries queued in a pgx.Batch into dests, in order, and closes br.
+// See Scanner.ScanBatch.
+func ScanBatch(br pgx.BatchResults, dests ...interface{}) error {
+	return defaultScanner.ScanBatch(br, dests...)
+}
+
+// ScanBatch scans the result of each query queued in a pgx.Batch into the dest at the same
+// position, passed by reference, and closes br. The way a result is scanned depends on its dest:
+//   - a slice of structs, or pointers to structs, is scanned like with ScanStructs,
+//   - another slice is scanned like with ScanFlat,
+//   - a struct is scanned like with ScanStruct,
+//   - any other value is scanned like with GetFlat,
+//   - a nil dest skips the result of a query executed for its side effects.
+//
+// The first failing query stops the scan, and its error is returned.
+func (s *Scanner) ScanBatch(br pgx.BatchResults, dests ...interface{}) error {
+	for i, dest := range dests {
+		if err := s.scanBatchResult(br, dest); err != nil {
+			br.Close()
+			return errors.Wrapf(err, "failed to scan the result of query %d", i)
+		}
+	}
+	return br.Close()
+}
+
+func (s *Scanner) scanBatchResult(br pgx.BatchResults, dest interface{}) error {
+	if dest == nil {
+		_, err := br.Exec()
+		return err
+	}
+
+	t := reflect.TypeOf(dest)
+	if t.Kind() != reflect.Ptr {
+		return errors.Errorf("expected a pointer, got %T", dest)
+	}
+
+	rows, err := br.Query()
+	if err != nil {
+		return err
+	}
+
+	t = t.Elem()
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		if isColumnType(t.Elem()) {
+			return s.ScanFlat(rows, dest)
+		}
+		return s.ScanStructs(rows, dest)
+	case !isColumnType(t):
+		return s.ScanStruct(rows, dest)
+	default:
+		defer rows.Close()
+		return scanValue(rows, dest)
+	}
+}
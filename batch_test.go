@@ -0,0 +1,55 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanBatch(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	batch := &pgx.Batch{}
+	batch.Queue("SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	batch.Queue("SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	batch.Queue("SELECT id FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	batch.Queue("SELECT count(*) FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	batch.Queue("UPDATE structscan_test SET some_data = some_data WHERE id = $1", e1.ID)
+
+	var (
+		entities []*testEntity
+		entity   testEntity
+		ids      []string
+		count    int64
+	)
+	err := ScanBatch(conn.SendBatch(context.Background(), batch), &entities, &entity, &ids, &count, nil)
+	require.NoError(t, err)
+	require.Len(t, entities, 2)
+	assert.Equal(t, e1.ID, entities[0].ID)
+	assert.Equal(t, e2.ID, entities[1].ID)
+	assert.Equal(t, e1.ID, entity.ID)
+	assert.Equal(t, e1.SomeData, entity.SomeData)
+	assert.Equal(t, []string{e1.ID, e2.ID}, ids)
+	assert.Equal(t, int64(2), count)
+
+	// test some fail cases
+	batch = &pgx.Batch{}
+	batch.Queue("SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	batch.Queue("SELECT * FROM structscan_test WHERE id = $1", "foo")
+	err = ScanBatch(conn.SendBatch(context.Background(), batch), &entity, &entity)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+	assert.Contains(t, err.Error(), "failed to scan the result of query 1")
+
+	batch = &pgx.Batch{}
+	batch.Queue("SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	err = ScanBatch(conn.SendBatch(context.Background(), batch), entity)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a pointer")
+}
@@ -0,0 +1,117 @@
+package pgxscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"reflect"
+
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// blobChunkSize is the size of the chunks blobs are written and read in.
+const blobChunkSize = 64 * 1024
+
+var writerType = reflect.TypeOf((*io.Writer)(nil)).Elem()
+
+// blobTarget returns the scan destination of the field f and the conversion writing the column
+// to it, if f is an io.Writer, such as an *os.File set before Get or ScanStruct, and oid the bytea
+// type. This is not streaming: pgx reads the whole value into the row buffer first, only the
+// extra copy into a []byte is saved, the content being written in chunks from the row, decoded
+// from hex in the text format. Values too large to hold in memory belong in large objects, read
+// with ReadLargeObject. NULL writes nothing, and a nil writer fails.
+func blobTarget(f reflect.Value, oid uint32) (interface{}, func() error, bool) {
+	if oid != pgtype.ByteaOID || f.Type() != writerType {
+		return nil, nil, false
+	}
+
+	raw := new(rawValue)
+	return raw, func() error {
+		if raw.src == nil {
+			return nil
+		}
+		if f.IsNil() {
+			return errors.New("cannot write bytea into a nil io.Writer")
+		}
+		return writeBlob(f.Interface().(io.Writer), raw.format, raw.src)
+	}, true
+}
+
+// writeBlob writes the bytea src, in format, to w in chunks of blobChunkSize bytes.
+func writeBlob(w io.Writer, format int16, src []byte) error {
+	if format == pgtype.BinaryFormatCode {
+		for len(src) > 0 {
+			n := len(src)
+			if n > blobChunkSize {
+				n = blobChunkSize
+			}
+			if _, err := w.Write(src[:n]); err != nil {
+				return errors.Wrap(err, "failed to write bytea")
+			}
+			src = src[n:]
+		}
+		return nil
+	}
+
+	if !bytes.HasPrefix(src, []byte(`\x`)) {
+		return errors.New("unsupported bytea text format, expected hex")
+	}
+	src = src[2:]
+	buf := make([]byte, blobChunkSize)
+	for len(src) > 0 {
+		n := len(src)
+		if n > 2*blobChunkSize {
+			n = 2 * blobChunkSize
+		}
+		decoded, err := hex.Decode(buf, src[:n])
+		if err != nil {
+			return errors.Wrap(err, "failed to decode bytea")
+		}
+		if _, err := w.Write(buf[:decoded]); err != nil {
+			return errors.Wrap(err, "failed to write bytea")
+		}
+		src = src[n:]
+	}
+	return nil
+}
+
+// ReadLargeObject writes the content of the large object oid to w, reading it in chunks so it is
+// never held in memory, and returns the number of bytes written. Large objects are only accessible
+// in a transaction.
+func ReadLargeObject(ctx context.Context, tx pgx.Tx, oid uint32, w io.Writer) (int64, error) {
+	los := tx.LargeObjects()
+	lo, err := los.Open(ctx, oid, pgx.LargeObjectModeRead)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open large object %d", oid)
+	}
+
+	n, err := io.CopyBuffer(w, lo, make([]byte, blobChunkSize))
+	if err != nil {
+		lo.Close()
+		return n, errors.Wrapf(err, "failed to read large object %d", oid)
+	}
+	return n, lo.Close()
+}
+
+// WriteLargeObject creates a large object with the content of r, writing it in chunks, and
+// returns its oid, to be stored in an oid column.
+func WriteLargeObject(ctx context.Context, tx pgx.Tx, r io.Reader) (uint32, error) {
+	los := tx.LargeObjects()
+	oid, err := los.Create(ctx, 0)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create large object")
+	}
+	lo, err := los.Open(ctx, oid, pgx.LargeObjectModeWrite)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open large object %d", oid)
+	}
+
+	if _, err := io.CopyBuffer(lo, r, make([]byte, blobChunkSize)); err != nil {
+		lo.Close()
+		return 0, errors.Wrapf(err, "failed to write large object %d", oid)
+	}
+	return oid, lo.Close()
+}
@@ -0,0 +1,114 @@
+package pgxscan
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBlobEntity struct {
+	ID      string    `db:"id"`
+	Content io.Writer `db:"content"`
+}
+
+func TestScanStructBlob(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "blob_test", `
+		id      text PRIMARY KEY,
+		content bytea
+	`)
+	content := bytes.Repeat([]byte("0123456789"), blobChunkSize/5)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO blob_test (id, content) VALUES ('blob-1', $1), ('blob-2', NULL)
+	`, content)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	dest := testBlobEntity{Content: &buf}
+	err = Get(context.Background(), conn, &dest, "SELECT * FROM blob_test WHERE id = 'blob-1'")
+	require.NoError(t, err)
+	assert.Equal(t, "blob-1", dest.ID)
+	assert.Equal(t, content, buf.Bytes())
+
+	buf.Reset()
+	err = Get(context.Background(), conn, &dest, "SELECT * FROM blob_test WHERE id = 'blob-2'")
+	require.NoError(t, err)
+	assert.Equal(t, 0, buf.Len())
+
+	// large objects
+	tx, err := conn.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback(context.Background())
+
+	oid, err := WriteLargeObject(context.Background(), tx, bytes.NewReader(content))
+	require.NoError(t, err)
+
+	buf.Reset()
+	n, err := ReadLargeObject(context.Background(), tx, oid, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, buf.Bytes())
+
+	// test some fail cases
+	err = Get(context.Background(), conn, new(testBlobEntity), "SELECT * FROM blob_test WHERE id = 'blob-1'")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot write bytea into a nil io.Writer")
+
+	_, err = ReadLargeObject(context.Background(), tx, oid+1000, &buf)
+	require.Error(t, err)
+}
+
+func TestScanStructBlobFakeRows(t *testing.T) {
+	rows := newFakeRows(0)
+	rows.fields = []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("content"), DataTypeOID: pgtype.ByteaOID},
+	}
+	rows.rows = [][][]byte{{[]byte("blob-1"), []byte(`\x68656c6c6f`)}}
+
+	var buf bytes.Buffer
+	dest := testBlobEntity{Content: &buf}
+	err := ScanStruct(rows, &dest)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", buf.String())
+}
+
+func TestWriteBlob(t *testing.T) {
+	content := bytes.Repeat([]byte("abc"), blobChunkSize)
+
+	var binary, text chunkWriter
+	require.NoError(t, writeBlob(&binary, pgtype.BinaryFormatCode, content))
+	assert.Equal(t, content, binary.Bytes())
+	assert.Equal(t, 3, binary.writes)
+
+	require.NoError(t, writeBlob(&text, pgtype.TextFormatCode, []byte(`\x`+strings.Repeat("616263", blobChunkSize))))
+	assert.Equal(t, content, text.Bytes())
+	assert.Equal(t, 3, text.writes)
+
+	// test some fail cases
+	err := writeBlob(new(chunkWriter), pgtype.TextFormatCode, []byte(`abc`))
+	require.Error(t, err)
+	assert.Equal(t, "unsupported bytea text format, expected hex", err.Error())
+
+	err = writeBlob(new(chunkWriter), pgtype.TextFormatCode, []byte(`\xzz`))
+	require.Error(t, err)
+}
+
+// chunkWriter counts the writes to its buffer.
+type chunkWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
@@ -0,0 +1,57 @@
+package pgxscan
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+)
+
+// Sqlizer is implemented by the query builders, such as squirrel's or goqu's, returning the
+// query they build and its arguments. The query must use $N placeholders, such as the ones of
+// squirrel's Dollar placeholder format; Rebind converts ? ones.
+type Sqlizer interface {
+	ToSql() (string, []interface{}, error)
+}
+
+// GetBuilder works like Get, running the query built by builder.
+func GetBuilder(ctx context.Context, querier Querier, dest interface{}, builder Sqlizer) error {
+	return defaultScanner.GetBuilder(ctx, querier, dest, builder)
+}
+
+// SelectBuilder works like Select, running the query built by builder.
+func SelectBuilder(ctx context.Context, querier Querier, dest interface{}, builder Sqlizer) error {
+	return defaultScanner.SelectBuilder(ctx, querier, dest, builder)
+}
+
+// ExecBuilder executes the query built by builder.
+func ExecBuilder(ctx context.Context, querier Querier, builder Sqlizer) (pgconn.CommandTag, error) {
+	return defaultScanner.ExecBuilder(ctx, querier, builder)
+}
+
+// GetBuilder works like the package-level GetBuilder, using the Scanner options.
+func (s *Scanner) GetBuilder(ctx context.Context, querier Querier, dest interface{}, builder Sqlizer) error {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return errors.Wrap(err, "failed to build the query")
+	}
+	return s.Get(ctx, querier, dest, query, args...)
+}
+
+// SelectBuilder works like the package-level SelectBuilder, using the Scanner options.
+func (s *Scanner) SelectBuilder(ctx context.Context, querier Querier, dest interface{}, builder Sqlizer) error {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return errors.Wrap(err, "failed to build the query")
+	}
+	return s.Select(ctx, querier, dest, query, args...)
+}
+
+// ExecBuilder works like the package-level ExecBuilder, using the Scanner options.
+func (s *Scanner) ExecBuilder(ctx context.Context, querier Querier, builder Sqlizer) (pgconn.CommandTag, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build the query")
+	}
+	return s.exec(ctx, querier, query, args)
+}
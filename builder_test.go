@@ -0,0 +1,69 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBuilder is a Sqlizer returning a fixed query, like a query builder would build it.
+type testBuilder struct {
+	query string
+	args  []interface{}
+	err   error
+}
+
+func (b testBuilder) ToSql() (string, []interface{}, error) {
+	return b.query, b.args, b.err
+}
+
+func TestBuilder(t *testing.T) {
+	conn := connect(t)
+
+	e := testEntity{
+		ID:        "builder-1-" + time.Now().String(),
+		CreatedAt: time.Now(),
+		SomeData:  "foo bar baz",
+	}
+
+	tag, err := ExecBuilder(context.Background(), conn, testBuilder{
+		query: "INSERT INTO structscan_test (id, some_data, created_at) VALUES ($1, $2, $3)",
+		args:  []interface{}{e.ID, e.SomeData, e.CreatedAt},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tag.RowsAffected())
+
+	var result testEntity
+	err = GetBuilder(context.Background(), conn, &result, testBuilder{
+		query: "SELECT * FROM structscan_test WHERE id = $1",
+		args:  []interface{}{e.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, e.ID, result.ID)
+	assert.Equal(t, e.SomeData, result.SomeData)
+
+	var results []testEntity
+	err = New(WithMaxRows(1)).SelectBuilder(context.Background(), conn, &results, testBuilder{
+		query: Rebind("SELECT * FROM structscan_test WHERE id = ?"),
+		args:  []interface{}{e.ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, e.ID, results[0].ID)
+
+	// test some fail cases
+	errBuild := errors.New("no table")
+	err = GetBuilder(context.Background(), conn, &result, testBuilder{err: errBuild})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errBuild))
+
+	err = SelectBuilder(context.Background(), conn, &results, testBuilder{err: errBuild})
+	require.Error(t, err)
+
+	_, err = ExecBuilder(context.Background(), conn, testBuilder{err: errBuild})
+	require.Error(t, err)
+}
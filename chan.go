@@ -0,0 +1,41 @@
+package pgxscan
+
+import (
+	"context"
+)
+
+// SelectChan scans the rows of the query result into new Ts in a goroutine, T being a struct
+// type, and sends them on the returned channel, closed once the rows are exhausted.
+// The error channel then receives the error the query or a scan failed with, if any, and is closed.
+//
+// Cancelling ctx stops the goroutine and closes the rows, even if nothing receives from the channel.
+func SelectChan[T any](ctx context.Context, querier Querier, query string, args ...interface{}) (<-chan T, <-chan error) {
+	results := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(results)
+
+		rows, err := defaultScanner.query(ctx, querier, query, args)
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		var dest T
+		err = ScanEach(rows, &dest, func() error {
+			select {
+			case results <- dest:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return results, errs
+}
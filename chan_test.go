@@ -0,0 +1,38 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectChan(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	results, errs := SelectChan[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	var ids []string
+	for result := range results {
+		ids = append(ids, result.ID)
+	}
+	require.NoError(t, <-errs)
+	assert.Equal(t, []string{e1.ID, e2.ID}, ids)
+
+	// test some fail cases
+	missingResults, errs := SelectChan[testMissingField](context.Background(), conn, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	for range missingResults {
+		t.Fatal("no row is expected")
+	}
+	require.Error(t, <-errs)
+
+	// cancelling stops the scan without anything receiving the rows
+	ctx, cancel := context.WithCancel(context.Background())
+	results, errs = SelectChan[testEntity](ctx, conn, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	cancel()
+	require.Error(t, <-errs)
+	_, ok := <-results
+	assert.False(t, ok)
+}
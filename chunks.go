@@ -0,0 +1,116 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// SelectChunks scans the query result in chunks of chunkSize rows, see ScanChunks.
+func SelectChunks(ctx context.Context, querier Querier, dest interface{}, chunkSize int, fn func(chunk interface{}) error, query string, args ...interface{}) error {
+	return defaultScanner.SelectChunks(ctx, querier, dest, chunkSize, fn, query, args...)
+}
+
+// SelectChunks works like the package-level SelectChunks, using the Scanner options.
+func (s *Scanner) SelectChunks(ctx context.Context, querier Querier, dest interface{}, chunkSize int, fn func(chunk interface{}) error, query string, args ...interface{}) error {
+	rows, err := s.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	return s.scanChunks(ctx, rows, dest, chunkSize, fn)
+}
+
+// ScanChunks scans the rows of r into slices of up to chunkSize structs and calls fn with each
+// of them, so large results can be processed in batches. dest is a pointer to a slice of structs
+// or pointers to structs, like for ScanStructs, set to each chunk before fn is called with it.
+func ScanChunks(r pgx.Rows, dest interface{}, chunkSize int, fn func(chunk interface{}) error) error {
+	return defaultScanner.ScanChunks(r, dest, chunkSize, fn)
+}
+
+// ScanChunks works like the package-level ScanChunks, using the Scanner options.
+//
+// Every chunk is a new slice, so fn may retain it. All chunks but the last have chunkSize rows,
+// and fn is not called for an empty result. The first error returned by fn stops the scan and is
+// returned as is.
+func (s *Scanner) ScanChunks(r pgx.Rows, dest interface{}, chunkSize int, fn func(chunk interface{}) error) error {
+	return s.scanChunks(context.Background(), r, dest, chunkSize, fn)
+}
+
+// scanChunks works like ScanChunks, passing ctx to the AfterScan method of the structs, if any.
+func (s *Scanner) scanChunks(ctx context.Context, r pgx.Rows, dest interface{}, chunkSize int, fn func(chunk interface{}) error) error {
+	defer r.Close()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if chunkSize <= 0 {
+		return errors.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.IsNil() || destVal.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("expected a pointer to a slice, got %T", dest)
+	}
+	sliceVal := destVal.Elem()
+	sliceType := sliceVal.Type()
+	structType := sliceType.Elem()
+	isPtr := structType.Kind() == reflect.Ptr
+	if isPtr {
+		structType = structType.Elem()
+	}
+
+	var (
+		columns []string
+		oids    []uint32
+		fields  [][]int
+		decoder *rowDecoder
+		err     error
+	)
+	chunk := reflect.MakeSlice(sliceType, 0, chunkSize)
+	flush := func() error {
+		sliceVal.Set(chunk)
+		if err := fn(chunk.Interface()); err != nil {
+			return err
+		}
+		chunk = reflect.MakeSlice(sliceType, 0, chunkSize)
+		return nil
+	}
+
+	for row := 0; r.Next(); row++ {
+		v := reflect.New(structType)
+		if columns == nil {
+			columns, fields, err = s.rowMetadata(r, v)
+			if err != nil {
+				return err
+			}
+			oids = columnOIDs(r)
+			decoder = newRowDecoder(oids)
+		}
+
+		if err := s.scanRow(ctx, r, row, v, columns, oids, fields, decoder); err != nil {
+			return err
+		}
+
+		if isPtr {
+			chunk = reflect.Append(chunk, v)
+		} else {
+			chunk = reflect.Append(chunk, v.Elem())
+		}
+		if chunk.Len() == chunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := r.Err(); err != nil {
+		return err
+	}
+	if chunk.Len() > 0 {
+		return flush()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectChunks(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	var (
+		dest   []*testEntity
+		chunks [][]*testEntity
+	)
+	err := SelectChunks(context.Background(), conn, &dest, 1, func(chunk interface{}) error {
+		chunks = append(chunks, chunk.([]*testEntity))
+		return nil
+	}, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, e1.ID, chunks[0][0].ID)
+	assert.Equal(t, e2.ID, chunks[1][0].ID)
+
+	// test some fail cases
+	errStop := errors.New("stop")
+	err = SelectChunks(context.Background(), conn, &dest, 1, func(chunk interface{}) error {
+		return errStop
+	}, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	assert.Equal(t, errStop, err)
+
+	var destMissing []testMissingField
+	err = SelectChunks(context.Background(), conn, &destMissing, 1, func(chunk interface{}) error {
+		return nil
+	}, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.Error(t, err)
+}
+
+func TestScanChunks(t *testing.T) {
+	var (
+		dest  []testEntity
+		sizes []int
+	)
+	err := ScanChunks(newFakeRows(5), &dest, 2, func(chunk interface{}) error {
+		sizes = append(sizes, len(chunk.([]testEntity)))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	require.Len(t, dest, 1)
+	assert.Equal(t, "bench-4", dest[0].ID)
+
+	sizes = nil
+	err = ScanChunks(newFakeRows(0), &dest, 2, func(chunk interface{}) error {
+		sizes = append(sizes, len(chunk.([]testEntity)))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, sizes)
+
+	// test some fail cases
+	err = ScanChunks(newFakeRows(5), &dest, 0, func(chunk interface{}) error { return nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chunk size must be positive")
+
+	err = ScanChunks(newFakeRows(5), dest, 2, func(chunk interface{}) error { return nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a pointer to a slice")
+}
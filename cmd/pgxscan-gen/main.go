@@ -0,0 +1,240 @@
+// Command pgxscan-gen generates scanners for structs that scan pgx rows with an explicit
+// switch on the column names instead of reflection, and registers them with
+// pgxscan.RegisterScanner so that Get, Select and the other functions scanning structs use them.
+//
+// The structs to generate scanners for are annotated with a pgxscan:generate comment:
+//
+//	//go:generate go run github.com/pyr-sh/pgxscan/v2/cmd/pgxscan-gen
+//
+//	//pgxscan:generate
+//	type User struct {
+//		ID   int64  `db:"id"`
+//		Name string `db:"name"`
+//	}
+//
+// For each of them, it writes a ScanUser method scanning the current row into the struct to
+// pgxscan_gen.go, in the package directory. Columns are named like pgxscan names them without
+// options: after the db tag, or the lowercased field name. Embedded structs and fields with the
+// json option are not supported.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const annotation = "pgxscan:generate"
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package to generate scanners for")
+	output := flag.String("output", "pgxscan_gen.go", "name of the file to write in the package directory")
+	tag := flag.String("tag", "db", "name of the struct tag naming columns")
+	flag.Parse()
+
+	if err := run(*dir, *output, *tag); err != nil {
+		fmt.Fprintln(os.Stderr, "pgxscan-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// structInfo describes an annotated struct.
+type structInfo struct {
+	Name   string
+	Fields []fieldInfo
+}
+
+// fieldInfo describes a field of an annotated struct and the column it maps to.
+type fieldInfo struct {
+	Name   string
+	Column string
+}
+
+// run writes the scanners of the structs annotated in the package in dir to output.
+func run(dir, output, tag string) error {
+	pkg, structs, err := parseDir(dir, output, tag)
+	if err != nil {
+		return err
+	}
+	if len(structs) == 0 {
+		return fmt.Errorf("no struct annotated with %s in %s", annotation, dir)
+	}
+
+	src, err := generate(pkg, structs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+// parseDir returns the name of the package in dir and its annotated structs, skipping the
+// tests and output.
+func parseDir(dir, output, tag string) (string, []structInfo, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	sort.Strings(paths)
+
+	var (
+		pkg     string
+		structs []structInfo
+	)
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == output {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return "", nil, err
+		}
+		pkg = file.Name.Name
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || !(annotated(ts.Doc) || (len(gen.Specs) == 1 && annotated(gen.Doc))) {
+					continue
+				}
+				info, err := structFields(ts.Name.Name, st, tag)
+				if err != nil {
+					return "", nil, err
+				}
+				structs = append(structs, info)
+			}
+		}
+	}
+	return pkg, structs, nil
+}
+
+// annotated reports whether doc has the annotation line.
+func annotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(strings.TrimPrefix(c.Text, "//")) == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+// structFields returns the fields of the struct st named name mapped to columns.
+func structFields(name string, st *ast.StructType, tag string) (structInfo, error) {
+	info := structInfo{Name: name}
+	columns := make(map[string]string)
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			return info, fmt.Errorf("embedded field %s of %s is not supported", exprString(field.Type), name)
+		}
+
+		var tagValue string
+		if field.Tag != nil {
+			unquoted, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return info, fmt.Errorf("invalid tag of %s: %v", name, err)
+			}
+			tagValue = reflect.StructTag(unquoted).Get(tag)
+		}
+		if tagValue == "-" {
+			continue
+		}
+		column, options, _ := strings.Cut(tagValue, ",")
+		for _, option := range strings.Split(options, ",") {
+			if option == "json" {
+				return info, fmt.Errorf("json option of %s.%s is not supported", name, field.Names[0].Name)
+			}
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			fieldColumn := column
+			if fieldColumn == "" {
+				fieldColumn = strings.ToLower(ident.Name)
+			}
+			if other, ok := columns[fieldColumn]; ok {
+				return info, fmt.Errorf("fields %s and %s of %s map to the same column %q", other, ident.Name, name, fieldColumn)
+			}
+			columns[fieldColumn] = ident.Name
+			info.Fields = append(info.Fields, fieldInfo{Name: ident.Name, Column: fieldColumn})
+		}
+	}
+	if len(info.Fields) == 0 {
+		return info, fmt.Errorf("no field of %s maps to a column", name)
+	}
+	return info, nil
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by pgxscan-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+func init() {
+{{- range .Structs}}
+	pgxscan.RegisterScanner(func(r pgx.Rows, dest *{{.Name}}) error { return dest.Scan{{.Name}}(r) })
+{{- end}}
+}
+{{range .Structs}}
+// Scan{{.Name}} scans the current row of r into dest.
+func (dest *{{.Name}}) Scan{{.Name}}(r pgx.Rows) error {
+	fields := r.FieldDescriptions()
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		switch string(field.Name) {
+		{{- range .Fields}}
+		case {{printf "%q" .Column}}:
+			values[i] = &dest.{{.Name}}
+		{{- end}}
+		default:
+			return fmt.Errorf("missing column %q in dest %T", field.Name, dest)
+		}
+	}
+	return r.Scan(values...)
+}
+{{end}}`))
+
+// generate returns the formatted source of the scanners of structs, in the package pkg.
+func generate(pkg string, structs []structInfo) ([]byte, error) {
+	var buf bytes.Buffer
+	err := fileTemplate.Execute(&buf, struct {
+		Package string
+		Structs []structInfo
+	}{pkg, structs})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
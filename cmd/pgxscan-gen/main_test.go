@@ -0,0 +1,89 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSource(t *testing.T, src string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(src), 0o644)
+	require.NoError(t, err)
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := writeSource(t, `package models
+
+import "time"
+
+//pgxscan:generate
+type User struct {
+	ID        int64     `+"`db:\"id,pk\"`"+`
+	Name      string
+	Email     *string   `+"`db:\"email\"`"+`
+	CreatedAt time.Time `+"`db:\"created_at\"`"+`
+	Ignored   string    `+"`db:\"-\"`"+`
+	internal  string
+}
+
+type (
+	//pgxscan:generate
+	Tag struct {
+		Name string `+"`db:\"name\"`"+`
+	}
+
+	Skipped struct {
+		Name string `+"`db:\"name\"`"+`
+	}
+)
+`)
+	err := run(dir, "pgxscan_gen.go", "db")
+	require.NoError(t, err)
+
+	src, err := os.ReadFile(filepath.Join(dir, "pgxscan_gen.go"))
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "pgxscan_gen.go", src, 0)
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.Contains(t, out, "package models")
+	assert.Contains(t, out, "pgxscan.RegisterScanner(func(r pgx.Rows, dest *User) error { return dest.ScanUser(r) })")
+	assert.Contains(t, out, "func (dest *User) ScanUser(r pgx.Rows) error {")
+	assert.Contains(t, out, "case \"id\":\n\t\t\tvalues[i] = &dest.ID")
+	assert.Contains(t, out, "case \"name\":\n\t\t\tvalues[i] = &dest.Name")
+	assert.Contains(t, out, "case \"created_at\":\n\t\t\tvalues[i] = &dest.CreatedAt")
+	assert.Contains(t, out, "func (dest *Tag) ScanTag(r pgx.Rows) error {")
+	assert.NotContains(t, out, "Ignored")
+	assert.NotContains(t, out, "internal")
+	assert.NotContains(t, out, "Skipped")
+
+	// the generated file is skipped when generating again
+	err = run(dir, "pgxscan_gen.go", "db")
+	require.NoError(t, err)
+
+	// test some fail cases
+	err = run(writeSource(t, "package models\n\ntype User struct{}\n"), "pgxscan_gen.go", "db")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no struct annotated")
+
+	err = run(writeSource(t, "package models\n\n//pgxscan:generate\ntype User struct {\n\tBase\n}\n\ntype Base struct{}\n"), "pgxscan_gen.go", "db")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "embedded field Base")
+
+	err = run(writeSource(t, "package models\n\n//pgxscan:generate\ntype User struct {\n\tData []byte `db:\"data,json\"`\n}\n"), "pgxscan_gen.go", "db")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "json option")
+
+	err = run(writeSource(t, "package models\n\n//pgxscan:generate\ntype User struct {\n\tName string\n\tOther string `db:\"name\"`\n}\n"), "pgxscan_gen.go", "db")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "same column")
+}
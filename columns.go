@@ -0,0 +1,135 @@
+package pgxscan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgproto3/v2"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// ScanColumns scans the rows of r into dest, a pointer to a struct of slices, see Scanner.ScanColumns.
+func ScanColumns(r pgx.Rows, dest interface{}) error {
+	return defaultScanner.ScanColumns(r, dest)
+}
+
+// ScanColumns scans every column of r into the slice field of dest it maps to, such as
+// IDs []int64 `db:"id"`, one element per row, so that each column is stored contiguously for
+// analytics and vectorized processing. dest is a pointer to a struct, which slices are replaced,
+// empty if there are no rows. Elements are scanned like the fields of ScanStructs, with the
+// options of their field, such as json. AfterScan is called on dest once every row is scanned.
+//
+// Function call closes rows, so caller may skip it.
+func (s *Scanner) ScanColumns(r pgx.Rows, dest interface{}) error {
+	defer r.Close()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("expected a non-nil pointer to a struct, got %T", dest)
+	}
+
+	fieldDescriptions := r.FieldDescriptions()
+	tm := s.mapper().TypeMap(v.Type())
+	fields := make([]*reflectx.FieldInfo, len(fieldDescriptions))
+	slices := make([]reflect.Value, len(fieldDescriptions))
+	for i, fieldDescription := range fieldDescriptions {
+		column := string(fieldDescription.Name)
+		fi := tm.GetByPath(column)
+		if fi == nil {
+			if s.unsafe {
+				continue
+			}
+			return fmt.Errorf("missing column %q in dest %s", column, v.Type())
+		}
+		if fi.Field.Type.Kind() != reflect.Slice {
+			return errors.Errorf("field %s of column %q must be a slice, got %s", fi.Field.Name, column, fi.Field.Type)
+		}
+		fields[i] = fi
+		slices[i] = reflect.MakeSlice(fi.Field.Type, 0, s.capacity)
+	}
+
+	d := newRowDecoder(columnOIDs(r))
+	conversions := make([]func() error, len(fieldDescriptions))
+	elems := make([]reflect.Value, len(fieldDescriptions))
+	for row := 0; r.Next(); row++ {
+		if s.maxRows > 0 && row >= s.maxRows {
+			return ErrTooManyRows
+		}
+
+		for i, fi := range fields {
+			if fi == nil {
+				d.values[i] = discard{}
+				continue
+			}
+			elems[i] = reflect.New(fi.Field.Type.Elem()).Elem()
+			d.values[i], conversions[i] = s.elementTarget(fi, elems[i], fieldDescriptions[i].DataTypeOID)
+		}
+
+		if i, err := d.scan(r); err != nil {
+			return columnScanError(err, row, fields, fieldDescriptions, i)
+		}
+		for i, convert := range conversions {
+			if convert == nil {
+				continue
+			}
+			if err := convert(); err != nil {
+				return columnScanError(err, row, fields, fieldDescriptions, i)
+			}
+		}
+		for i, fi := range fields {
+			if fi != nil {
+				slices[i] = reflect.Append(slices[i], elems[i])
+			}
+		}
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	for i, fi := range fields {
+		if fi != nil {
+			reflectx.FieldByIndexes(v, fi.Index).Set(slices[i])
+		}
+	}
+	return afterScanHook(context.Background(), v)
+}
+
+// elementTarget returns the scan destination of f, an element of the slice field fi, scanned
+// from a column of type oid, and the conversion to run once the row is scanned, if any.
+func (s *Scanner) elementTarget(fi *reflectx.FieldInfo, f reflect.Value, oid uint32) (interface{}, func() error) {
+	if _, ok := fi.Options["json"]; ok {
+		return s.jsonTarget(f)
+	}
+	if fn, ok := s.converterFunc(f.Type()); ok {
+		return convertTarget(f, fn)
+	}
+	if target, convert, ok := s.compositeTarget(f, oid); ok {
+		return target, convert
+	}
+	return scanTarget(f, oid)
+}
+
+// columnScanError wraps err, the error scanning or converting the column of index i of the row
+// of index row into the slice fields, in a ScanError describing the column. i is negative if
+// the column is unknown.
+func columnScanError(err error, row int, fields []*reflectx.FieldInfo, fieldDescriptions []pgproto3.FieldDescription, i int) error {
+	scanErr := &ScanError{Row: row, Err: err}
+	if i < 0 {
+		return scanErr
+	}
+
+	scanErr.Column = string(fieldDescriptions[i].Name)
+	scanErr.OID = fieldDescriptions[i].DataTypeOID
+	if fields[i] != nil {
+		scanErr.Field = fields[i].Field.Name
+		scanErr.FieldType = fields[i].Field.Type.Elem()
+	}
+	return scanErr
+}
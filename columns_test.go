@@ -0,0 +1,137 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testColumns struct {
+	IDs       []int64             `db:"id"`
+	Names     []*string           `db:"name"`
+	Scores    []float64           `db:"score"`
+	Metadata  []map[string]string `db:"metadata,json"`
+	CreatedAt []time.Time         `db:"created_at"`
+}
+
+func TestScanColumns(t *testing.T) {
+	conn := connect(t)
+
+	query := `
+		SELECT * FROM (VALUES
+			(1, 'foo', 1.5, '{"a": "b"}'::jsonb, '2020-01-01 00:00:00+00'::timestamptz),
+			(2, NULL, 2.5, NULL, '2020-01-02 00:00:00+00'::timestamptz)
+		) AS t (id, name, score, metadata, created_at)
+		ORDER BY id ASC
+	`
+
+	rows, err := conn.Query(context.Background(), query)
+	require.NoError(t, err)
+
+	var result testColumns
+	err = ScanColumns(rows, &result)
+	require.NoError(t, err)
+	foo := "foo"
+	assert.Equal(t, []int64{1, 2}, result.IDs)
+	assert.Equal(t, []*string{&foo, nil}, result.Names)
+	assert.Equal(t, []float64{1.5, 2.5}, result.Scores)
+	assert.Equal(t, []map[string]string{{"a": "b"}, nil}, result.Metadata)
+	require.Len(t, result.CreatedAt, 2)
+	assert.True(t, result.CreatedAt[1].Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)))
+
+	// no rows replace the slices
+	rows, err = conn.Query(context.Background(), "SELECT 1::int8 AS id WHERE false")
+	require.NoError(t, err)
+	err = ScanColumns(rows, &result)
+	require.NoError(t, err)
+	assert.Empty(t, result.IDs)
+	assert.Len(t, result.Scores, 2)
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), "SELECT 1 AS id, 'foo' AS other")
+	require.NoError(t, err)
+	err = ScanColumns(rows, &result)
+	require.Error(t, err)
+	assert.Equal(t, `missing column "other" in dest *pgxscan.testColumns`, err.Error())
+
+	rows, err = conn.Query(context.Background(), "SELECT 1 AS id, 'foo' AS other")
+	require.NoError(t, err)
+	err = New(WithUnsafe()).ScanColumns(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, result.IDs)
+
+	rows, err = conn.Query(context.Background(), "SELECT 'foo' AS id")
+	require.NoError(t, err)
+	err = ScanColumns(rows, &result)
+	require.Error(t, err)
+	var scanErr *ScanError
+	require.True(t, errors.As(err, &scanErr))
+	assert.Equal(t, "id", scanErr.Column)
+	assert.Equal(t, "IDs", scanErr.Field)
+
+	rows, err = conn.Query(context.Background(), "SELECT 1 AS id")
+	require.NoError(t, err)
+	err = ScanColumns(rows, &struct {
+		ID int `db:"id"`
+	}{})
+	require.Error(t, err)
+
+	rows, err = conn.Query(context.Background(), "SELECT 1 AS id")
+	require.NoError(t, err)
+	err = ScanColumns(rows, result)
+	require.Error(t, err)
+}
+
+type testColumnsStats struct {
+	IDs   []string `db:"id"`
+	Count int      `db:"-"`
+}
+
+func (s *testColumnsStats) AfterScan(ctx context.Context) error {
+	s.Count = len(s.IDs)
+	return nil
+}
+
+func TestScanColumnsFakeRows(t *testing.T) {
+	rows := newFakeRows(3)
+	rows.rows[2][2] = nil
+
+	var result struct {
+		IDs       []string    `db:"id"`
+		CreatedAt []time.Time `db:"created_at"`
+		SomeData  []*string   `db:"some_data"`
+	}
+	err := ScanColumns(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bench-0", "bench-1", "bench-2"}, result.IDs)
+	assert.Len(t, result.CreatedAt, 3)
+	require.Len(t, result.SomeData, 3)
+	assert.Equal(t, "foo bar baz", *result.SomeData[0])
+	assert.Nil(t, result.SomeData[2])
+
+	// AfterScan is called once every row is scanned
+	var stats testColumnsStats
+	err = New(WithUnsafe()).ScanColumns(newFakeRows(3), &stats)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Count)
+
+	// test some fail cases
+	rows = newFakeRows(2)
+	var wrong struct {
+		IDs []int `db:"id"`
+	}
+	err = New(WithUnsafe()).ScanColumns(rows, &wrong)
+	require.Error(t, err)
+
+	var scanErr *ScanError
+	require.True(t, errors.As(err, &scanErr))
+	assert.Equal(t, 0, scanErr.Row)
+	assert.Equal(t, "id", scanErr.Column)
+	assert.Equal(t, "IDs", scanErr.Field)
+	assert.Equal(t, reflect.TypeOf(0), scanErr.FieldType)
+}
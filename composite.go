@@ -0,0 +1,141 @@
+package pgxscan
+
+import (
+	"reflect"
+
+	"github.com/jackc/pgtype"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// firstUserOID is the first OID assigned to user-defined objects, such as composite types.
+const firstUserOID = 16384
+
+// compositeTarget returns the scan destination of the field f and the conversion decoding it,
+// if f is a struct, or a pointer to one, which is not a column type, and oid a user-defined type
+// or record. The attributes of the composite value are scanned into the fields of the struct
+// they map to, like columns: "SELECT ROW(a.id, a.name)::author AS author" scans into the id
+// and name fields of the Author struct field tagged "author".
+//
+// Attributes are matched by name when the composite type is registered on the connection,
+// see RegisterTypes, and by position otherwise, as for anonymous records. Pgx only decodes the
+// values of unregistered types as text, which lack the types of their attributes, so they fail.
+// The values of the other user-defined types registered on the connection, such as extension
+// types, are assigned to the struct by their data type, like pgx does.
+func (s *Scanner) compositeTarget(f reflect.Value, oid uint32) (interface{}, func() error, bool) {
+	t := reflectx.Deref(f.Type())
+	if (oid != pgtype.RecordOID && oid < firstUserOID) || isColumnType(t) || isDecoder(reflect.New(t).Interface()) {
+		return nil, nil, false
+	}
+
+	raw := new(rawValue)
+	return raw, func() error {
+		if raw.src == nil {
+			return setNull(f)
+		}
+		v := reflect.New(t)
+		if oid != pgtype.RecordOID && !isComposite(raw.ci, oid) {
+			if err := raw.scan(oid, v.Interface()); err != nil {
+				return err
+			}
+		} else if err := s.decodeComposite(v, oid, raw); err != nil {
+			return err
+		}
+		setValue(f, v.Elem())
+		return nil
+	}, true
+}
+
+// isComposite reports whether oid is a composite type registered on ci, or a type unknown to
+// it, which compositeTarget reports as an unregistered composite type.
+func isComposite(ci *pgtype.ConnInfo, oid uint32) bool {
+	dt, ok := ci.DataTypeForOID(oid)
+	if !ok {
+		return true
+	}
+	_, ok = dt.Value.(*pgtype.CompositeType)
+	return ok
+}
+
+// decodeComposite scans the attributes of the composite value raw, of type oid, into the
+// fields of the struct v points to.
+func (s *Scanner) decodeComposite(v reflect.Value, oid uint32, raw *rawValue) error {
+	var attributes []pgtype.CompositeTypeField
+	if dt, ok := raw.ci.DataTypeForOID(oid); ok {
+		if ct, ok := dt.Value.(*pgtype.CompositeType); ok {
+			attributes = ct.Fields()
+		}
+	}
+
+	fields := s.attributeFields(v.Type().Elem(), attributes)
+	scan := func(i int, attributeOID uint32, src []byte) error {
+		if i >= len(fields) {
+			return errors.Errorf("composite value has more than the %d fields of %s", len(fields), v.Type().Elem())
+		}
+		if fields[i] == nil {
+			if s.unsafe {
+				return nil
+			}
+			return errors.Errorf("missing field for attribute %q in %s", attributes[i].Name, v.Type().Elem())
+		}
+
+		f := reflectx.FieldByIndexes(v, fields[i].Index)
+		target, convert, ok := s.compositeTarget(f, attributeOID)
+		if !ok {
+			target, convert = scanTarget(f, attributeOID)
+		}
+		if err := raw.ci.Scan(attributeOID, raw.format, src, target); err != nil {
+			return errors.Wrapf(err, "failed to scan attribute %q", fields[i].Path)
+		}
+		if convert != nil {
+			return convert()
+		}
+		return nil
+	}
+
+	if raw.format == pgtype.BinaryFormatCode {
+		scanner := pgtype.NewCompositeBinaryScanner(raw.ci, raw.src)
+		for i := 0; scanner.Next(); i++ {
+			if err := scan(i, scanner.OID(), scanner.Bytes()); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+
+	if attributes == nil {
+		return errors.Errorf("composite type oid %d is not registered, see RegisterTypes", oid)
+	}
+	scanner := pgtype.NewCompositeTextScanner(raw.ci, raw.src)
+	for i := 0; scanner.Next(); i++ {
+		if i >= len(attributes) {
+			return errors.Errorf("composite value has more than the %d attributes of its type", len(attributes))
+		}
+		if err := scan(i, attributes[i].OID, scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// attributeFields returns the fields of the struct type t the attributes are scanned into, nil
+// for the attributes without a field. Without attributes, the fields are the ones t maps to
+// columns, in order.
+func (s *Scanner) attributeFields(t reflect.Type, attributes []pgtype.CompositeTypeField) []*reflectx.FieldInfo {
+	tm := s.mapper().TypeMap(t)
+	if attributes == nil {
+		var fields []*reflectx.FieldInfo
+		for _, fi := range tm.Tree.Children {
+			if fi != nil && !fi.Embedded {
+				fields = append(fields, fi)
+			}
+		}
+		return fields
+	}
+
+	fields := make([]*reflectx.FieldInfo, len(attributes))
+	for i, attribute := range attributes {
+		fields[i] = tm.GetByPath(attribute.Name)
+	}
+	return fields
+}
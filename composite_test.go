@@ -0,0 +1,109 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCompositeEntity struct {
+	ID     string      `db:"id"`
+	Author testAuthor  `db:"author"`
+	Editor *testAuthor `db:"editor"`
+}
+
+type testCompositeMood struct {
+	Name string
+}
+
+// testCompositeMoodValue is a data type assigning its values to testCompositeMood structs.
+type testCompositeMoodValue struct {
+	pgtype.GenericText
+}
+
+func (v *testCompositeMoodValue) AssignTo(dst interface{}) error {
+	if mood, ok := dst.(*testCompositeMood); ok {
+		mood.Name = v.String
+		return nil
+	}
+	return v.GenericText.AssignTo(dst)
+}
+
+func TestScanStructsComposite(t *testing.T) {
+	conn := connect(t)
+
+	_, err := conn.Exec(context.Background(), `
+		DROP TYPE IF EXISTS composite_author;
+		CREATE TYPE composite_author AS (name text, id text);
+	`)
+	require.NoError(t, err)
+	err = RegisterTypes(UserType{Name: "composite_author"})(context.Background(), conn)
+	require.NoError(t, err)
+
+	// attributes of registered types are matched by name
+	var result []testCompositeEntity
+	err = Select(context.Background(), conn, &result, `
+		SELECT b.id, ROW(a.name, a.id)::composite_author AS author, ROW(e.name, e.id)::composite_author AS editor
+		FROM (VALUES ('book-1', 'author-1', 'author-2'), ('book-2', 'author-2', NULL)) AS b (id, author_id, editor_id)
+		JOIN (VALUES ('author-1', 'Frank Herbert'), ('author-2', 'Jane Austen')) AS a (id, name) ON a.id = b.author_id
+		LEFT JOIN (VALUES ('author-2', 'Jane Austen')) AS e (id, name) ON e.id = b.editor_id
+		ORDER BY b.id ASC
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []testCompositeEntity{
+		{ID: "book-1", Author: testAuthor{ID: "author-1", Name: "Frank Herbert"}, Editor: &testAuthor{ID: "author-2", Name: "Jane Austen"}},
+		{ID: "book-2", Author: testAuthor{ID: "author-2", Name: "Jane Austen"}},
+	}, result)
+
+	// attributes of records are matched by position
+	var record testCompositeEntity
+	err = Get(context.Background(), conn, &record, `SELECT 'book-3' AS id, ROW('author-3', 'Emma') AS author, NULL::record AS editor`)
+	require.NoError(t, err)
+	assert.Equal(t, testCompositeEntity{ID: "book-3", Author: testAuthor{ID: "author-3", Name: "Emma"}}, record)
+
+	// values of the other registered user-defined types are assigned by their data type
+	_, err = conn.Exec(context.Background(), `
+		DROP TYPE IF EXISTS composite_mood;
+		CREATE TYPE composite_mood AS ENUM ('happy', 'sad');
+	`)
+	require.NoError(t, err)
+	var moodOID uint32
+	err = conn.QueryRow(context.Background(), "SELECT 'composite_mood'::regtype::oid").Scan(&moodOID)
+	require.NoError(t, err)
+	conn.ConnInfo().RegisterDataType(pgtype.DataType{Value: &testCompositeMoodValue{}, Name: "composite_mood", OID: moodOID})
+	var mood struct {
+		Mood    testCompositeMood  `db:"mood"`
+		MoodPtr *testCompositeMood `db:"mood_ptr"`
+	}
+	err = Get(context.Background(), conn, &mood, `SELECT 'happy'::composite_mood AS mood, 'sad'::composite_mood AS mood_ptr`)
+	require.NoError(t, err)
+	assert.Equal(t, "happy", mood.Mood.Name)
+	require.NotNil(t, mood.MoodPtr)
+	assert.Equal(t, "sad", mood.MoodPtr.Name)
+
+	// test some fail cases
+	_, err = conn.Exec(context.Background(), `
+		DROP TYPE IF EXISTS composite_unregistered;
+		CREATE TYPE composite_unregistered AS (id text, name text);
+		DROP TYPE IF EXISTS composite_other;
+		CREATE TYPE composite_other AS (id text, nickname text);
+	`)
+	require.NoError(t, err)
+	err = Get(context.Background(), conn, &record, `SELECT 'book-4' AS id, ROW('author-4', 'Emma')::composite_unregistered AS author, NULL::record AS editor`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not registered, see RegisterTypes")
+
+	err = RegisterTypes(UserType{Name: "composite_other"})(context.Background(), conn)
+	require.NoError(t, err)
+	err = Get(context.Background(), conn, &record, `SELECT 'book-5' AS id, ROW('author-5', 'Emma')::composite_other AS author, NULL::record AS editor`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to scan column "author"`)
+	assert.Contains(t, err.Error(), `of row 0 into field Author of type pgxscan.testAuthor: missing field for attribute "nickname" in pgxscan.testAuthor`)
+
+	err = Get(context.Background(), conn, &record, `SELECT 'book-6' AS id, ROW('author-6', 'Emma', 'extra') AS author, NULL::record AS editor`)
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "author" (oid 2249) of row 0 into field Author of type pgxscan.testAuthor: composite value has more than the 2 fields of pgxscan.testAuthor`, err.Error())
+}
@@ -0,0 +1,190 @@
+package pgxscan
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/jackc/pgtype"
+	"github.com/pkg/errors"
+)
+
+// ConverterFunc converts the value of a column, as decoded by pgx into an interface{}, such as
+// a string for a text column or nil for NULL, into a value of the type it is registered for.
+type ConverterFunc func(src interface{}) (interface{}, error)
+
+// converters holds the ConverterFunc registered for each field type with RegisterConverter.
+var converters sync.Map
+
+// RegisterConverter makes every Scanner scan the columns mapped to fields of type dstType with fn,
+// so that domain types, such as a UserID wrapping a string or an enum, can be scanned without
+// implementing sql.Scanner. fn may return a value of any type convertible to dstType.
+// Converters registered for a Scanner with WithConverter take precedence.
+func RegisterConverter(dstType reflect.Type, fn ConverterFunc) {
+	converters.Store(dstType, fn)
+}
+
+// WithConverter makes the Scanner scan the columns mapped to fields of type dstType with fn,
+// see RegisterConverter.
+func WithConverter(dstType reflect.Type, fn ConverterFunc) Option {
+	return func(s *Scanner) {
+		if s.converters == nil {
+			s.converters = make(map[reflect.Type]ConverterFunc)
+		}
+		s.converters[dstType] = fn
+	}
+}
+
+// converterFunc returns the ConverterFunc registered for fields of type t, if any.
+func (s *Scanner) converterFunc(t reflect.Type) (ConverterFunc, bool) {
+	if fn, ok := s.converters[t]; ok {
+		return fn, true
+	}
+	if fn, ok := converters.Load(t); ok {
+		return fn.(ConverterFunc), true
+	}
+	return nil, false
+}
+
+// convertTarget returns the destination of a column mapped to the field f, and the conversion
+// of its value into f with fn once the row is scanned.
+func convertTarget(f reflect.Value, fn ConverterFunc) (interface{}, func() error) {
+	var src interface{}
+	return &src, func() error {
+		result, err := fn(src)
+		if err != nil {
+			return err
+		}
+
+		v := reflect.ValueOf(result)
+		switch {
+		case !v.IsValid():
+			f.Set(reflect.Zero(f.Type()))
+		case v.Type().AssignableTo(f.Type()):
+			f.Set(v)
+		case v.Type().ConvertibleTo(f.Type()):
+			f.Set(v.Convert(f.Type()))
+		default:
+			return errors.Errorf("converter for %s returned a %T", f.Type(), result)
+		}
+		return nil
+	}
+}
+
+// converter scans a column into a holder type pgx knows how to assign to, and converts
+// the holder into the destination field once the row is scanned.
+type converter struct {
+	holder  reflect.Type
+	convert func(dst, src reflect.Value) error
+}
+
+// converterFor returns the converter for fields of type t, or nil if pgx handles t itself.
+func converterFor(t reflect.Type) *converter {
+	// Slices of named element types, such as []Status or []uuid.UUID: pgtype only assigns
+	// arrays to slices of predeclared element types, and fails on named ones.
+	if t.Kind() == reflect.Slice {
+		if elem := baseType(t.Elem()); elem != nil && elem != t.Elem() {
+			return &converter{holder: reflect.SliceOf(elem), convert: convertSlice}
+		}
+	}
+
+	return nil
+}
+
+// baseType returns the predeclared type t is defined from, such as string for a named
+// string type or [16]byte for a named 16 byte array, or nil if t is not a basic type.
+func baseType(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.Bool:
+		return reflect.TypeOf(false)
+	case reflect.Int:
+		return reflect.TypeOf(int(0))
+	case reflect.Int8:
+		return reflect.TypeOf(int8(0))
+	case reflect.Int16:
+		return reflect.TypeOf(int16(0))
+	case reflect.Int32:
+		return reflect.TypeOf(int32(0))
+	case reflect.Int64:
+		return reflect.TypeOf(int64(0))
+	case reflect.Uint:
+		return reflect.TypeOf(uint(0))
+	case reflect.Uint8:
+		return reflect.TypeOf(uint8(0))
+	case reflect.Uint16:
+		return reflect.TypeOf(uint16(0))
+	case reflect.Uint32:
+		return reflect.TypeOf(uint32(0))
+	case reflect.Uint64:
+		return reflect.TypeOf(uint64(0))
+	case reflect.Float32:
+		return reflect.TypeOf(float32(0))
+	case reflect.Float64:
+		return reflect.TypeOf(float64(0))
+	case reflect.String:
+		return reflect.TypeOf("")
+	case reflect.Array:
+		if elem := baseType(t.Elem()); elem != nil {
+			return reflect.ArrayOf(t.Len(), elem)
+		}
+	}
+	return nil
+}
+
+// convertSlice converts each element of the slice src into the element type of dst.
+// A nil src makes dst nil.
+func convertSlice(dst, src reflect.Value) error {
+	if src.IsNil() {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	elemType := dst.Type().Elem()
+	result := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+	for i := 0; i < src.Len(); i++ {
+		result.Index(i).Set(src.Index(i).Convert(elemType))
+	}
+	dst.Set(result)
+	return nil
+}
+
+// setNull sets the pointer field f to nil for a NULL column, failing for other fields.
+func setNull(f reflect.Value) error {
+	if f.Kind() != reflect.Ptr {
+		return errors.Errorf("cannot scan NULL into %s", f.Type())
+	}
+	f.Set(reflect.Zero(f.Type()))
+	return nil
+}
+
+// setValue sets the field f, of the type of value or a pointer to it, to value.
+func setValue(f, value reflect.Value) {
+	if f.Kind() == reflect.Ptr {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	f.Set(value)
+}
+
+// rawValue is a scan destination keeping the column value as received, to decode it once
+// the row is scanned. The value is only valid until the next row is read.
+type rawValue struct {
+	ci     *pgtype.ConnInfo
+	format int16
+	src    []byte
+}
+
+func (r *rawValue) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	*r = rawValue{ci: ci, format: pgtype.BinaryFormatCode, src: src}
+	return nil
+}
+
+func (r *rawValue) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	*r = rawValue{ci: ci, format: pgtype.TextFormatCode, src: src}
+	return nil
+}
+
+// scan decodes the value, of type oid, into dst the way pgx does.
+func (r *rawValue) scan(oid uint32, dst interface{}) error {
+	return r.ci.Scan(oid, r.format, r.src, dst)
+}
@@ -0,0 +1,182 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testUUIDArrayEntity struct {
+	ID   string      `db:"id"`
+	Refs []uuid.UUID `db:"refs"`
+}
+
+func TestScanStructsUUIDArray(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "uuid_array_test", `
+		id   text PRIMARY KEY,
+		refs uuid[]
+	`)
+
+	ref1, err := uuid.NewV4()
+	require.NoError(t, err)
+	ref2, err := uuid.NewV4()
+	require.NoError(t, err)
+
+	_, err = conn.Exec(
+		context.Background(),
+		"INSERT INTO uuid_array_test (id, refs) VALUES ($1, $2), ($3, '{}'), ($4, NULL)",
+		"uuid-array-1", []string{ref1.String(), ref2.String()},
+		"uuid-array-2",
+		"uuid-array-3",
+	)
+	require.NoError(t, err)
+
+	var result []testUUIDArrayEntity
+	err = Select(context.Background(), conn, &result, "SELECT * FROM uuid_array_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	assert.Equal(t, []uuid.UUID{ref1, ref2}, result[0].Refs)
+	assert.NotNil(t, result[1].Refs)
+	assert.Empty(t, result[1].Refs)
+	assert.Nil(t, result[2].Refs)
+}
+
+type testStatus string
+
+type testStatuses []testStatus
+
+type testArraysEntity struct {
+	ID       string       `db:"id"`
+	Names    []string     `db:"names"`
+	Counts   []int64      `db:"counts"`
+	Refs     [][16]byte   `db:"refs"`
+	Statuses []testStatus `db:"statuses"`
+	History  testStatuses `db:"history"`
+	Levels   []testLevel  `db:"levels"`
+}
+
+func TestScanStructsArrays(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "arrays_test", `
+		id       text PRIMARY KEY,
+		names    text[],
+		counts   int[],
+		refs     uuid[],
+		statuses text[],
+		history  varchar[],
+		levels   bigint[]
+	`)
+
+	ref, err := uuid.NewV4()
+	require.NoError(t, err)
+
+	_, err = conn.Exec(
+		context.Background(),
+		`INSERT INTO arrays_test (id, names, counts, refs, statuses, history, levels) VALUES
+			($1, '{foo,bar}', '{1,2}', $2, '{active}', '{draft,active}', '{1,2}'),
+			($3, '{}', '{}', '{}', '{}', '{}', '{}'),
+			($4, NULL, NULL, NULL, NULL, NULL, NULL)`,
+		"arrays-1", []string{ref.String()},
+		"arrays-2",
+		"arrays-3",
+	)
+	require.NoError(t, err)
+
+	var result []testArraysEntity
+	err = Select(context.Background(), conn, &result, "SELECT * FROM arrays_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	assert.Equal(t, testArraysEntity{
+		ID:       "arrays-1",
+		Names:    []string{"foo", "bar"},
+		Counts:   []int64{1, 2},
+		Refs:     [][16]byte{ref},
+		Statuses: []testStatus{"active"},
+		History:  testStatuses{"draft", "active"},
+		Levels:   []testLevel{1, 2},
+	}, result[0])
+
+	assert.NotNil(t, result[1].Statuses)
+	assert.Empty(t, result[1].Statuses)
+	assert.NotNil(t, result[1].History)
+	assert.Empty(t, result[1].History)
+
+	assert.Equal(t, testArraysEntity{ID: "arrays-3"}, result[2])
+}
+
+type testUserID struct {
+	value string
+}
+
+type testPriority int
+
+type testConvertedEntity struct {
+	ID        testUserID `db:"id"`
+	CreatedAt time.Time  `db:"created_at"`
+	SomeData  string     `db:"some_data"`
+}
+
+func TestScannerConverter(t *testing.T) {
+	RegisterConverter(reflect.TypeOf(testUserID{}), func(src interface{}) (interface{}, error) {
+		id, ok := src.(string)
+		if !ok {
+			return nil, errors.Errorf("unexpected user id %v", src)
+		}
+		return testUserID{value: id}, nil
+	})
+	t.Cleanup(func() { converters.Delete(reflect.TypeOf(testUserID{})) })
+
+	var result []testConvertedEntity
+	err := ScanStructs(newFakeRows(2), &result)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, testUserID{value: "bench-0"}, result[0].ID)
+	assert.Equal(t, testUserID{value: "bench-1"}, result[1].ID)
+
+	// converters of a Scanner take precedence
+	scanner := New(WithConverter(reflect.TypeOf(testUserID{}), func(src interface{}) (interface{}, error) {
+		return testUserID{value: "user-" + src.(string)}, nil
+	}))
+	err = scanner.ScanStructs(newFakeRows(1), &result)
+	require.NoError(t, err)
+	assert.Equal(t, testUserID{value: "user-bench-0"}, result[0].ID)
+
+	// results convertible to the field type are converted
+	type testPriorityEntity struct {
+		ID       string       `db:"id"`
+		Priority testPriority `db:"some_data"`
+	}
+	scanner = New(WithUnsafe(), WithConverter(reflect.TypeOf(testPriority(0)), func(src interface{}) (interface{}, error) {
+		return len(src.(string)), nil
+	}))
+	var priority testPriorityEntity
+	err = scanner.ScanStruct(newFakeRows(1), &priority)
+	require.NoError(t, err)
+	assert.Equal(t, testPriority(11), priority.Priority)
+
+	// test some fail cases
+	scanner = New(WithConverter(reflect.TypeOf(testUserID{}), func(src interface{}) (interface{}, error) {
+		return nil, errors.New("invalid user id")
+	}))
+	err = scanner.ScanStructs(newFakeRows(1), &result)
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "id" (oid 25) of row 0 into field ID of type pgxscan.testUserID: invalid user id`, err.Error())
+
+	scanner = New(WithConverter(reflect.TypeOf(testUserID{}), func(src interface{}) (interface{}, error) {
+		return 42, nil
+	}))
+	err = scanner.ScanStructs(newFakeRows(1), &result)
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "id" (oid 25) of row 0 into field ID of type pgxscan.testUserID: converter for pgxscan.testUserID returned a int`, err.Error())
+}
@@ -0,0 +1,81 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// CopyFromer is implemented by pgx.Conn, pgx.Tx and pgxpool.Pool.
+type CopyFromer interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// CopyFrom bulk inserts the structs of the slice src into table with the copy protocol,
+// see Scanner.CopyFrom. It returns the number of rows copied.
+func CopyFrom(ctx context.Context, conn CopyFromer, table string, src interface{}) (int64, error) {
+	return defaultScanner.CopyFrom(ctx, conn, table, src)
+}
+
+// CopyFrom bulk inserts the structs of the slice src, or the structs its pointers point to,
+// into table, which may be qualified by a schema like "schema.table", with the copy protocol.
+// Columns are the ones InsertArgs returns for the struct type.
+func (s *Scanner) CopyFrom(ctx context.Context, conn CopyFromer, table string, src interface{}) (int64, error) {
+	columns, rowSrc, err := s.CopyFromSource(src)
+	if err != nil {
+		return 0, err
+	}
+	return conn.CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, rowSrc)
+}
+
+// CopyFromSource returns the columns and values of the structs of the slice src, see
+// Scanner.CopyFromSource.
+func CopyFromSource(src interface{}) ([]string, pgx.CopyFromSource, error) {
+	return defaultScanner.CopyFromSource(src)
+}
+
+// CopyFromSource returns the columns of the structs of the slice src and a pgx.CopyFromSource
+// of their values, to copy them with pgx directly.
+func (s *Scanner) CopyFromSource(src interface{}) ([]string, pgx.CopyFromSource, error) {
+	v := reflect.ValueOf(src)
+	if v.Kind() != reflect.Slice {
+		return nil, nil, errors.Errorf("expected a slice of structs, got %T", src)
+	}
+	t := reflectx.Deref(v.Type().Elem())
+	if t.Kind() != reflect.Struct {
+		return nil, nil, errors.Errorf("expected a slice of structs, got %T", src)
+	}
+
+	fields := s.columnFields(t)
+	return columnNames(fields), &structCopySource{rows: v, fields: fields, row: -1}, nil
+}
+
+// structCopySource serves the values of a slice of structs as a pgx.CopyFromSource.
+type structCopySource struct {
+	rows   reflect.Value
+	fields []*reflectx.FieldInfo
+	row    int
+	err    error
+}
+
+func (c *structCopySource) Next() bool {
+	c.row++
+	return c.row < c.rows.Len()
+}
+
+func (c *structCopySource) Values() ([]interface{}, error) {
+	v := reflect.Indirect(c.rows.Index(c.row))
+	if !v.IsValid() {
+		c.err = errors.Errorf("row %d is a nil pointer", c.row)
+		return nil, c.err
+	}
+	return columnValues(v, c.fields), nil
+}
+
+func (c *structCopySource) Err() error {
+	return c.err
+}
@@ -0,0 +1,48 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyFrom(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+	e1.ID += "-copy"
+	e2.ID += "-copy"
+
+	n, err := CopyFrom(context.Background(), conn, "structscan_test", []testEntity{e1, e2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	var result []*testEntity
+	err = Select(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, e1.SomeData, result[0].SomeData)
+	assert.Equal(t, e2.SomeData, result[1].SomeData)
+
+	e3 := &testEntity{ID: e1.ID + "-ptr", CreatedAt: time.Now(), SomeData: "qux"}
+	n, err = CopyFrom(context.Background(), conn, "structscan_test", []*testEntity{e3})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	e4 := &testEntity{ID: e1.ID + "-schema", CreatedAt: time.Now(), SomeData: "quux"}
+	n, err = CopyFrom(context.Background(), conn, "public.structscan_test", []*testEntity{e4})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// test some fail cases
+	_, err = CopyFrom(context.Background(), conn, "structscan_test", e1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a slice of structs")
+
+	_, err = CopyFrom(context.Background(), conn, "structscan_test", []*testEntity{nil})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "row 0 is a nil pointer")
+}
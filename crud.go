@@ -0,0 +1,351 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// Insert inserts the struct src passed by reference into table, see Scanner.Insert.
+func Insert(ctx context.Context, querier Querier, table string, src interface{}, returning ...string) error {
+	return defaultScanner.Insert(ctx, querier, table, src, returning...)
+}
+
+// Insert inserts the struct src passed by reference into table, with the columns and values
+// InsertArgs returns. The returning columns, such as a serial id or columns with defaults,
+// are left for the database to fill in and scanned back into src.
+func (s *Scanner) Insert(ctx context.Context, querier Querier, table string, src interface{}, returning ...string) error {
+	if v := reflect.ValueOf(src); v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.Errorf("expected a non-nil pointer to a struct, got %T", src)
+	}
+
+	query, _, args, err := s.insertQuery(table, src, returning)
+	if err != nil {
+		return err
+	}
+	return s.execReturning(ctx, querier, src, query, args, returning)
+}
+
+// insertQuery returns the INSERT statement of the struct src into table, along with its columns
+// and arguments, leaving out the returning columns.
+func (s *Scanner) insertQuery(table string, src interface{}, returning []string) (string, []string, []interface{}, error) {
+	columns, values, err := s.InsertArgs(src)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var (
+		inserted     []string
+		args         []interface{}
+		placeholders []string
+	)
+	for i, column := range columns {
+		if containsString(returning, column) {
+			continue
+		}
+		inserted = append(inserted, column)
+		args = append(args, values[i])
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+	query := "INSERT INTO " + quoteIdentifier(table)
+	if len(inserted) == 0 {
+		// every column is left for the database to fill in
+		return query + " DEFAULT VALUES", nil, nil, nil
+	}
+	query += " (" + quoteIdentifiers(inserted) + ") VALUES (" + strings.Join(placeholders, ", ") + ")"
+
+	return query, inserted, args, nil
+}
+
+// UpsertOption configures an Upsert.
+type UpsertOption func(*upsertConfig)
+
+type upsertConfig struct {
+	conflict  []string
+	update    []string
+	returning []string
+}
+
+// OnConflict sets the conflict target of an Upsert to columns, instead of the primary key columns.
+func OnConflict(columns ...string) UpsertOption {
+	return func(c *upsertConfig) {
+		c.conflict = columns
+	}
+}
+
+// UpdateColumns restricts the columns an Upsert updates on conflict to columns.
+func UpdateColumns(columns ...string) UpsertOption {
+	return func(c *upsertConfig) {
+		c.update = columns
+	}
+}
+
+// Returning makes an Upsert scan columns back into the struct, see Insert.
+func Returning(columns ...string) UpsertOption {
+	return func(c *upsertConfig) {
+		c.returning = columns
+	}
+}
+
+// Upsert inserts the struct src passed by reference into table, or updates the conflicting row,
+// see Scanner.Upsert.
+func Upsert(ctx context.Context, querier Querier, table string, src interface{}, opts ...UpsertOption) error {
+	return defaultScanner.Upsert(ctx, querier, table, src, opts...)
+}
+
+// Upsert inserts the struct src passed by reference into table like Insert, updating the row
+// conflicting on the primary key columns instead, with INSERT ... ON CONFLICT ... DO UPDATE.
+// All the inserted columns but the conflict target are updated by default.
+//
+// If there is no column to update, conflicting rows are left untouched with DO NOTHING,
+// in which case the returning columns are not scanned and pgx.ErrNoRows is returned.
+//
+// If a field is tagged with the optlock option, as in `db:"version,optlock"`, a conflicting row
+// is only updated if its version is the one of src, and its version is incremented. Otherwise
+// ErrStaleRow is returned. The version of the inserted or updated row is scanned back into src.
+func (s *Scanner) Upsert(ctx context.Context, querier Querier, table string, src interface{}, opts ...UpsertOption) error {
+	v := reflect.ValueOf(src)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.Errorf("expected a non-nil pointer to a struct, got %T", src)
+	}
+
+	var c upsertConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	conflict := c.conflict
+	var version string
+	for _, fi := range s.columnFields(v.Elem().Type()) {
+		if isPrimaryKey(fi) && len(c.conflict) == 0 {
+			conflict = append(conflict, fi.Path)
+		}
+		if isVersion(fi) {
+			version = fi.Path
+		}
+	}
+	if len(conflict) == 0 {
+		return errors.Errorf("no field of %s is tagged with the pk option", v.Elem().Type())
+	}
+
+	// the version is always inserted and returned
+	var returning []string
+	for _, column := range c.returning {
+		if column != version {
+			returning = append(returning, column)
+		}
+	}
+
+	query, inserted, args, err := s.insertQuery(table, src, returning)
+	if err != nil {
+		return err
+	}
+
+	var set []string
+	for _, column := range inserted {
+		if containsString(conflict, column) || column == version || (len(c.update) > 0 && !containsString(c.update, column)) {
+			continue
+		}
+		quoted := pgx.Identifier{column}.Sanitize()
+		set = append(set, quoted+" = EXCLUDED."+quoted)
+	}
+	for _, column := range c.update {
+		if !containsString(inserted, column) {
+			return errors.Errorf("column %q to update is not inserted", column)
+		}
+	}
+
+	query += " ON CONFLICT (" + quoteIdentifiers(conflict) + ")"
+	if version == "" {
+		if len(set) == 0 {
+			query += " DO NOTHING"
+		} else {
+			query += " DO UPDATE SET " + strings.Join(set, ", ")
+		}
+		return s.execReturning(ctx, querier, src, query, args, returning)
+	}
+
+	column := pgx.Identifier{version}.Sanitize()
+	current := quoteIdentifier(table) + "." + column
+	set = append(set, column+" = "+current+" + 1")
+	query += " DO UPDATE SET " + strings.Join(set, ", ") + " WHERE " + current + " = EXCLUDED." + column
+
+	err = s.execReturning(ctx, querier, src, query, args, append(returning, version))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrStaleRow
+	}
+	return err
+}
+
+// Update updates the row of table with the primary key of the struct src, see Scanner.Update.
+func Update(ctx context.Context, querier Querier, table string, src interface{}, columns ...string) error {
+	return defaultScanner.Update(ctx, querier, table, src, columns...)
+}
+
+// Update sets the columns of the row of table with the primary key of the struct src to the values
+// of src, or only the given columns. Primary key columns are the ones of fields tagged with the pk
+// option, as in `db:"id,pk"`. If no row has the primary key pgx.ErrNoRows is returned.
+//
+// If a field is tagged with the optlock option, as in `db:"version,optlock"`, the row is only
+// updated if its version is the one of src, and its version is incremented, along with the one
+// of src if passed by reference. Otherwise ErrStaleRow is returned.
+func (s *Scanner) Update(ctx context.Context, querier Querier, table string, src interface{}, columns ...string) error {
+	v := reflect.Indirect(reflect.ValueOf(src))
+	if v.Kind() != reflect.Struct {
+		return errors.Errorf("expected a struct or a pointer to a struct, got %T", src)
+	}
+
+	var (
+		set, where []string
+		args       []interface{}
+		hasPK      bool
+		version    = -1
+	)
+	fields := s.columnFields(v.Type())
+	names := columnNames(fields)
+	for _, column := range columns {
+		if !containsString(names, column) {
+			return errors.Errorf("missing field for column %q in %s", column, v.Type())
+		}
+	}
+	for i, value := range columnValues(v, fields) {
+		column := pgx.Identifier{names[i]}.Sanitize()
+		if isPrimaryKey(fields[i]) {
+			hasPK = true
+			args = append(args, value)
+			where = append(where, column+" = $"+strconv.Itoa(len(args)))
+		} else if isVersion(fields[i]) {
+			version = i
+			args = append(args, value)
+			where = append(where, column+" = $"+strconv.Itoa(len(args)))
+		} else if len(columns) == 0 || containsString(columns, names[i]) {
+			args = append(args, value)
+			set = append(set, column+" = $"+strconv.Itoa(len(args)))
+		}
+	}
+	if !hasPK {
+		return errors.Errorf("no field of %s is tagged with the pk option", v.Type())
+	}
+	if len(set) == 0 {
+		return errors.New("no column to update")
+	}
+	if version >= 0 {
+		column := pgx.Identifier{names[version]}.Sanitize()
+		set = append(set, column+" = "+column+" + 1")
+	}
+
+	query := "UPDATE " + quoteIdentifier(table) + " SET " + strings.Join(set, ", ") + " WHERE " + strings.Join(where, " AND ")
+	tag, err := s.exec(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		if version >= 0 {
+			return ErrStaleRow
+		}
+		return pgx.ErrNoRows
+	}
+
+	if version >= 0 {
+		if f, ok := fieldByIndexes(v, fields[version].Index); ok && f.CanSet() {
+			incrementVersion(f)
+		}
+	}
+	return nil
+}
+
+// incrementVersion increments the integer version field f.
+func incrementVersion(f reflect.Value) {
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.SetInt(f.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.SetUint(f.Uint() + 1)
+	}
+}
+
+// Delete deletes the row of table with the primary key of the struct src, see Scanner.Delete.
+func Delete(ctx context.Context, querier Querier, table string, src interface{}) (int64, error) {
+	return defaultScanner.Delete(ctx, querier, table, src)
+}
+
+// Delete deletes the row of table with the primary key of the struct src, see Update, and returns
+// the number of rows deleted. If no row has the primary key, because it was deleted already for
+// instance, an error matching both ErrNotFound and pgx.ErrNoRows is returned.
+func (s *Scanner) Delete(ctx context.Context, querier Querier, table string, src interface{}) (int64, error) {
+	v := reflect.Indirect(reflect.ValueOf(src))
+	if v.Kind() != reflect.Struct {
+		return 0, errors.Errorf("expected a struct or a pointer to a struct, got %T", src)
+	}
+
+	var where []string
+	var args []interface{}
+	fields := s.columnFields(v.Type())
+	for i, value := range columnValues(v, fields) {
+		if isPrimaryKey(fields[i]) {
+			args = append(args, value)
+			where = append(where, pgx.Identifier{fields[i].Path}.Sanitize()+" = $"+strconv.Itoa(len(args)))
+		}
+	}
+	if len(where) == 0 {
+		return 0, errors.Errorf("no field of %s is tagged with the pk option", v.Type())
+	}
+
+	query := "DELETE FROM " + quoteIdentifier(table) + " WHERE " + strings.Join(where, " AND ")
+	tag, err := s.exec(ctx, querier, query, args)
+	if err != nil {
+		return 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, &notFoundError{err: pgx.ErrNoRows}
+	}
+	return tag.RowsAffected(), nil
+}
+
+// isPrimaryKey reports whether fi is tagged with the pk option.
+func isPrimaryKey(fi *reflectx.FieldInfo) bool {
+	_, ok := fi.Options["pk"]
+	return ok
+}
+
+// isVersion reports whether fi is tagged with the optlock option.
+func isVersion(fi *reflectx.FieldInfo) bool {
+	_, ok := fi.Options["optlock"]
+	return ok
+}
+
+// execReturning executes query, scanning the returning columns into dest if there are any.
+func (s *Scanner) execReturning(ctx context.Context, querier Querier, dest interface{}, query string, args []interface{}, returning []string) error {
+	if len(returning) == 0 {
+		_, err := s.exec(ctx, querier, query, args)
+		return err
+	}
+	return s.Get(ctx, querier, dest, query+" RETURNING "+quoteIdentifiers(returning), args...)
+}
+
+// quoteIdentifier quotes name, which may be qualified by a schema with a dot.
+func quoteIdentifier(name string) string {
+	return pgx.Identifier(strings.Split(name, ".")).Sanitize()
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = pgx.Identifier{name}.Sanitize()
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,253 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCrudEntity struct {
+	ID        int       `db:"id,pk"`
+	Name      string    `db:"name"`
+	Status    string    `db:"status"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func createCrudTable(t *testing.T, conn *pgx.Conn) {
+	t.Helper()
+
+	createTable(t, conn, "crud_test", `
+		id         serial PRIMARY KEY,
+		name       text        NOT NULL UNIQUE,
+		status     text        NOT NULL DEFAULT 'new',
+		created_at timestamptz NOT NULL DEFAULT now()
+	`)
+}
+
+func TestInsert(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+
+	e1 := &testCrudEntity{Name: "foo", Status: "active"}
+	err := Insert(context.Background(), conn, "crud_test", e1, "id", "created_at")
+	require.NoError(t, err)
+	assert.NotZero(t, e1.ID)
+	assert.False(t, e1.CreatedAt.IsZero())
+
+	e2 := &testCrudEntity{Name: "bar"}
+	err = Insert(context.Background(), conn, "public.crud_test", e2, "id", "status", "created_at")
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID+1, e2.ID)
+	assert.Equal(t, "new", e2.Status)
+
+	result, err := GetAs[testCrudEntity](context.Background(), conn, "SELECT * FROM crud_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", result.Name)
+	assert.Equal(t, "active", result.Status)
+
+	e3 := &testCrudEntity{ID: 100, Name: "baz", Status: "active", CreatedAt: time.Now()}
+	err = Insert(context.Background(), conn, "crud_test", e3)
+	require.NoError(t, err)
+	exists, err := Exists(context.Background(), conn, "SELECT 1 FROM crud_test WHERE id = 100")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// test some fail cases
+	err = Insert(context.Background(), conn, "crud_test", testCrudEntity{Name: "qux"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a non-nil pointer to a struct")
+
+	err = Insert(context.Background(), conn, "crud_test", &testCrudEntity{Name: "foo"}, "id", "created_at")
+	require.Error(t, err)
+
+	err = Insert(context.Background(), conn, "crud_test", &testCrudEntity{Name: "qux"}, "id", "foo")
+	require.Error(t, err)
+}
+
+func TestInsertQuery(t *testing.T) {
+	query, columns, args, err := New().insertQuery("public.crud_test", &testCrudEntity{Name: "foo"}, []string{"id", "created_at"})
+	require.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "public"."crud_test" ("name", "status") VALUES ($1, $2)`, query)
+	assert.Equal(t, []string{"name", "status"}, columns)
+	assert.Equal(t, []interface{}{"foo", ""}, args)
+
+	// every column returned
+	query, columns, args, err = New().insertQuery("crud_test", &testCrudEntity{}, []string{"id", "name", "status", "created_at"})
+	require.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "crud_test" DEFAULT VALUES`, query)
+	assert.Empty(t, columns)
+	assert.Empty(t, args)
+}
+
+func TestUpdate(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+
+	e1 := &testCrudEntity{Name: "foo", Status: "active"}
+	require.NoError(t, Insert(context.Background(), conn, "crud_test", e1, "id", "created_at"))
+
+	e1.Name = "bar"
+	e1.Status = "inactive"
+	err := Update(context.Background(), conn, "crud_test", e1, "status")
+	require.NoError(t, err)
+
+	result, err := GetAs[testCrudEntity](context.Background(), conn, "SELECT * FROM crud_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", result.Name)
+	assert.Equal(t, "inactive", result.Status)
+
+	result.Name = "baz"
+	err = Update(context.Background(), conn, "crud_test", result)
+	require.NoError(t, err)
+
+	result, err = GetAs[testCrudEntity](context.Background(), conn, "SELECT * FROM crud_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "baz", result.Name)
+	assert.Equal(t, "inactive", result.Status)
+
+	// test some fail cases
+	err = Update(context.Background(), conn, "crud_test", &testCrudEntity{ID: e1.ID + 1, Name: "qux"})
+	assert.Equal(t, pgx.ErrNoRows, err)
+
+	err = Update(context.Background(), conn, "crud_test", e1, "foo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing field for column "foo"`)
+
+	err = Update(context.Background(), conn, "crud_test", e1, "id")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no column to update")
+
+	err = Update(context.Background(), conn, "structscan_test", &testEntity{ID: "foo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no field of pgxscan.testEntity is tagged with the pk option")
+}
+
+func TestUpsert(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+
+	e1 := &testCrudEntity{Name: "foo", Status: "active"}
+	err := Upsert(context.Background(), conn, "crud_test", e1, OnConflict("name"), Returning("id", "created_at"))
+	require.NoError(t, err)
+	assert.NotZero(t, e1.ID)
+
+	e2 := &testCrudEntity{Name: "foo", Status: "inactive"}
+	err = Upsert(context.Background(), conn, "crud_test", e2, OnConflict("name"), Returning("id", "created_at"))
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, e2.ID)
+	assert.Equal(t, e1.CreatedAt.Unix(), e2.CreatedAt.Unix())
+
+	result, err := GetAs[testCrudEntity](context.Background(), conn, "SELECT * FROM crud_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "inactive", result.Status)
+
+	// the primary key is the default conflict target
+	result.Name = "bar"
+	result.Status = "active"
+	err = Upsert(context.Background(), conn, "crud_test", &result, UpdateColumns("status"))
+	require.NoError(t, err)
+
+	result, err = GetAs[testCrudEntity](context.Background(), conn, "SELECT * FROM crud_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", result.Name)
+	assert.Equal(t, "active", result.Status)
+
+	// test some fail cases
+	err = Upsert(context.Background(), conn, "crud_test", &testCrudEntity{Name: "foo"}, OnConflict("name"), UpdateColumns("name"), Returning("id"))
+	assert.Equal(t, pgx.ErrNoRows, err)
+
+	err = Upsert(context.Background(), conn, "crud_test", e1, UpdateColumns("foo"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `column "foo" to update is not inserted`)
+
+	err = Upsert(context.Background(), conn, "structscan_test", &testEntity{ID: "foo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no field of pgxscan.testEntity is tagged with the pk option")
+
+	err = Upsert(context.Background(), conn, "crud_test", testCrudEntity{Name: "foo"})
+	require.Error(t, err)
+}
+
+func TestDelete(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+
+	e1 := &testCrudEntity{Name: "foo", Status: "active"}
+	require.NoError(t, Insert(context.Background(), conn, "crud_test", e1, "id", "created_at"))
+
+	n, err := Delete(context.Background(), conn, "crud_test", e1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	exists, err := Exists(context.Background(), conn, "SELECT 1 FROM crud_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// test some fail cases
+	n, err = Delete(context.Background(), conn, "crud_test", e1)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+	assert.Zero(t, n)
+
+	_, err = Delete(context.Background(), conn, "structscan_test", testEntity{ID: "foo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no field of pgxscan.testEntity is tagged with the pk option")
+}
+
+type testVersionedEntity struct {
+	ID      int    `db:"id,pk"`
+	Name    string `db:"name"`
+	Version int    `db:"version,optlock"`
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	conn := connect(t)
+	createTable(t, conn, "optlock_test", `
+		id      int  PRIMARY KEY,
+		name    text NOT NULL,
+		version int  NOT NULL
+	`)
+
+	e1 := &testVersionedEntity{ID: 1, Name: "foo", Version: 1}
+	err := Upsert(context.Background(), conn, "optlock_test", e1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, e1.Version)
+
+	stale := *e1
+
+	e1.Name = "bar"
+	err = Update(context.Background(), conn, "optlock_test", e1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, e1.Version)
+
+	e1.Name = "baz"
+	err = Upsert(context.Background(), conn, "optlock_test", e1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, e1.Version)
+
+	result, err := GetAs[testVersionedEntity](context.Background(), conn, "SELECT * FROM optlock_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *e1, result)
+
+	// test some fail cases
+	stale.Name = "qux"
+	err = Update(context.Background(), conn, "optlock_test", &stale)
+	assert.Equal(t, ErrStaleRow, err)
+	assert.Equal(t, 1, stale.Version)
+
+	err = Upsert(context.Background(), conn, "optlock_test", &stale)
+	assert.Equal(t, ErrStaleRow, err)
+
+	err = Update(context.Background(), conn, "optlock_test", &testVersionedEntity{ID: 2, Name: "foo"})
+	assert.Equal(t, ErrStaleRow, err)
+
+	result, err = GetAs[testVersionedEntity](context.Background(), conn, "SELECT * FROM optlock_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "baz", result.Name)
+}
@@ -0,0 +1,145 @@
+package pgxscan
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// CSVOption configures WriteCSV.
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	delimiter  rune
+	null       string
+	timeFormat string
+}
+
+// CSVDelimiter separates the fields with delimiter instead of a comma.
+func CSVDelimiter(delimiter rune) CSVOption {
+	return func(c *csvConfig) {
+		c.delimiter = delimiter
+	}
+}
+
+// CSVNull writes NULL as null instead of an empty field.
+func CSVNull(null string) CSVOption {
+	return func(c *csvConfig) {
+		c.null = null
+	}
+}
+
+// CSVTimeFormat formats the date, timestamp and timestamptz values in UTC with the time layout
+// instead of their text representation in Postgres.
+func CSVTimeFormat(layout string) CSVOption {
+	return func(c *csvConfig) {
+		c.timeFormat = layout
+	}
+}
+
+// WriteCSV writes the rows of r to w as CSV, after a header of the column names. Values are
+// written in their text representation in Postgres, such as t and f for booleans, as psql shows
+// them. Function call closes rows, so caller may skip it.
+func WriteCSV(r pgx.Rows, w io.Writer, opts ...CSVOption) error {
+	defer r.Close()
+
+	c := csvConfig{delimiter: ','}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = c.delimiter
+
+	fields := r.FieldDescriptions()
+	record := make([]string, len(fields))
+	for i, fieldDescription := range fields {
+		record[i] = string(fieldDescription.Name)
+	}
+	if err := cw.Write(record); err != nil {
+		return errors.Wrap(err, "failed to write the header")
+	}
+
+	raws := make([]interface{}, len(fields))
+	for i := range raws {
+		raws[i] = new(rawValue)
+	}
+	for r.Next() {
+		if err := r.Scan(raws...); err != nil {
+			return err
+		}
+		for i, raw := range raws {
+			text, null, err := textValue(raw.(*rawValue), fields[i].DataTypeOID, c.timeFormat)
+			if err != nil {
+				return errors.Wrapf(err, "failed to format column %q", fields[i].Name)
+			}
+			if null {
+				text = c.null
+			}
+			record[i] = text
+		}
+		if err := cw.Write(record); err != nil {
+			return errors.Wrap(err, "failed to write a row")
+		}
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// textValue returns the text representation of raw, a value of type oid, and whether it is NULL.
+// Dates and timestamps are formatted with timeFormat, if not empty.
+func textValue(raw *rawValue, oid uint32, timeFormat string) (string, bool, error) {
+	if raw.src == nil {
+		return "", true, nil
+	}
+
+	dt, ok := raw.ci.DataTypeForOID(oid)
+	if !ok {
+		if raw.format == pgtype.TextFormatCode {
+			return string(raw.src), false, nil
+		}
+		return fmt.Sprintf(`\x%x`, raw.src), false, nil
+	}
+	if raw.format == pgtype.TextFormatCode && timeFormat == "" {
+		return string(raw.src), false, nil
+	}
+
+	value := pgtype.NewValue(dt.Value)
+	if err := raw.scan(oid, value); err != nil {
+		return "", false, err
+	}
+
+	if timeFormat != "" {
+		switch value.(type) {
+		case *pgtype.Date, *pgtype.Timestamp, *pgtype.Timestamptz:
+			var t time.Time
+			if err := value.AssignTo(&t); err == nil {
+				return t.UTC().Format(timeFormat), false, nil
+			}
+			// infinite values keep their text representation
+		}
+	}
+
+	if n, ok := value.(*pgtype.Numeric); ok {
+		return numericText(n), false, nil
+	}
+
+	encoder, ok := value.(pgtype.TextEncoder)
+	if !ok {
+		return "", false, errors.Errorf("%T has no text representation", value)
+	}
+	text, err := encoder.EncodeText(raw.ci, nil)
+	if err != nil {
+		return "", false, err
+	}
+	return string(text), false, nil
+}
@@ -0,0 +1,76 @@
+package pgxscan
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	conn := connect(t)
+
+	query := `
+		SELECT * FROM (VALUES
+			(1, 'foo, bar', true, '2020-01-02 03:04:05+00'::timestamptz, '{1,2}'::int[]),
+			(2, NULL, false, NULL, NULL)
+		) AS t (id, name, active, created_at, tags)
+	`
+
+	rows, err := conn.Query(context.Background(), query)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = WriteCSV(rows, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name,active,created_at,tags\n"+
+		"1,\"foo, bar\",t,2020-01-02 03:04:05Z,\"{1,2}\"\n"+
+		"2,,f,,\n", buf.String())
+
+	rows, err = conn.Query(context.Background(), query)
+	require.NoError(t, err)
+
+	buf.Reset()
+	err = WriteCSV(rows, &buf, CSVDelimiter(';'), CSVNull("NULL"), CSVTimeFormat(time.RFC3339))
+	require.NoError(t, err)
+	assert.Equal(t, "id;name;active;created_at;tags\n"+
+		"1;foo, bar;t;2020-01-02T03:04:05Z;{1,2}\n"+
+		"2;NULL;f;NULL;NULL\n", buf.String())
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), "SELECT 1 AS id")
+	require.NoError(t, err)
+	err = WriteCSV(rows, &buf, CSVDelimiter('"'))
+	require.Error(t, err)
+}
+
+func TestWriteCSVFakeRows(t *testing.T) {
+	rows := newFakeRows(2)
+	rows.rows[1][2] = nil
+
+	var buf bytes.Buffer
+	err := WriteCSV(rows, &buf, CSVTimeFormat("2006-01-02"))
+	require.NoError(t, err)
+	assert.Equal(t, "id,created_at,some_data\n"+
+		"bench-0,2020-01-01,foo bar baz\n"+
+		"bench-1,2020-01-01,\n", buf.String())
+}
+
+func TestWriteCSVNumeric(t *testing.T) {
+	rows := newFakeRows(0)
+	rows.fields = []pgproto3.FieldDescription{
+		{Name: []byte("amount"), DataTypeOID: pgtype.NumericOID},
+	}
+	rows.rows = [][][]byte{{[]byte("12.30")}, {[]byte("1200")}, {[]byte("NaN")}, {nil}}
+
+	// numerics keep their scale, instead of the exponent pgtype encodes them with
+	var buf bytes.Buffer
+	err := WriteCSV(rows, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "amount\n12.30\n1200\nNaN\n\n", buf.String())
+}
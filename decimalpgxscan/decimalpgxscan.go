@@ -0,0 +1,25 @@
+// Package decimalpgxscan decodes numeric columns into shopspring/decimal values, so that
+// decimal.Decimal fields are scanned exactly without a roundtrip through strings.
+package decimalpgxscan
+
+import (
+	"context"
+
+	"github.com/jackc/pgtype"
+	numeric "github.com/jackc/pgtype/ext/shopspring-numeric"
+	pgx "github.com/jackc/pgx/v4"
+)
+
+// Register registers the numeric type on the connection as decoded by shopspring/decimal, so
+// numeric columns scan into decimal.Decimal and *decimal.Decimal fields directly. NULL sets
+// *decimal.Decimal fields to nil.
+//
+// The signature matches pgxpool.Config.AfterConnect, so it can be used to set up each pool connection.
+func Register(ctx context.Context, conn *pgx.Conn) error {
+	conn.ConnInfo().RegisterDataType(pgtype.DataType{
+		Value: &numeric.Numeric{},
+		Name:  "numeric",
+		OID:   pgtype.NumericOID,
+	})
+	return nil
+}
@@ -0,0 +1,78 @@
+package decimalpgxscan
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+type testEntity struct {
+	ID       string           `db:"id"`
+	Amount   decimal.Decimal  `db:"amount"`
+	Discount *decimal.Decimal `db:"discount"`
+}
+
+func TestRegister(t *testing.T) {
+	conn := connect(t)
+
+	err := Register(context.Background(), conn)
+	require.NoError(t, err)
+
+	var results []testEntity
+	err = pgxscan.Select(context.Background(), conn, &results, "SELECT * FROM decimalpgxscan_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "12345678901234567890.123456789", results[0].Amount.String())
+	require.NotNil(t, results[0].Discount)
+	assert.Equal(t, "0.1", results[0].Discount.String())
+
+	assert.Equal(t, "-0.01", results[1].Amount.String())
+	assert.Nil(t, results[1].Discount)
+
+	// test some fail cases
+	var result testEntity
+	err = pgxscan.Get(context.Background(), conn, &result, "SELECT 'a' AS id, NULL::numeric AS amount, NULL::numeric AS discount")
+	require.Error(t, err)
+}
+
+func connect(t *testing.T) *pgx.Conn {
+	t.Helper()
+
+	connString := os.Getenv("TEST_POSTGRES_URI")
+	require.NotEmpty(t, connString)
+
+	conn, err := pgx.Connect(context.Background(), connString)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := conn.Close(context.Background())
+		assert.NoError(t, err)
+	})
+
+	_, err = conn.Exec(context.Background(), `DROP TABLE IF EXISTS decimalpgxscan_test`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `
+		CREATE TABLE decimalpgxscan_test (
+			id       text PRIMARY KEY,
+			amount   numeric not null,
+			discount numeric
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `
+		INSERT INTO decimalpgxscan_test (id, amount, discount)
+		VALUES ('a', 12345678901234567890.123456789, 0.1), ('b', -0.01, NULL)
+	`)
+	require.NoError(t, err)
+
+	return conn
+}
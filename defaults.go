@@ -0,0 +1,137 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// dbDefaults holds the constant column defaults of a table, as Postgres literals.
+type dbDefaults struct {
+	literals map[string]string
+}
+
+// WithDBDefaults applies the column defaults of table to the fields scanned from NULL
+// columns of the same name, so Go values match what the database would have stored.
+//
+// The defaults are read once from information_schema.columns when the option is applied,
+// and only constant defaults are used: expressions such as now() or nextval() are ignored.
+// table may be qualified with a schema, it defaults to the current one otherwise.
+// If reading the defaults fails, every scan of the Scanner returns the error.
+func WithDBDefaults(querier Querier, table string) Option {
+	return func(s *Scanner) {
+		defaults, err := loadDBDefaults(context.Background(), querier, table)
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.defaults = defaults
+	}
+}
+
+func loadDBDefaults(ctx context.Context, querier Querier, table string) (*dbDefaults, error) {
+	var schema *string
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		s := table[:i]
+		schema, table = &s, table[i+1:]
+	}
+
+	rows, err := querier.Query(ctx, `
+		SELECT column_name::text, column_default::text
+		FROM information_schema.columns
+		WHERE table_schema = COALESCE($1::text, current_schema()::text)
+			AND table_name = $2::text
+			AND column_default IS NOT NULL
+	`, schema, table)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the column defaults of %q", table)
+	}
+	defer rows.Close()
+
+	defaults := &dbDefaults{literals: make(map[string]string)}
+	for rows.Next() {
+		var column, expr string
+		if err := rows.Scan(&column, &expr); err != nil {
+			return nil, errors.Wrapf(err, "failed to read the column defaults of %q", table)
+		}
+		if literal, ok := parseDefaultLiteral(expr); ok {
+			defaults.literals[column] = literal
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read the column defaults of %q", table)
+	}
+
+	return defaults, nil
+}
+
+var (
+	castLiteralRegexp    = regexp.MustCompile(`^'((?:[^']|'')*)'::[\w\s\[\]".]+$`)
+	numericLiteralRegexp = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+)
+
+// parseDefaultLiteral returns the text representation of the column default expr,
+// as reported by information_schema, if it is a constant.
+func parseDefaultLiteral(expr string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+
+	if m := castLiteralRegexp.FindStringSubmatch(expr); m != nil {
+		return strings.ReplaceAll(m[1], "''", "'"), true
+	}
+	if numericLiteralRegexp.MatchString(expr) || expr == "true" || expr == "false" {
+		return expr, true
+	}
+	return "", false
+}
+
+// setDefaults scans the columns which have a default through a rawValue, so that NULL
+// values assign the default to their field while other values are decoded as usual.
+func (d *dbDefaults) setDefaults(r pgx.Rows, v reflect.Value, columns []string, fields [][]int, values []interface{}, conversions []func() error) {
+	fieldDescriptions := r.FieldDescriptions()
+	for i, column := range columns {
+		literal, ok := d.literals[column]
+		if !ok || len(fields[i]) == 0 {
+			continue
+		}
+
+		f := reflectx.FieldByIndexesReadOnly(v, fields[i])
+		oid := fieldDescriptions[i].DataTypeOID
+		target, convert := values[i], conversions[i]
+		raw := new(rawValue)
+
+		values[i] = raw
+		conversions[i] = func() error {
+			if raw.src == nil {
+				return assignDefault(raw.ci, f, oid, literal)
+			}
+			if err := raw.scan(oid, target); err != nil {
+				return err
+			}
+			if convert != nil {
+				return convert()
+			}
+			return nil
+		}
+	}
+}
+
+// assignDefault decodes literal as a value of the type oid and assigns it to the field f.
+func assignDefault(ci *pgtype.ConnInfo, f reflect.Value, oid uint32, literal string) error {
+	target, convert := scanTarget(f, oid)
+	if err := ci.Scan(oid, pgtype.TextFormatCode, []byte(literal), target); err != nil {
+		return errors.Wrapf(err, "failed to assign default %q", literal)
+	}
+	if convert != nil {
+		return convert()
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDefaultsEntity struct {
+	ID        string     `db:"id"`
+	Status    string     `db:"status"`
+	Priority  int        `db:"priority"`
+	Tags      []string   `db:"tags"`
+	Note      *string    `db:"note"`
+	CreatedAt *time.Time `db:"created_at"`
+}
+
+func TestScannerDBDefaults(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "defaults_test", `
+		id         text PRIMARY KEY,
+		status     text        DEFAULT 'pending',
+		priority   int         DEFAULT 5,
+		tags       text[]      DEFAULT '{}',
+		note       text        DEFAULT 'it''s new',
+		created_at timestamptz DEFAULT now()
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO defaults_test (id, status, priority, tags, note, created_at) VALUES
+			('defaults-1', NULL, NULL, NULL, NULL, NULL),
+			('defaults-2', 'done', 1, '{foo}', 'old', now())
+	`)
+	require.NoError(t, err)
+
+	scanner := New(WithDBDefaults(conn, "defaults_test"))
+
+	var result []*testDefaultsEntity
+	err = scanner.Select(context.Background(), conn, &result, "SELECT * FROM defaults_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, "pending", result[0].Status)
+	assert.Equal(t, 5, result[0].Priority)
+	assert.NotNil(t, result[0].Tags)
+	assert.Empty(t, result[0].Tags)
+	require.NotNil(t, result[0].Note)
+	assert.Equal(t, "it's new", *result[0].Note)
+	// now() is not a constant, so the column stays NULL
+	assert.Nil(t, result[0].CreatedAt)
+
+	assert.Equal(t, "done", result[1].Status)
+	assert.Equal(t, 1, result[1].Priority)
+	assert.Equal(t, []string{"foo"}, result[1].Tags)
+	require.NotNil(t, result[1].Note)
+	assert.Equal(t, "old", *result[1].Note)
+	assert.NotNil(t, result[1].CreatedAt)
+
+	// test some fail cases
+	var resultFail []*testDefaultsEntity
+	err = Select(context.Background(), conn, &resultFail, "SELECT * FROM defaults_test ORDER BY id ASC")
+	require.Error(t, err)
+
+	closedConn, err := pgx.Connect(context.Background(), conn.Config().ConnString())
+	require.NoError(t, err)
+	require.NoError(t, closedConn.Close(context.Background()))
+
+	scanner = New(WithDBDefaults(closedConn, "defaults_test"))
+	err = scanner.Select(context.Background(), conn, &resultFail, "SELECT * FROM defaults_test ORDER BY id ASC")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to read the column defaults of "defaults_test"`)
+}
+
+func TestParseDefaultLiteral(t *testing.T) {
+	for expr, expected := range map[string]string{
+		"'pending'::text":                  "pending",
+		"'it''s'::character varying":       "it's",
+		"'{}'::text[]":                     "{}",
+		"'2020-01-02'::date":               "2020-01-02",
+		"5":                                "5",
+		"(-1)":                             "-1",
+		"1.5":                              "1.5",
+		"true":                             "true",
+		`'{"a": 1}'::jsonb`:                `{"a": 1}`,
+		"'x'::\"MyType\"":                  "x",
+		"'2020-01-02 03:04:05'::timestamp": "2020-01-02 03:04:05",
+	} {
+		literal, ok := parseDefaultLiteral(expr)
+		assert.True(t, ok, expr)
+		assert.Equal(t, expected, literal, expr)
+	}
+
+	for _, expr := range []string{
+		"now()",
+		"nextval('defaults_test_id_seq'::regclass)",
+		"CURRENT_TIMESTAMP",
+		"NULL::text",
+		"gen_random_uuid()",
+	} {
+		_, ok := parseDefaultLiteral(expr)
+		assert.False(t, ok, expr)
+	}
+}
@@ -0,0 +1,83 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// Diff returns the SET clause assigning the columns changed from original to modified, see Scanner.Diff.
+func Diff(original, modified interface{}) (setClause string, args []interface{}, err error) {
+	return defaultScanner.Diff(original, modified)
+}
+
+// Diff compares the structs original and modified, of the same type, and returns the assignments
+// of the columns whose values differ, such as `"name" = $1, "status" = $2`, along with the values
+// of modified for the placeholders, numbered from 1. setClause is empty if nothing changed.
+// Columns are the ones InsertArgs returns.
+func (s *Scanner) Diff(original, modified interface{}) (setClause string, args []interface{}, err error) {
+	columns, args, err := s.changedColumns(original, modified)
+	if err != nil {
+		return "", nil, err
+	}
+
+	set := make([]string, len(columns))
+	for i, column := range columns {
+		set[i] = pgx.Identifier{column}.Sanitize() + " = $" + strconv.Itoa(i+1)
+	}
+	return strings.Join(set, ", "), args, nil
+}
+
+// UpdateChanged updates the columns changed from original to modified, see Scanner.UpdateChanged.
+func UpdateChanged(ctx context.Context, querier Querier, table string, original, modified interface{}) error {
+	return defaultScanner.UpdateChanged(ctx, querier, table, original, modified)
+}
+
+// UpdateChanged updates the row of table with the primary key of modified like Update, setting only
+// the columns whose values differ from original, so read-modify-write flows don't rewrite every column.
+// Nothing is executed if no column changed. The primary key can't be changed, the row being found by it.
+func (s *Scanner) UpdateChanged(ctx context.Context, querier Querier, table string, original, modified interface{}) error {
+	columns, _, err := s.changedColumns(original, modified)
+	if err != nil {
+		return err
+	}
+	for _, fi := range s.columnFields(reflect.Indirect(reflect.ValueOf(modified)).Type()) {
+		if isPrimaryKey(fi) && containsString(columns, fi.Path) {
+			return errors.Errorf("primary key column %q changed", fi.Path)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	return s.Update(ctx, querier, table, modified, columns...)
+}
+
+// changedColumns returns the columns whose values differ between the structs original and modified,
+// and their values in modified.
+func (s *Scanner) changedColumns(original, modified interface{}) ([]string, []interface{}, error) {
+	originalVal := reflect.Indirect(reflect.ValueOf(original))
+	modifiedVal := reflect.Indirect(reflect.ValueOf(modified))
+	if originalVal.Kind() != reflect.Struct || originalVal.Type() != modifiedVal.Type() {
+		return nil, nil, errors.Errorf("expected structs of the same type, got %T and %T", original, modified)
+	}
+
+	fields := s.columnFields(originalVal.Type())
+	originalValues := columnValues(originalVal, fields)
+	modifiedValues := columnValues(modifiedVal, fields)
+
+	var (
+		columns []string
+		values  []interface{}
+	)
+	for i, fi := range fields {
+		if !reflect.DeepEqual(originalValues[i], modifiedValues[i]) {
+			columns = append(columns, fi.Path)
+			values = append(values, modifiedValues[i])
+		}
+	}
+	return columns, values, nil
+}
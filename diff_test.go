@@ -0,0 +1,64 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	original := testCrudEntity{ID: 1, Name: "foo", Status: "active"}
+
+	modified := original
+	modified.Name = "bar"
+	modified.Status = "inactive"
+	setClause, args, err := Diff(&original, &modified)
+	require.NoError(t, err)
+	assert.Equal(t, `"name" = $1, "status" = $2`, setClause)
+	assert.Equal(t, []interface{}{"bar", "inactive"}, args)
+
+	setClause, args, err = Diff(original, original)
+	require.NoError(t, err)
+	assert.Empty(t, setClause)
+	assert.Empty(t, args)
+
+	// test some fail cases
+	_, _, err = Diff(&original, &testEntity{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected structs of the same type")
+}
+
+func TestUpdateChanged(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+
+	original := &testCrudEntity{Name: "foo", Status: "active"}
+	require.NoError(t, Insert(context.Background(), conn, "crud_test", original, "id", "created_at"))
+
+	// a concurrent change of another column is kept
+	_, err := conn.Exec(context.Background(), "UPDATE crud_test SET name = 'bar' WHERE id = $1", original.ID)
+	require.NoError(t, err)
+
+	modified := *original
+	modified.Status = "inactive"
+	err = UpdateChanged(context.Background(), conn, "crud_test", original, &modified)
+	require.NoError(t, err)
+
+	result, err := GetAs[testCrudEntity](context.Background(), conn, "SELECT * FROM crud_test WHERE id = $1", original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", result.Name)
+	assert.Equal(t, "inactive", result.Status)
+
+	err = UpdateChanged(context.Background(), conn, "crud_test", &modified, &modified)
+	require.NoError(t, err)
+
+	// test some fail cases
+	moved := modified
+	moved.ID++
+	moved.Name = "baz"
+	err = UpdateChanged(context.Background(), conn, "crud_test", &modified, &moved)
+	require.Error(t, err)
+	assert.Equal(t, `primary key column "id" changed`, err.Error())
+}
@@ -0,0 +1,81 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// SelectEach scans the rows of the query result one by one into the same T and calls fn
+// after each of them, see ScanEach.
+func SelectEach[T any](ctx context.Context, querier Querier, fn func(dest *T) error, query string, args ...interface{}) error {
+	rows, err := defaultScanner.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+
+	dest := new(T)
+	return defaultScanner.scanEach(ctx, rows, dest, func() error {
+		return fn(dest)
+	})
+}
+
+// ScanEach scans each row of r into the struct dest passed by reference and calls fn
+// after each of them, so large results can be processed without holding them in memory.
+func ScanEach(r pgx.Rows, dest interface{}, fn func() error) error {
+	return defaultScanner.ScanEach(r, dest, fn)
+}
+
+// ScanEach works like the package-level ScanEach, using the Scanner options.
+//
+// dest is zeroed before each row and is only valid until fn returns: copy it, not a pointer to
+// it, to keep a row. The first error returned by fn stops the scan and is returned as is.
+func (s *Scanner) ScanEach(r pgx.Rows, dest interface{}, fn func() error) error {
+	return s.scanEach(context.Background(), r, dest, fn)
+}
+
+// scanEach works like ScanEach, passing ctx to the AfterScan method of dest, if any.
+func (s *Scanner) scanEach(ctx context.Context, r pgx.Rows, dest interface{}, fn func() error) error {
+	defer r.Close()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("expected a non-nil pointer to a struct, got %T", dest)
+	}
+	zero := reflect.Zero(v.Elem().Type())
+
+	var (
+		columns []string
+		oids    []uint32
+		fields  [][]int
+		decoder *rowDecoder
+		err     error
+	)
+	for row := 0; r.Next(); row++ {
+		// zeroed before BeforeScan is called on the first row, to keep what it sets
+		v.Elem().Set(zero)
+		if columns == nil {
+			columns, fields, err = s.rowMetadata(r, v)
+			if err != nil {
+				return err
+			}
+			oids = columnOIDs(r)
+			decoder = newRowDecoder(oids)
+		}
+
+		if err := s.scanRow(ctx, r, row, v, columns, oids, fields, decoder); err != nil {
+			return err
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	return r.Err()
+}
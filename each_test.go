@@ -0,0 +1,63 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectEach(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	var (
+		ids   []string
+		dests []*testEntity
+	)
+	err := SelectEach(context.Background(), conn, func(dest *testEntity) error {
+		ids = append(ids, dest.ID)
+		dests = append(dests, dest)
+		return nil
+	}, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{e1.ID, e2.ID}, ids)
+	// the destination is reused for every row
+	assert.Same(t, dests[0], dests[1])
+
+	// test some fail cases
+	errStop := errors.New("stop")
+	calls := 0
+	err = SelectEach(context.Background(), conn, func(dest *testEntity) error {
+		calls++
+		return errStop
+	}, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, 1, calls)
+
+	err = SelectEach(context.Background(), conn, func(dest *testMissingField) error {
+		return nil
+	}, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.Error(t, err)
+}
+
+func TestScanEach(t *testing.T) {
+	var (
+		result testEntity
+		ids    []string
+	)
+	err := ScanEach(newFakeRows(3), &result, func() error {
+		ids = append(ids, result.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bench-0", "bench-1", "bench-2"}, ids)
+
+	// test some fail cases
+	err = ScanEach(newFakeRows(3), result, func() error { return nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a non-nil pointer to a struct")
+}
@@ -0,0 +1,165 @@
+package pgxscan
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is matched, along with pgx.ErrNoRows, by the errors of the functions returning
+// a single row when the query returned no rows.
+var ErrNotFound = errors.New("not found")
+
+// ErrTooManyRows is returned by the functions expecting a single row when the query returned more,
+// and by the ones scanning all the rows when there are more than allowed by WithMaxRows.
+var ErrTooManyRows = errors.New("too many rows")
+
+// ErrStaleRow is returned by the functions updating a row with a field tagged with the optlock option
+// when the row was changed since its version was read, or does not exist anymore.
+var ErrStaleRow = errors.New("stale row")
+
+// ScanError is returned when a row fails to be scanned into a struct. It describes the column
+// which failed to be scanned or converted and the field it maps to, when known.
+type ScanError struct {
+	// Row is the index of the row in the result, from 0.
+	Row int
+	// Column is the name of the column, empty if unknown.
+	Column string
+	// OID is the type OID of the column.
+	OID uint32
+	// Field is the name of the struct field the column maps to, empty if unknown or unmapped.
+	Field string
+	// FieldType is the type of Field.
+	FieldType reflect.Type
+	// Err is the error the column failed to be scanned or converted with.
+	Err error
+}
+
+func (e *ScanError) Error() string {
+	switch {
+	case e.Column == "":
+		return fmt.Sprintf("failed to scan row %d: %v", e.Row, e.Err)
+	case e.Field == "":
+		return fmt.Sprintf("failed to scan column %q (oid %d) of row %d: %v", e.Column, e.OID, e.Row, e.Err)
+	default:
+		return fmt.Sprintf("failed to scan column %q (oid %d) of row %d into field %s of type %s: %v",
+			e.Column, e.OID, e.Row, e.Field, e.FieldType, e.Err)
+	}
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// columnDecoder wraps the scan destination of a column, so that the column a scan fails on is
+// known without relying on the errors of pgx. A nil dest skips the column, like pgx does. Being
+// of the same type for every row, it also keeps pgx from scanning the next rows with the plans
+// made for the destinations of the first one.
+type columnDecoder struct {
+	oid  uint32
+	dest interface{}
+	err  error
+}
+
+func (d *columnDecoder) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	if d.dest == nil {
+		return nil
+	}
+	d.err = ci.Scan(d.oid, pgtype.BinaryFormatCode, src, d.dest)
+	return d.err
+}
+
+func (d *columnDecoder) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	if d.dest == nil {
+		return nil
+	}
+	d.err = ci.Scan(d.oid, pgtype.TextFormatCode, src, d.dest)
+	return d.err
+}
+
+// rowDecoder scans the columns of rows into values, the scan destinations of the current row,
+// through a columnDecoder each. Its buffers are reused from row to row.
+type rowDecoder struct {
+	values   []interface{}
+	decoders []columnDecoder
+	dests    []interface{}
+}
+
+// newRowDecoder returns a rowDecoder for columns of types oids.
+func newRowDecoder(oids []uint32) *rowDecoder {
+	d := &rowDecoder{
+		values:   make([]interface{}, len(oids)),
+		decoders: make([]columnDecoder, len(oids)),
+		dests:    make([]interface{}, len(oids)),
+	}
+	for i, oid := range oids {
+		d.decoders[i].oid = oid
+		d.dests[i] = &d.decoders[i]
+	}
+	return d
+}
+
+// scan scans the current row of r into the values of d. On failure it returns the index of the
+// failing column and its error, or -1 and the error of r if the scan failed before decoding a
+// column.
+func (d *rowDecoder) scan(r pgx.Rows) (int, error) {
+	for i := range d.decoders {
+		d.decoders[i].dest, d.decoders[i].err = d.values[i], nil
+	}
+	err := r.Scan(d.dests...)
+	if err == nil {
+		return -1, nil
+	}
+	for i := range d.decoders {
+		if d.decoders[i].err != nil {
+			return i, d.decoders[i].err
+		}
+	}
+	return -1, err
+}
+
+// notFoundError wraps pgx.ErrNoRows so that it matches ErrNotFound as well.
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// SQLState returns the SQLSTATE code of the PostgreSQL error err is or wraps, such as "23505"
+// for a unique violation, or an empty string if it has none.
+func SQLState(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
+	return pgErr.Code
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation (SQLSTATE 23505).
+func IsUniqueViolation(err error) bool {
+	return SQLState(err) == "23505"
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key constraint violation (SQLSTATE 23503).
+func IsForeignKeyViolation(err error) bool {
+	return SQLState(err) == "23503"
+}
+
+// IsCheckViolation reports whether err is a check constraint violation (SQLSTATE 23514).
+func IsCheckViolation(err error) bool {
+	return SQLState(err) == "23514"
+}
@@ -0,0 +1,50 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLState(t *testing.T) {
+	conn := connect(t)
+	_, err := conn.Exec(context.Background(), "DROP TABLE IF EXISTS errors_child_test")
+	require.NoError(t, err)
+	createTable(t, conn, "errors_parent_test", `id integer PRIMARY KEY`)
+	createTable(t, conn, "errors_child_test", `
+		id        integer PRIMARY KEY CHECK (id > 0),
+		parent_id integer NOT NULL REFERENCES errors_parent_test (id)
+	`)
+
+	_, err = conn.Exec(context.Background(), "INSERT INTO errors_parent_test (id) VALUES (1)")
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), "INSERT INTO errors_parent_test (id) VALUES (1)")
+	assert.Equal(t, "23505", SQLState(err))
+	assert.True(t, IsUniqueViolation(err))
+	assert.False(t, IsForeignKeyViolation(err))
+
+	_, err = conn.Exec(context.Background(), "INSERT INTO errors_child_test (id, parent_id) VALUES (1, 2)")
+	assert.True(t, IsForeignKeyViolation(err))
+	assert.False(t, IsCheckViolation(err))
+
+	_, err = conn.Exec(context.Background(), "INSERT INTO errors_child_test (id, parent_id) VALUES (-1, 1)")
+	assert.True(t, IsCheckViolation(err))
+	assert.False(t, IsUniqueViolation(err))
+
+	// test some fail cases
+	assert.Equal(t, "", SQLState(nil))
+	assert.Equal(t, "", SQLState(errors.New("foo")))
+	assert.False(t, IsUniqueViolation(nil))
+}
+
+func TestSQLStateWrapped(t *testing.T) {
+	err := errors.Wrap(&pgconn.PgError{Code: "23505"}, "insert user")
+	assert.Equal(t, "23505", SQLState(err))
+	assert.True(t, IsUniqueViolation(err))
+	assert.False(t, IsCheckViolation(err))
+}
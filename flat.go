@@ -0,0 +1,121 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// Exists reports whether the query returns at least one row. Only the first row is read,
+// so the query does not need to select anything in particular.
+func Exists(ctx context.Context, querier Querier, query string, args ...interface{}) (bool, error) {
+	rows, err := defaultScanner.query(ctx, querier, query, args)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	exists := rows.Next()
+	rows.Close()
+	return exists, rows.Err()
+}
+
+// Count scans the single bigint, or other integer, value returned by the query, such as the result
+// of SELECT count(*). It fails if the query returns more than one column or row.
+func Count(ctx context.Context, querier Querier, query string, args ...interface{}) (int64, error) {
+	rows, err := defaultScanner.query(ctx, querier, query, args)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	if err := scanValue(rows, &count); err != nil {
+		return 0, err
+	}
+	if rows.Next() {
+		return 0, ErrTooManyRows
+	}
+	return count, rows.Err()
+}
+
+// GetFlat scans the value of the single column of the first row of the query result into dest,
+// passed by reference, complementing SelectFlat. If there are no rows pgx.ErrNoRows is returned.
+func GetFlat(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	rows, err := defaultScanner.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return scanValue(rows, dest)
+}
+
+// scanValue scans the value of the first row of r, a result of a single column, into dest.
+func scanValue(r pgx.Rows, dest interface{}) error {
+	if n := len(r.FieldDescriptions()); n != 1 {
+		return errors.Errorf("expected a single column, got %d", n)
+	}
+
+	if !r.Next() {
+		if err := r.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+
+	return errors.Wrap(r.Scan(dest), "failed to parse a row")
+}
+
+// Pluck scans the column named column of each row of the query result into the slice dest passed
+// by reference, ignoring the other columns, unlike SelectFlat which expects a single column.
+func Pluck(ctx context.Context, querier Querier, dest interface{}, column string, query string, args ...interface{}) error {
+	rows, err := defaultScanner.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	return ScanPluck(rows, dest, column)
+}
+
+// ScanPluck scans the column named column of each row of r into the slice dest passed by reference.
+// Function call closes rows, so caller may skip it.
+func ScanPluck(r pgx.Rows, dest interface{}, column string) error {
+	defer r.Close()
+
+	valDest := reflect.ValueOf(dest)
+	if valDest.Kind() != reflect.Ptr || valDest.Elem().Kind() != reflect.Slice {
+		return errors.New("invalid input, expected a pointer to a slice")
+	}
+
+	index := -1
+	fields := r.FieldDescriptions()
+	for i, fd := range fields {
+		if string(fd.Name) == column {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errors.Errorf("missing column %q in the result", column)
+	}
+
+	typSlice := valDest.Elem().Type()
+	valSlice := reflect.MakeSlice(typSlice, 0, 0)
+	values := make([]interface{}, len(fields))
+	for r.Next() {
+		valRow := reflect.New(typSlice.Elem())
+		values[index] = valRow.Interface()
+		if err := r.Scan(values...); err != nil {
+			return errors.Wrap(err, "failed to parse a row")
+		}
+		valSlice = reflect.Append(valSlice, valRow.Elem())
+	}
+
+	if err := r.Err(); err != nil {
+		return err
+	}
+	valDest.Elem().Set(valSlice)
+	return nil
+}
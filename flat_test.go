@@ -0,0 +1,118 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExists(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	exists, err := Exists(context.Background(), conn, "SELECT 1 FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = Exists(context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// test some fail cases
+	_, err = Exists(context.Background(), conn, "SELECT * FROM structscan_test_missing")
+	require.Error(t, err)
+}
+
+func TestCount(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	count, err := Count(context.Background(), conn, "SELECT count(*) FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = Count(context.Background(), conn, "SELECT count(*)::int FROM structscan_test WHERE id = $1", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// test some fail cases
+	_, err = Count(context.Background(), conn, "SELECT count(*), 1 FROM structscan_test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a single column, got 2")
+
+	_, err = Count(context.Background(), conn, "SELECT 1 FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	assert.Equal(t, ErrTooManyRows, err)
+
+	_, err = Count(context.Background(), conn, "SELECT 1 FROM structscan_test WHERE id = $1", "foo")
+	assert.Equal(t, pgx.ErrNoRows, err)
+}
+
+func TestPluck(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	var ids []string
+	err := Pluck(context.Background(), conn, &ids, "id", "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{e1.ID, e2.ID}, ids)
+
+	var data []*string
+	err = Pluck(context.Background(), conn, &data, "some_data", "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+	assert.Equal(t, e1.SomeData, *data[0])
+
+	// test some fail cases
+	err = Pluck(context.Background(), conn, &ids, "foo", "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing column "foo" in the result`)
+
+	err = Pluck(context.Background(), conn, ids, "id", "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.Error(t, err)
+
+	var wrongType []int
+	err = Pluck(context.Background(), conn, &wrongType, "id", "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.Error(t, err)
+}
+
+func TestScanPluck(t *testing.T) {
+	var data []string
+	err := ScanPluck(newFakeRows(2), &data, "some_data")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo bar baz", "foo bar baz"}, data)
+}
+
+func TestGetFlat(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	var data string
+	err := GetFlat(context.Background(), conn, &data, "SELECT some_data FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.SomeData, data)
+
+	var null *string
+	err = GetFlat(context.Background(), conn, &null, "SELECT NULL::text")
+	require.NoError(t, err)
+	assert.Nil(t, null)
+
+	// test some fail cases
+	err = GetFlat(context.Background(), conn, &data, "SELECT some_data FROM structscan_test WHERE id = $1", "foo")
+	assert.Equal(t, pgx.ErrNoRows, err)
+
+	err = GetFlat(context.Background(), conn, &data, "SELECT id, some_data FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a single column, got 2")
+
+	var wrongType int
+	err = GetFlat(context.Background(), conn, &wrongType, "SELECT some_data FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// generatedScanners holds the scanners registered with RegisterScanner, by struct type.
+var generatedScanners sync.Map
+
+// RegisterScanner makes Get, Select and the other functions scanning structs scan the rows
+// into T with fn instead of reflection. pgxscan-gen generates such scanners, registered in
+// the init function of the file it writes:
+//
+//	//go:generate go run github.com/pyr-sh/pgxscan/v2/cmd/pgxscan-gen
+//
+// fn scans the current row of r into dest. Results with columns pgxscan decodes in steps of its
+// own, such as numerics scanned into *big.Rat fields, are still scanned with reflection.
+func RegisterScanner[T any](fn func(r pgx.Rows, dest *T) error) {
+	generatedScanners.Store(reflect.TypeOf((*T)(nil)).Elem(), func(r pgx.Rows, v reflect.Value) error {
+		return fn(r, v.Interface().(*T))
+	})
+}
+
+// generatedScanner returns the scanner registered for the struct type t, to scan the rows of r
+// with, unless options of s change how columns are mapped or decoded, or a column of r needs
+// decoding steps of its own, such as a numeric scanned into a *big.Rat or a type with a
+// registered converter, which generated scanners know nothing about.
+func (s *Scanner) generatedScanner(t reflect.Type, r pgx.Rows) (func(pgx.Rows, reflect.Value) error, bool) {
+	if s.fieldMapper != nil || len(s.pipelines) > 0 || s.location != nil || len(s.unions) > 0 ||
+		len(s.converters) > 0 || s.defaults != nil || s.unsafe || s.strict {
+		return nil, false
+	}
+	fn, ok := generatedScanners.Load(t)
+	if !ok || !s.scansDirectly(t, r) {
+		return nil, false
+	}
+	return fn.(func(pgx.Rows, reflect.Value) error), true
+}
+
+// scansDirectly reports whether every column of r mapped to a field of the struct type t is
+// scanned by pgx into the field itself, like generated scanners do.
+func (s *Scanner) scansDirectly(t reflect.Type, r pgx.Rows) bool {
+	fieldDescriptions := r.FieldDescriptions()
+	columns := make([]string, len(fieldDescriptions))
+	for i, fieldDescription := range fieldDescriptions {
+		columns[i] = string(fieldDescription.Name)
+	}
+
+	v := reflect.New(t)
+	traversals := s.traversalsByName(v.Type(), columns)
+	values := make([]interface{}, len(columns))
+	conversions, err := s.fieldsByTraversal(v, columns, columnOIDs(r), traversals, values)
+	if err != nil {
+		return false
+	}
+	for i, traversal := range traversals {
+		if len(traversal) == 0 {
+			continue
+		}
+		f, ok := fieldByIndexes(v, traversal)
+		if !ok || conversions[i] != nil || values[i] != f.Addr().Interface() {
+			return false
+		}
+	}
+	return true
+}
+
+// scanGenerated scans the current row of r, of index row, into the struct v with fn, a
+// generated scanner, and runs the BeforeScan method of v before the first row and its
+// AfterScan method, if any.
+func scanGenerated(ctx context.Context, r pgx.Rows, row int, v reflect.Value, fn func(pgx.Rows, reflect.Value) error) error {
+	if hook, ok := v.Interface().(BeforeScanner); ok && row == 0 {
+		if err := hook.BeforeScan(r.FieldDescriptions()); err != nil {
+			return errors.Wrap(err, "BeforeScan failed")
+		}
+	}
+	if err := fn(r, v); err != nil {
+		return &ScanError{Row: row, Err: err}
+	}
+	return afterScanHook(ctx, v)
+}
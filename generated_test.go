@@ -0,0 +1,103 @@
+package pgxscan
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testGeneratedRow struct {
+	ID        string    `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	SomeData  string    `db:"some_data"`
+	generated bool
+}
+
+// scan is written like the scanners generated by pgxscan-gen.
+func (dest *testGeneratedRow) scan(r pgx.Rows) error {
+	fields := r.FieldDescriptions()
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		switch string(field.Name) {
+		case "id":
+			values[i] = &dest.ID
+		case "created_at":
+			values[i] = &dest.CreatedAt
+		case "some_data":
+			values[i] = &dest.SomeData
+		default:
+			return errors.Errorf("missing column %q in dest %T", field.Name, dest)
+		}
+	}
+	dest.generated = true
+	return r.Scan(values...)
+}
+
+func TestRegisterScanner(t *testing.T) {
+	RegisterScanner(func(r pgx.Rows, dest *testGeneratedRow) error { return dest.scan(r) })
+	defer generatedScanners.Delete(reflect.TypeOf(testGeneratedRow{}))
+
+	var rows []testGeneratedRow
+	err := ScanStructs(newFakeRows(3), &rows)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.True(t, rows[0].generated)
+	assert.Equal(t, "bench-1", rows[1].ID)
+	assert.Equal(t, "foo bar baz", rows[2].SomeData)
+
+	var ptrs []*testGeneratedRow
+	err = ScanStructs(newFakeRows(2), &ptrs)
+	require.NoError(t, err)
+	require.Len(t, ptrs, 2)
+	assert.True(t, ptrs[1].generated)
+
+	var row testGeneratedRow
+	err = ScanStruct(newFakeRows(1), &row)
+	require.NoError(t, err)
+	assert.True(t, row.generated)
+	assert.Equal(t, "bench-0", row.ID)
+
+	// options changing the mapping fall back to reflection
+	row = testGeneratedRow{}
+	err = New(WithStrict()).ScanStruct(newFakeRows(1), &row)
+	require.NoError(t, err)
+	assert.False(t, row.generated)
+	assert.Equal(t, "bench-0", row.ID)
+
+	// columns needing decoding steps of their own fall back to reflection
+	type testGeneratedRat struct {
+		ID     string   `db:"id"`
+		Amount *big.Rat `db:"amount"`
+	}
+	RegisterScanner(func(r pgx.Rows, dest *testGeneratedRat) error {
+		return r.Scan(&dest.ID, &dest.Amount)
+	})
+	defer generatedScanners.Delete(reflect.TypeOf(testGeneratedRat{}))
+	rats := newFakeRows(1)
+	rats.fields = []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("amount"), DataTypeOID: pgtype.NumericOID},
+	}
+	rats.rows = [][][]byte{{[]byte("rat-1"), []byte("1.5")}}
+	var rat []testGeneratedRat
+	err = ScanStructs(rats, &rat)
+	require.NoError(t, err)
+	require.Len(t, rat, 1)
+	assert.Equal(t, "3/2", rat[0].Amount.String())
+
+	// test some fail cases
+	RegisterScanner(func(r pgx.Rows, dest *testGeneratedRow) error { return errors.New("boom") })
+	err = ScanStructs(newFakeRows(2), &rows)
+	require.Error(t, err)
+	var scanErr *ScanError
+	require.True(t, errors.As(err, &scanErr))
+	assert.Equal(t, 0, scanErr.Row)
+}
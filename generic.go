@@ -0,0 +1,87 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// GetAs scans the first row of the query result into a new T, see Get.
+// T is the struct type itself, so no destination needs to be declared and passed by pointer.
+func GetAs[T any](ctx context.Context, querier Querier, query string, args ...interface{}) (T, error) {
+	var dest T
+	if err := Get(ctx, querier, &dest, query, args...); err != nil {
+		var zero T
+		return zero, err
+	}
+	return dest, nil
+}
+
+// SelectAs scans the query result into a new []T, see Select. T is a struct type or a pointer
+// to one. The slice is empty, not nil, if there are no rows.
+func SelectAs[T any](ctx context.Context, querier Querier, query string, args ...interface{}) ([]T, error) {
+	dest := []T{}
+	if err := Select(ctx, querier, &dest, query, args...); err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// GetOrNotFound scans the first row of the query result into a new T, see Get.
+// If there are no rows, the zero T is returned with an error matching both ErrNotFound and pgx.ErrNoRows.
+func GetOrNotFound[T any](ctx context.Context, querier Querier, query string, args ...interface{}) (T, error) {
+	var dest T
+	if err := Get(ctx, querier, &dest, query, args...); err != nil {
+		var zero T
+		if errors.Is(err, pgx.ErrNoRows) {
+			return zero, &notFoundError{err: err}
+		}
+		return zero, err
+	}
+	return dest, nil
+}
+
+// pools holds a *sync.Pool of destinations for each type scanned by GetPooled.
+var pools sync.Map
+
+// GetPooled scans the first row of the query result into a T taken from a pool, see Get.
+// It saves the allocation of a destination on hot paths getting the same type repeatedly.
+//
+// The caller must call release once done with the result, and must not use or retain it,
+// nor anything it references, afterwards: release zeroes the struct and hands it out again.
+// Calling release more than once is a no-op. On error, the destination is released already
+// and release is nil.
+func GetPooled[T any](ctx context.Context, querier Querier, query string, args ...interface{}) (dest *T, release func(), err error) {
+	pool := poolOf[T]()
+	dest = pool.Get().(*T)
+	var once sync.Once
+	release = func() {
+		// putting dest twice would hand it out to two callers at once
+		once.Do(func() {
+			var zero T
+			*dest = zero
+			pool.Put(dest)
+		})
+	}
+
+	if err := Get(ctx, querier, dest, query, args...); err != nil {
+		release()
+		return nil, nil, err
+	}
+	return dest, release, nil
+}
+
+func poolOf[T any]() *sync.Pool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if pool, ok := pools.Load(t); ok {
+		return pool.(*sync.Pool)
+	}
+
+	pool, _ := pools.LoadOrStore(t, &sync.Pool{
+		New: func() interface{} { return new(T) },
+	})
+	return pool.(*sync.Pool)
+}
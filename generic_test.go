@@ -0,0 +1,126 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrNotFound(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	result, err := GetOrNotFound[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+	assert.Equal(t, e1.SomeData, result.SomeData)
+	// compare unit timestamp to avoid milliseconds diff
+	assert.Equal(t, e1.CreatedAt.Unix(), result.CreatedAt.Unix())
+
+	// test some fail cases
+	resultEmpty, err := GetOrNotFound[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+	assert.Equal(t, testEntity{}, resultEmpty)
+
+	_, err = GetOrNotFound[testMissingField](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
+
+func TestGetPooled(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	for i := 0; i < 10; i++ {
+		e := e1
+		if i%2 == 1 {
+			e = e2
+		}
+
+		result, release, err := GetPooled[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e.ID)
+		require.NoError(t, err)
+		assert.Equal(t, e.ID, result.ID)
+		assert.Equal(t, e.SomeData, result.SomeData)
+		// compare unit timestamp to avoid milliseconds diff
+		assert.Equal(t, e.CreatedAt.Unix(), result.CreatedAt.Unix())
+
+		release()
+		// released destinations are zeroed before being reused
+		assert.Equal(t, testEntity{}, *result)
+	}
+
+	// releasing twice doesn't put the destination back twice
+	result, release, err := GetPooled[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	release()
+	release()
+	first, releaseFirst, err := GetPooled[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	second, releaseSecond, err := GetPooled[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e2.ID)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+	assert.Equal(t, e1.ID, first.ID)
+	releaseFirst()
+	releaseSecond()
+
+	// test some fail cases
+	result, release, err = GetPooled[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+	assert.Nil(t, result)
+	assert.Nil(t, release)
+}
+
+func TestGetAs(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	result, err := GetAs[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+	assert.Equal(t, e1.SomeData, result.SomeData)
+	// compare unit timestamp to avoid milliseconds diff
+	assert.Equal(t, e1.CreatedAt.Unix(), result.CreatedAt.Unix())
+
+	// test some fail cases
+	resultEmpty, err := GetAs[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+	assert.Equal(t, testEntity{}, resultEmpty)
+}
+
+func TestSelectAs(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	result, err := SelectAs[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, e1.ID, result[0].ID)
+	assert.Equal(t, e2.ID, result[1].ID)
+
+	resultPtr, err := SelectAs[*testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, resultPtr, 2)
+	assert.Equal(t, e1.ID, resultPtr[0].ID)
+	assert.Equal(t, e2.SomeData, resultPtr[1].SomeData)
+
+	resultEmpty, err := SelectAs[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.NoError(t, err)
+	assert.NotNil(t, resultEmpty)
+	assert.Empty(t, resultEmpty)
+
+	// test some fail cases
+	_, err = SelectAs[testMissingField](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+}
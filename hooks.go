@@ -0,0 +1,159 @@
+package pgxscan
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+)
+
+// QueryEvent describes a query run through a Querier, once done with.
+type QueryEvent struct {
+	// Method is the Querier method the query was run with: Query, QueryRow or Exec.
+	Method string
+	Query  string
+	Args   []interface{}
+	// Duration runs until the rows are closed, so it includes the time spent scanning them.
+	Duration time.Duration
+	// Rows is the number of rows read, or affected for Exec.
+	Rows int64
+	// Err is the error the query failed with, if any. pgx.ErrNoRows is not an error.
+	Err error
+}
+
+// MetricsHook is notified of the queries run through the Querier returned by Metrics, to record
+// them with any metrics backend.
+type MetricsHook interface {
+	ObserveQuery(ctx context.Context, event QueryEvent)
+}
+
+// Metrics returns a Middleware notifying hook of each query once done with.
+func Metrics(hook MetricsHook) Middleware {
+	return func(q Querier) Querier {
+		return &observedQuerier{inner: q, observe: hook.ObserveQuery}
+	}
+}
+
+// TraceHook is a MetricsHook also notified when each query run through the Querier returned
+// by Trace starts. StartQuery returns the context to run the query with, such as one carrying
+// a span, which ObserveQuery then gets.
+type TraceHook interface {
+	MetricsHook
+	StartQuery(ctx context.Context, event QueryEvent) context.Context
+}
+
+// Trace returns a Middleware notifying hook when each query starts and once done with it.
+func Trace(hook TraceHook) Middleware {
+	return func(q Querier) Querier {
+		return &observedQuerier{inner: q, before: hook.StartQuery, observe: hook.ObserveQuery}
+	}
+}
+
+// observedQuerier calls before when each query run with inner starts, if not nil, running the
+// query with the context it returns, and observe once done with it.
+type observedQuerier struct {
+	inner   Querier
+	before  func(ctx context.Context, event QueryEvent) context.Context
+	observe func(ctx context.Context, event QueryEvent)
+}
+
+func (q *observedQuerier) begin(ctx context.Context, event QueryEvent) context.Context {
+	if q.before != nil {
+		return q.before(ctx, event)
+	}
+	return ctx
+}
+
+func (q *observedQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	event := QueryEvent{Method: "Query", Query: sql, Args: args}
+	ctx = q.begin(ctx, event)
+	start := time.Now()
+
+	rows, err := q.inner.Query(ctx, sql, args...)
+	if err != nil {
+		event.Duration = time.Since(start)
+		event.Err = err
+		q.observe(ctx, event)
+		return nil, err
+	}
+	return &observedRows{Rows: rows, ctx: ctx, q: q, event: event, start: start}, nil
+}
+
+func (q *observedQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	event := QueryEvent{Method: "QueryRow", Query: sql, Args: args}
+	ctx = q.begin(ctx, event)
+	return &observedRow{row: q.inner.QueryRow(ctx, sql, args...), ctx: ctx, q: q, event: event, start: time.Now()}
+}
+
+func (q *observedQuerier) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	event := QueryEvent{Method: "Exec", Query: sql, Args: args}
+	ctx = q.begin(ctx, event)
+	start := time.Now()
+
+	tag, err := q.inner.Exec(ctx, sql, args...)
+	event.Duration = time.Since(start)
+	event.Rows = tag.RowsAffected()
+	event.Err = err
+	q.observe(ctx, event)
+	return tag, err
+}
+
+// observedRows counts the rows read and reports their query once they are closed.
+type observedRows struct {
+	pgx.Rows
+	ctx   context.Context
+	q     *observedQuerier
+	event QueryEvent
+	start time.Time
+	done  bool
+}
+
+func (r *observedRows) Next() bool {
+	if r.Rows.Next() {
+		r.event.Rows++
+		return true
+	}
+	r.finish()
+	return false
+}
+
+func (r *observedRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *observedRows) finish() {
+	if r.done {
+		return
+	}
+	r.done = true
+
+	r.event.Duration = time.Since(r.start)
+	r.event.Err = r.Rows.Err()
+	r.q.observe(r.ctx, r.event)
+}
+
+// observedRow reports its query once scanned.
+type observedRow struct {
+	row   pgx.Row
+	ctx   context.Context
+	q     *observedQuerier
+	event QueryEvent
+	start time.Time
+}
+
+func (r *observedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+
+	r.event.Duration = time.Since(r.start)
+	switch err {
+	case nil:
+		r.event.Rows = 1
+	case pgx.ErrNoRows:
+	default:
+		r.event.Err = err
+	}
+	r.q.observe(r.ctx, r.event)
+	return err
+}
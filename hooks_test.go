@@ -0,0 +1,60 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHook struct {
+	events []QueryEvent
+}
+
+func (h *recordingHook) ObserveQuery(ctx context.Context, event QueryEvent) {
+	h.events = append(h.events, event)
+}
+
+func TestMetrics(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	hook := &recordingHook{}
+	q := Wrap(conn, Metrics(hook))
+
+	var result []*testEntity
+	err := Select(context.Background(), q, &result, "SELECT * FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	require.NoError(t, err)
+
+	var data string
+	err = q.QueryRow(context.Background(), "SELECT some_data FROM structscan_test WHERE id = $1", "foo").Scan(&data)
+	assert.Equal(t, pgx.ErrNoRows, err)
+
+	_, err = q.Exec(context.Background(), "UPDATE structscan_test SET some_data = some_data WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+
+	// test some fail cases
+	_, err = q.Exec(context.Background(), "UPDATE structscan_test_missing SET foo = 1")
+	require.Error(t, err)
+
+	require.Len(t, hook.events, 4)
+
+	assert.Equal(t, "Query", hook.events[0].Method)
+	assert.Equal(t, []interface{}{e1.ID, e2.ID}, hook.events[0].Args)
+	assert.Equal(t, int64(2), hook.events[0].Rows)
+	assert.NoError(t, hook.events[0].Err)
+	assert.Positive(t, hook.events[0].Duration)
+
+	assert.Equal(t, "QueryRow", hook.events[1].Method)
+	assert.Equal(t, int64(0), hook.events[1].Rows)
+	assert.NoError(t, hook.events[1].Err)
+
+	assert.Equal(t, "Exec", hook.events[2].Method)
+	assert.Equal(t, int64(1), hook.events[2].Rows)
+
+	assert.Equal(t, "UPDATE structscan_test_missing SET foo = 1", hook.events[3].Query)
+	assert.Error(t, hook.events[3].Err)
+}
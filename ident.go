@@ -0,0 +1,106 @@
+package pgxscan
+
+import (
+	"strings"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// maxIdentifierLength is the length Postgres truncates longer identifiers to.
+const maxIdentifierLength = 63
+
+// BindIdentifiers replaces the {name} placeholders of query with the identifiers idents maps
+// them to, quoted, so that queries on tables or columns chosen at runtime, such as per-tenant
+// tables, don't need fmt.Sprintf. Values are still passed as $N parameters:
+//
+//	query, err := BindIdentifiers("SELECT {columns} FROM {table} WHERE id = $1", map[string]interface{}{
+//		"table":   "tenant_42.orders",
+//		"columns": []string{"id", "total"},
+//	})
+//
+// An identifier is a string, qualified by dots like "schema.table", or a pgx.Identifier; a
+// []string is a comma-separated list of identifiers. Identifiers must be non-empty, at most
+// 63 bytes long and free of NUL bytes. String literals, quoted identifiers and comments are
+// left untouched.
+func BindIdentifiers(query string, idents map[string]interface{}) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(query); i++ {
+		if end := skipEnd(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+
+		if query[i] != '{' || i+1 >= len(query) || !isNameStart(query[i+1]) {
+			b.WriteByte(query[i])
+			continue
+		}
+		end := i + 1
+		for end < len(query) && isNamePart(query[end]) {
+			end++
+		}
+		if end >= len(query) || query[end] != '}' {
+			b.WriteByte(query[i])
+			continue
+		}
+
+		name := query[i+1 : end]
+		ident, ok := idents[name]
+		if !ok {
+			return "", errors.Errorf("no identifier for placeholder {%s}", name)
+		}
+		quoted, err := quoteIdent(ident)
+		if err != nil {
+			return "", errors.Wrapf(err, "identifier of placeholder {%s}", name)
+		}
+		b.WriteString(quoted)
+		i = end
+	}
+
+	return b.String(), nil
+}
+
+// quoteIdent returns the quoted identifier or list of identifiers ident.
+func quoteIdent(ident interface{}) (string, error) {
+	switch ident := ident.(type) {
+	case string:
+		return quoteIdent(pgx.Identifier(strings.Split(ident, ".")))
+	case pgx.Identifier:
+		if len(ident) == 0 {
+			return "", errors.New("empty identifier")
+		}
+		for _, part := range ident {
+			if err := validIdentifier(part); err != nil {
+				return "", err
+			}
+		}
+		return ident.Sanitize(), nil
+	case []string:
+		if len(ident) == 0 {
+			return "", errors.New("empty list of identifiers")
+		}
+		quoted := make([]string, len(ident))
+		for i, name := range ident {
+			var err error
+			if quoted[i], err = quoteIdent(name); err != nil {
+				return "", err
+			}
+		}
+		return strings.Join(quoted, ", "), nil
+	}
+	return "", errors.Errorf("expected a string, a []string or a pgx.Identifier, got %T", ident)
+}
+
+func validIdentifier(name string) error {
+	switch {
+	case name == "":
+		return errors.New("empty identifier")
+	case len(name) > maxIdentifierLength:
+		return errors.Errorf("identifier %q is longer than %d bytes", name, maxIdentifierLength)
+	case strings.IndexByte(name, 0) >= 0:
+		return errors.Errorf("identifier %q contains a NUL byte", name)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package pgxscan
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindIdentifiers(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	query, err := BindIdentifiers("SELECT {columns} FROM {table} WHERE id IN ($1, $2) ORDER BY {order} ASC", map[string]interface{}{
+		"table":   "public.structscan_test",
+		"columns": []string{"id", "some_data"},
+		"order":   pgx.Identifier{"id"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "id", "some_data" FROM "public"."structscan_test" WHERE id IN ($1, $2) ORDER BY "id" ASC`, query)
+
+	var result []struct {
+		ID       string `db:"id"`
+		SomeData string `db:"some_data"`
+	}
+	err = Select(context.Background(), conn, &result, query, e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, e1.ID, result[0].ID)
+	assert.Equal(t, e2.SomeData, result[1].SomeData)
+
+	query, err = BindIdentifiers(`SELECT '{table}', "{table}", '{a,b}'::text[] /* {table} */ FROM {table} WHERE {x`, map[string]interface{}{
+		"table": `we"ird`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT '{table}', "{table}", '{a,b}'::text[] /* {table} */ FROM "we""ird" WHERE {x`, query)
+
+	// test some fail cases
+	_, err = BindIdentifiers("SELECT * FROM {table}", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no identifier for placeholder {table}")
+
+	for _, ident := range []interface{}{"", "public.", strings.Repeat("a", 64), "a\x00b", []string{}, pgx.Identifier{}, 1} {
+		_, err = BindIdentifiers("SELECT * FROM {table}", map[string]interface{}{"table": ident})
+		require.Error(t, err, "%q", ident)
+	}
+}
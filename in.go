@@ -0,0 +1,84 @@
+package pgxscan
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// In rewrites the ? parameters of query into $1..$N placeholders, expanding each slice
+// argument into one placeholder per element, so that "WHERE id IN (?)" binds a []string.
+// Byte slices and values implementing driver.Valuer are bound as a single argument.
+//
+// String literals, quoted identifiers and comments are left untouched. A literal question mark,
+// such as the jsonb ?, ?| and ?& operators, is escaped by doubling it, like for Rebind.
+func In(query string, args ...interface{}) (string, []interface{}, error) {
+	var (
+		b        strings.Builder
+		expanded []interface{}
+		n        int
+	)
+
+	for i := 0; i < len(query); i++ {
+		if end := skipEnd(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+
+		if query[i] != '?' {
+			b.WriteByte(query[i])
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == '?' {
+			b.WriteByte('?')
+			i++
+			continue
+		}
+		if n >= len(args) {
+			return "", nil, errors.Errorf("number of parameters exceeds the %d arguments", len(args))
+		}
+
+		elems, err := inElems(args[n])
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "argument %d", n+1)
+		}
+		for j, elem := range elems {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			expanded = append(expanded, elem)
+			b.WriteString("$" + strconv.Itoa(len(expanded)))
+		}
+		n++
+	}
+
+	if n != len(args) {
+		return "", nil, errors.Errorf("number of parameters %d does not match the %d arguments", n, len(args))
+	}
+	return b.String(), expanded, nil
+}
+
+// inElems returns the values arg expands into.
+func inElems(arg interface{}) ([]interface{}, error) {
+	if _, ok := arg.(driver.Valuer); ok {
+		return []interface{}{arg}, nil
+	}
+
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return []interface{}{arg}, nil
+	}
+	if v.Len() == 0 {
+		return nil, errors.New("empty slice passed to In")
+	}
+
+	elems := make([]interface{}, v.Len())
+	for i := range elems {
+		elems[i] = v.Index(i).Interface()
+	}
+	return elems, nil
+}
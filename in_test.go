@@ -0,0 +1,49 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIn(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	query, args, err := In("SELECT * FROM structscan_test WHERE id IN (?) AND some_data <> ? ORDER BY id ASC", []string{e1.ID, e2.ID}, "qux")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM structscan_test WHERE id IN ($1, $2) AND some_data <> $3 ORDER BY id ASC", query)
+	assert.Equal(t, []interface{}{e1.ID, e2.ID, "qux"}, args)
+
+	var result []testEntity
+	err = Select(context.Background(), conn, &result, query, args...)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, e1.ID, result[0].ID)
+	assert.Equal(t, e2.ID, result[1].ID)
+
+	query, args, err = In("SELECT '?', \"?\" -- ?\nFROM t WHERE b = ?", []byte("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT '?', \"?\" -- ?\nFROM t WHERE b = $1", query)
+	assert.Equal(t, []interface{}{[]byte("foo")}, args)
+
+	// test some fail cases
+	_, _, err = In("SELECT * FROM t WHERE id IN (?)", []string{})
+	require.Error(t, err)
+
+	_, _, err = In("SELECT * FROM t WHERE id IN (?) AND a = ?", []string{"a"})
+	require.Error(t, err)
+
+	_, _, err = In("SELECT * FROM t WHERE id IN (?)", []string{"a"}, "b")
+	require.Error(t, err)
+}
+
+func TestInQuestionMarks(t *testing.T) {
+	query, args, err := In("SELECT * FROM t WHERE data ?? ? AND tags ??| '{a}' AND id IN (?)", "a", []string{"b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE data ? $1 AND tags ?| '{a}' AND id IN ($2, $3)", query)
+	assert.Equal(t, []interface{}{"a", "b", "c"}, args)
+}
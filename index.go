@@ -0,0 +1,123 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// SelectMap scans the query result into a new map[K]V, keyed by the value of the field mapped
+// to keyColumn in each row, see Select. V is a struct type or a pointer to one, and the field is
+// of type K. Rows with the same key overwrite the ones before them.
+func SelectMap[K comparable, V any](ctx context.Context, querier Querier, keyColumn string, query string, args ...interface{}) (map[K]V, error) {
+	var result map[K]V
+	if err := defaultScanner.SelectMap(ctx, querier, &result, keyColumn, query, args...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SelectMap works like the package-level SelectMap, using the Scanner options, dest being
+// a pointer to the map[K]V, replaced.
+func (s *Scanner) SelectMap(ctx context.Context, querier Querier, dest interface{}, keyColumn string, query string, args ...interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Map {
+		return errors.Errorf("expected a pointer to a map, got %T", dest)
+	}
+	mapType := v.Elem().Type()
+
+	key, err := s.keyOf(mapType.Key(), mapType.Elem(), keyColumn)
+	if err != nil {
+		return err
+	}
+
+	rows := reflect.New(reflect.SliceOf(mapType.Elem()))
+	if err := s.Select(ctx, querier, rows.Interface(), query, args...); err != nil {
+		return err
+	}
+
+	result := reflect.MakeMapWithSize(mapType, rows.Elem().Len())
+	for i := 0; i < rows.Elem().Len(); i++ {
+		row := rows.Elem().Index(i)
+		k, err := key(row)
+		if err != nil {
+			return err
+		}
+		result.SetMapIndex(k, row)
+	}
+	v.Elem().Set(result)
+	return nil
+}
+
+// SelectGrouped scans the query result into a new map[K][]V, grouping the rows by the value of
+// the field mapped to keyColumn, see SelectMap. Rows keep the order of the result within groups,
+// so children of a set of parents can be loaded with a single query.
+func SelectGrouped[K comparable, V any](ctx context.Context, querier Querier, keyColumn string, query string, args ...interface{}) (map[K][]V, error) {
+	var result map[K][]V
+	if err := defaultScanner.SelectGrouped(ctx, querier, &result, keyColumn, query, args...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SelectGrouped works like the package-level SelectGrouped, using the Scanner options, dest
+// being a pointer to the map[K][]V, replaced.
+func (s *Scanner) SelectGrouped(ctx context.Context, querier Querier, dest interface{}, keyColumn string, query string, args ...interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Map || v.Elem().Type().Elem().Kind() != reflect.Slice {
+		return errors.Errorf("expected a pointer to a map of slices, got %T", dest)
+	}
+	mapType := v.Elem().Type()
+
+	key, err := s.keyOf(mapType.Key(), mapType.Elem().Elem(), keyColumn)
+	if err != nil {
+		return err
+	}
+
+	rows := reflect.New(mapType.Elem())
+	if err := s.Select(ctx, querier, rows.Interface(), query, args...); err != nil {
+		return err
+	}
+
+	result := reflect.MakeMap(mapType)
+	for i := 0; i < rows.Elem().Len(); i++ {
+		row := rows.Elem().Index(i)
+		k, err := key(row)
+		if err != nil {
+			return err
+		}
+		group := result.MapIndex(k)
+		if !group.IsValid() {
+			group = reflect.MakeSlice(mapType.Elem(), 0, 1)
+		}
+		result.SetMapIndex(k, reflect.Append(group, row))
+	}
+	v.Elem().Set(result)
+	return nil
+}
+
+// keyOf returns a function reading the key of type keyType from the field of rowType, a struct
+// type or a pointer to one, mapped to keyColumn.
+func (s *Scanner) keyOf(keyType, rowType reflect.Type, keyColumn string) (func(row reflect.Value) (reflect.Value, error), error) {
+	t := reflectx.Deref(rowType)
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("expected a struct or a pointer to a struct, got %s", rowType)
+	}
+	fi := s.mapper().TypeMap(t).GetByPath(keyColumn)
+	if fi == nil {
+		return nil, errors.Errorf("missing field for key column %q in dest %s", keyColumn, t)
+	}
+	if fi.Field.Type != keyType {
+		return nil, errors.Errorf("key column %q is scanned into a %s, not a %s", keyColumn, fi.Field.Type, keyType)
+	}
+
+	return func(row reflect.Value) (reflect.Value, error) {
+		f, ok := fieldByIndexes(reflect.Indirect(row), fi.Index)
+		if !ok {
+			return reflect.Value{}, errors.Errorf("key column %q is in a nil struct", keyColumn)
+		}
+		return f, nil
+	}, nil
+}
@@ -0,0 +1,88 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectMap(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	result, err := SelectMap[string, *testEntity](context.Background(), conn, "id", "SELECT * FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, e1.SomeData, result[e1.ID].SomeData)
+	assert.Equal(t, e2.SomeData, result[e2.ID].SomeData)
+
+	resultValues, err := SelectMap[string, testEntity](context.Background(), conn, "some_data", "SELECT * FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, resultValues, 2)
+	assert.Equal(t, e1.ID, resultValues[e1.SomeData].ID)
+
+	// the key column is mapped with the options of the Scanner
+	type testJSONTagged struct {
+		ID       string `json:"id"`
+		SomeData string `json:"some_data"`
+	}
+	var tagged map[string]testJSONTagged
+	err = New(WithTagName("json"), WithUnsafe()).SelectMap(context.Background(), conn, &tagged, "id", "SELECT * FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, tagged, 2)
+	assert.Equal(t, e2.SomeData, tagged[e2.ID].SomeData)
+
+	// test some fail cases
+	_, err = SelectMap[string, testEntity](context.Background(), conn, "foo", "SELECT * FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing field for key column "foo"`)
+
+	_, err = SelectMap[int, testEntity](context.Background(), conn, "id", "SELECT * FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `key column "id" is scanned into a string, not a int`)
+
+	_, err = SelectMap[string, testEntity](context.Background(), conn, "id", "SELECT * FROM structscan_test_missing")
+	require.Error(t, err)
+}
+
+func TestSelectGrouped(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	result, err := SelectGrouped[string, *testEntity](context.Background(), conn, "some_data", "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Len(t, result[e1.SomeData], 1)
+	assert.Equal(t, e1.ID, result[e1.SomeData][0].ID)
+
+	query := "SELECT id, created_at, 'same' AS some_data FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC"
+	resultSame, err := SelectGrouped[string, testEntity](context.Background(), conn, "some_data", query, e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, resultSame, 1)
+	require.Len(t, resultSame["same"], 2)
+	assert.Equal(t, e1.ID, resultSame["same"][0].ID)
+	assert.Equal(t, e2.ID, resultSame["same"][1].ID)
+
+	type testJSONTagged struct {
+		ID       string `json:"id"`
+		SomeData string `json:"some_data"`
+	}
+	var tagged map[string][]*testJSONTagged
+	err = New(WithTagName("json"), WithUnsafe()).SelectGrouped(context.Background(), conn, &tagged, "some_data", query, e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, tagged["same"], 2)
+	assert.Equal(t, e1.ID, tagged["same"][0].ID)
+
+	// test some fail cases
+	_, err = SelectGrouped[string, testEntity](context.Background(), conn, "foo", query, e1.ID, e2.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing field for key column "foo"`)
+
+	err = New().SelectGrouped(context.Background(), conn, &map[string]testEntity{}, "id", query, e1.ID, e2.ID)
+	require.Error(t, err)
+	assert.Equal(t, "expected a pointer to a map of slices, got *map[string]pgxscan.testEntity", err.Error())
+}
@@ -0,0 +1,88 @@
+package pgxscan
+
+import (
+	"net"
+	"net/netip"
+	"reflect"
+
+	"github.com/jackc/pgtype"
+	"github.com/pkg/errors"
+)
+
+var (
+	ipType       = reflect.TypeOf(net.IP{})
+	ipNetType    = reflect.TypeOf(net.IPNet{})
+	addrType     = reflect.TypeOf(netip.Addr{})
+	prefixType   = reflect.TypeOf(netip.Prefix{})
+	networkTypes = []reflect.Type{ipType, ipNetType, addrType, prefixType}
+)
+
+// inetTarget returns the scan destination of the field f and the conversion decoding it, if f
+// is a net.IP, a net.IPNet, a netip.Addr or a netip.Prefix, or a pointer to either, and oid the
+// inet or cidr type. Addresses with a netmask only fit net.IPNet and netip.Prefix fields.
+func inetTarget(f reflect.Value, oid uint32) (interface{}, func() error, bool) {
+	if oid != pgtype.InetOID && oid != pgtype.CIDROID {
+		return nil, nil, false
+	}
+	t := f.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !containsType(networkTypes, t) {
+		return nil, nil, false
+	}
+
+	holder := new(pgtype.Inet)
+	return holder, func() error {
+		if holder.Status != pgtype.Present {
+			// net.IP is a slice, which NULL makes nil
+			if t == ipType {
+				f.Set(reflect.Zero(f.Type()))
+				return nil
+			}
+			return setNull(f)
+		}
+
+		value, err := networkValue(holder.IPNet, t)
+		if err != nil {
+			return err
+		}
+		setValue(f, value)
+		return nil
+	}, true
+}
+
+// networkValue converts ipNet into a value of t, one of networkTypes.
+func networkValue(ipNet *net.IPNet, t reflect.Type) (reflect.Value, error) {
+	ip := ipNet.IP
+	if len(ipNet.Mask) == net.IPv4len {
+		ip = ip.To4()
+	}
+	ones, bits := ipNet.Mask.Size()
+
+	switch t {
+	case ipNetType:
+		return reflect.ValueOf(net.IPNet{IP: append(net.IP(nil), ip...), Mask: append(net.IPMask(nil), ipNet.Mask...)}), nil
+	case prefixType:
+		addr, _ := netip.AddrFromSlice(ip)
+		return reflect.ValueOf(netip.PrefixFrom(addr, ones)), nil
+	}
+
+	if ones != bits {
+		return reflect.Value{}, errors.Errorf("cannot scan network %s into %s", ipNet, t)
+	}
+	if t == ipType {
+		return reflect.ValueOf(append(net.IP(nil), ip...)), nil
+	}
+	addr, _ := netip.AddrFromSlice(ip)
+	return reflect.ValueOf(addr), nil
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, typ := range types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
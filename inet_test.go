@@ -0,0 +1,78 @@
+package pgxscan
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testInetEntity struct {
+	ID      string        `db:"id"`
+	IP      net.IP        `db:"ip"`
+	Network *net.IPNet    `db:"network"`
+	Addr    netip.Addr    `db:"addr"`
+	Prefix  *netip.Prefix `db:"prefix"`
+}
+
+func TestScanStructsInet(t *testing.T) {
+	conn := connect(t)
+
+	var result []testInetEntity
+	err := Select(context.Background(), conn, &result, `
+		SELECT * FROM (VALUES
+			('inet-1', '192.168.0.1'::inet, '10.0.0.0/8'::cidr, '::1'::inet, '2001:db8::/32'::cidr),
+			('inet-2', NULL::inet, NULL::cidr, '127.0.0.1'::inet, NULL::cidr)
+		) AS t (id, ip, network, addr, prefix)
+		ORDER BY id ASC
+	`)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, "192.168.0.1", result[0].IP.String())
+	require.NotNil(t, result[0].Network)
+	assert.Equal(t, "10.0.0.0/8", result[0].Network.String())
+	assert.Equal(t, netip.MustParseAddr("::1"), result[0].Addr)
+	require.NotNil(t, result[0].Prefix)
+	assert.Equal(t, netip.MustParsePrefix("2001:db8::/32"), *result[0].Prefix)
+
+	assert.Nil(t, result[1].IP)
+	assert.Nil(t, result[1].Network)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), result[1].Addr)
+	assert.Nil(t, result[1].Prefix)
+
+	// test some fail cases
+	var network testInetEntity
+	err = Get(context.Background(), conn, &network, `SELECT 'inet-3' AS id, '10.0.0.0/8'::cidr AS ip, NULL::cidr AS network, '::1'::inet AS addr, NULL::cidr AS prefix`)
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "ip" (oid 650) of row 0 into field IP of type net.IP: cannot scan network 10.0.0.0/8 into net.IP`, err.Error())
+
+	var null testInetEntity
+	err = Get(context.Background(), conn, &null, `SELECT 'inet-4' AS id, NULL::inet AS ip, NULL::cidr AS network, NULL::inet AS addr, NULL::cidr AS prefix`)
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "addr" (oid 869) of row 0 into field Addr of type netip.Addr: cannot scan NULL into netip.Addr`, err.Error())
+}
+
+func TestScanStructInetText(t *testing.T) {
+	type testInetData struct {
+		ID        string       `db:"id"`
+		CreatedAt netip.Addr   `db:"created_at"`
+		SomeData  netip.Prefix `db:"some_data"`
+	}
+
+	rows := newFakeRows(1)
+	rows.fields[1].DataTypeOID = pgtype.InetOID
+	rows.fields[2].DataTypeOID = pgtype.CIDROID
+	rows.rows[0][1] = []byte("192.168.0.1")
+	rows.rows[0][2] = []byte("192.168.0.0/16")
+
+	var result testInetData
+	err := ScanStruct(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("192.168.0.1"), result.CreatedAt)
+	assert.Equal(t, netip.MustParsePrefix("192.168.0.0/16"), result.SomeData)
+}
@@ -0,0 +1,66 @@
+package pgxscan
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/pkg/errors"
+)
+
+// Interval is a Postgres interval. Months and days are kept apart from the time, as their
+// length varies, so intervals such as '1 month' don't fit a time.Duration.
+type Interval struct {
+	Months       int32
+	Days         int32
+	Microseconds int64
+}
+
+// Duration returns the interval as a time.Duration, failing if it has months or days.
+func (i Interval) Duration() (time.Duration, error) {
+	if i.Months != 0 || i.Days != 0 {
+		return 0, errors.Errorf("interval of %d months and %d days does not fit a time.Duration", i.Months, i.Days)
+	}
+	d := time.Duration(i.Microseconds) * time.Microsecond
+	if d/time.Microsecond != time.Duration(i.Microseconds) {
+		return 0, errors.Errorf("interval of %d microseconds overflows a time.Duration", i.Microseconds)
+	}
+	return d, nil
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	intervalType = reflect.TypeOf(Interval{})
+)
+
+// intervalTarget returns the scan destination of the field f and the conversion decoding it,
+// if f is a time.Duration or an Interval, or a pointer to either, and oid the interval type.
+// pgtype assigns intervals to time.Duration assuming 30 day months, which is lossy.
+func intervalTarget(f reflect.Value, oid uint32) (interface{}, func() error, bool) {
+	t := f.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if oid != pgtype.IntervalOID || (t != durationType && t != intervalType) {
+		return nil, nil, false
+	}
+
+	holder := new(pgtype.Interval)
+	return holder, func() error {
+		if holder.Status != pgtype.Present {
+			return setNull(f)
+		}
+
+		interval := Interval{Months: holder.Months, Days: holder.Days, Microseconds: holder.Microseconds}
+		value := reflect.ValueOf(interval)
+		if t == durationType {
+			d, err := interval.Duration()
+			if err != nil {
+				return err
+			}
+			value = reflect.ValueOf(d)
+		}
+		setValue(f, value)
+		return nil
+	}, true
+}
@@ -0,0 +1,69 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testIntervalEntity struct {
+	ID       string         `db:"id"`
+	Timeout  time.Duration  `db:"timeout"`
+	Grace    *time.Duration `db:"grace"`
+	Period   Interval       `db:"period"`
+	Extended *Interval      `db:"extended"`
+}
+
+func TestScanStructsInterval(t *testing.T) {
+	conn := connect(t)
+
+	var result []testIntervalEntity
+	err := Select(context.Background(), conn, &result, `
+		SELECT * FROM (VALUES
+			('interval-1', '1 hour 30 minutes'::interval, '0.5 seconds'::interval, '1 month 2 days 3 hours'::interval, '1 year'::interval),
+			('interval-2', '-2 seconds'::interval, NULL::interval, '0'::interval, NULL::interval)
+		) AS t (id, timeout, grace, period, extended)
+		ORDER BY id ASC
+	`)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, 90*time.Minute, result[0].Timeout)
+	require.NotNil(t, result[0].Grace)
+	assert.Equal(t, 500*time.Millisecond, *result[0].Grace)
+	assert.Equal(t, Interval{Months: 1, Days: 2, Microseconds: int64(3 * time.Hour / time.Microsecond)}, result[0].Period)
+	assert.Equal(t, &Interval{Months: 12}, result[0].Extended)
+
+	assert.Equal(t, -2*time.Second, result[1].Timeout)
+	assert.Nil(t, result[1].Grace)
+	assert.Equal(t, Interval{}, result[1].Period)
+	assert.Nil(t, result[1].Extended)
+
+	// test some fail cases
+	var lossy testIntervalEntity
+	err = Get(context.Background(), conn, &lossy, `SELECT 'interval-3' AS id, '1 day'::interval AS timeout, NULL::interval AS grace, '0'::interval AS period, NULL::interval AS extended`)
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "timeout" (oid 1186) of row 0 into field Timeout of type time.Duration: interval of 0 months and 1 days does not fit a time.Duration`, err.Error())
+
+	var null testIntervalEntity
+	err = Get(context.Background(), conn, &null, `SELECT 'interval-4' AS id, NULL::interval AS timeout, NULL::interval AS grace, '0'::interval AS period, NULL::interval AS extended`)
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "timeout" (oid 1186) of row 0 into field Timeout of type time.Duration: cannot scan NULL into time.Duration`, err.Error())
+}
+
+func TestIntervalDuration(t *testing.T) {
+	d, err := Interval{Microseconds: 1500}.Duration()
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Microsecond, d)
+
+	// test some fail cases
+	_, err = Interval{Months: 1}.Duration()
+	require.Error(t, err)
+
+	_, err = Interval{Microseconds: 1 << 62}.Duration()
+	require.Error(t, err)
+	assert.Equal(t, "interval of 4611686018427387904 microseconds overflows a time.Duration", err.Error())
+}
@@ -0,0 +1,39 @@
+package pgxscan
+
+import (
+	"context"
+	"iter"
+
+	"github.com/pkg/errors"
+)
+
+// Iter scans the rows of the query result one by one into new Ts, T being a struct type, and
+// yields them with a nil error, so they can be ranged over. A failing query or scan yields the
+// zero T with the error and ends the iteration. Rows are closed once the iteration ends,
+// including when the loop breaks early.
+func Iter[T any](ctx context.Context, querier Querier, query string, args ...interface{}) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		rows, err := defaultScanner.query(ctx, querier, query, args)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		var dest T
+		err = ScanEach(rows, &dest, func() error {
+			if !yield(dest, nil) {
+				return errStopIter
+			}
+			return nil
+		})
+		if err != nil && err != errStopIter {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// errStopIter stops the scan of an Iter whose loop broke early.
+var errStopIter = errors.New("iteration stopped")
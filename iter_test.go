@@ -0,0 +1,48 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIter(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	var result []testEntity
+	for row, err := range Iter[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID) {
+		require.NoError(t, err)
+		result = append(result, row)
+	}
+	require.Len(t, result, 2)
+	assert.Equal(t, e1.ID, result[0].ID)
+	assert.Equal(t, e2.ID, result[1].ID)
+
+	// breaking early closes the rows, so the connection can be used again
+	for row, err := range Iter[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID) {
+		require.NoError(t, err)
+		assert.Equal(t, e1.ID, row.ID)
+		break
+	}
+	_, err := GetAs[testEntity](context.Background(), conn, "SELECT * FROM structscan_test WHERE id = $1", e2.ID)
+	require.NoError(t, err)
+
+	// test some fail cases
+	var errs []error
+	for _, err := range Iter[testEntity](context.Background(), conn, "SELECT * FROM structscan_test_missing") {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	require.Error(t, errs[0])
+
+	errs = nil
+	for _, err := range Iter[testMissingField](context.Background(), conn, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	require.Error(t, errs[0])
+}
@@ -0,0 +1,103 @@
+package pgxscan
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// jsonTarget returns the destination of the field f tagged with the json option, as in
+// `db:"metadata,json"`: the column is scanned as bytes and unmarshaled into f with
+// encoding/json, whatever the type of f. NULL sets f to its zero value.
+//
+// The keys of the objects decoded into structs without json tags are matched to their fields
+// like columns, so the rows aggregated by json_agg, as in `json_agg(items.*) AS items`,
+// populate a []Item field tagged `db:"items,json"` using the db tags of Item. Structs with json
+// tags are unmarshaled by encoding/json alone.
+func (s *Scanner) jsonTarget(f reflect.Value) (interface{}, func() error) {
+	payload := new([]byte)
+	return payload, func() error {
+		if *payload == nil {
+			f.Set(reflect.Zero(f.Type()))
+			return nil
+		}
+
+		ptr := reflect.New(f.Type())
+		if err := s.unmarshalJSON(*payload, ptr.Elem()); err != nil {
+			return errors.Wrap(err, "failed to unmarshal json")
+		}
+		f.Set(ptr.Elem())
+		return nil
+	}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unmarshalJSON unmarshals data into the settable v, matching the keys of objects to the
+// fields of structs without json tags by their column names.
+func (s *Scanner) unmarshalJSON(data []byte, v reflect.Value) error {
+	if !s.hasColumnJSON(v.Type()) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := s.unmarshalJSON(data, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := s.unmarshalJSON(elem, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+		tm := s.mapper().TypeMap(v.Type())
+		for key, value := range object {
+			fi := tm.GetByPath(key)
+			if fi == nil {
+				continue
+			}
+			if err := s.unmarshalJSON(value, reflectx.FieldByIndexes(v, fi.Index)); err != nil {
+				return errors.Wrapf(err, "failed to unmarshal %q", key)
+			}
+		}
+	}
+	return nil
+}
+
+// hasColumnJSON reports whether t is a struct without json tags, or a pointer or slice of one,
+// which json objects are unmarshaled into by column names.
+func (s *Scanner) hasColumnJSON(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		return s.hasColumnJSON(t.Elem())
+	}
+	if t.Kind() != reflect.Struct || isColumnType(t) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("json"); ok {
+			return false
+		}
+	}
+	return true
+}
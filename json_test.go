@@ -0,0 +1,185 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMetadata struct {
+	Source string   `json:"source"`
+	Tags   []string `json:"tags"`
+}
+
+type testJSONTagEntity struct {
+	ID       string                 `db:"id"`
+	Metadata testMetadata           `db:"metadata,json"`
+	Extra    map[string]interface{} `db:"extra,json"`
+	Scores   []int                  `db:"scores,json"`
+	Optional *testMetadata          `db:"optional,json"`
+}
+
+func TestScanStructsJSONTag(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "json_tag_test", `
+		id       text PRIMARY KEY,
+		metadata jsonb,
+		extra    json,
+		scores   text,
+		optional jsonb
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO json_tag_test (id, metadata, extra, scores, optional) VALUES
+			('json-1', '{"source": "api", "tags": ["a", "b"]}', '{"n": 1}', '[1, 2, 3]', '{"source": "cli"}'),
+			('json-2', NULL, NULL, NULL, NULL)
+	`)
+	require.NoError(t, err)
+
+	var result []testJSONTagEntity
+	err = Select(context.Background(), conn, &result, "SELECT * FROM json_tag_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, testMetadata{Source: "api", Tags: []string{"a", "b"}}, result[0].Metadata)
+	assert.Equal(t, map[string]interface{}{"n": float64(1)}, result[0].Extra)
+	assert.Equal(t, []int{1, 2, 3}, result[0].Scores)
+	assert.Equal(t, &testMetadata{Source: "cli"}, result[0].Optional)
+
+	assert.Equal(t, testMetadata{}, result[1].Metadata)
+	assert.Nil(t, result[1].Extra)
+	assert.Nil(t, result[1].Scores)
+	assert.Nil(t, result[1].Optional)
+
+	// test some fail cases
+	rows, err := conn.Query(context.Background(), `SELECT 'json-3' AS id, NULL AS metadata, NULL AS extra, 'not json' AS scores, NULL AS optional`)
+	require.NoError(t, err)
+	err = ScanStruct(rows, new(testJSONTagEntity))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to scan column "scores" (oid 25) of row 0 into field Scores of type []int: failed to unmarshal json`)
+}
+
+type testJSONAggItem struct {
+	ID        string     `db:"id"`
+	OrderID   string     `db:"order_id"`
+	CreatedAt time.Time  `db:"created_at"`
+	Note      *string    `db:"note"`
+	Ignored   string     `db:"-"`
+	Shipped   *time.Time `db:"shipped_at"`
+}
+
+type testJSONAggOrder struct {
+	ID       string             `db:"id"`
+	Items    []testJSONAggItem  `db:"items,json"`
+	ItemPtrs []*testJSONAggItem `db:"item_ptrs,json"`
+}
+
+func TestScanStructsJSONAgg(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "json_agg_orders", `
+		id text PRIMARY KEY
+	`)
+	createTable(t, conn, "json_agg_items", `
+		id         text PRIMARY KEY,
+		order_id   text NOT NULL,
+		created_at timestamptz NOT NULL,
+		note       text,
+		shipped_at timestamptz
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO json_agg_orders (id) VALUES ('order-1'), ('order-2');
+		INSERT INTO json_agg_items (id, order_id, created_at, note, shipped_at) VALUES
+			('item-1', 'order-1', '2020-01-02T03:04:05Z', 'fragile', '2020-01-03T00:00:00Z'),
+			('item-2', 'order-1', '2020-01-02T03:04:06Z', NULL, NULL)
+	`)
+	require.NoError(t, err)
+
+	var result []testJSONAggOrder
+	err = Select(context.Background(), conn, &result, `
+		SELECT
+			o.id,
+			json_agg(i.* ORDER BY i.id) FILTER (WHERE i.id IS NOT NULL) AS items,
+			COALESCE(json_agg(i.* ORDER BY i.id) FILTER (WHERE i.id IS NOT NULL), '[]') AS item_ptrs
+		FROM json_agg_orders o
+		LEFT JOIN json_agg_items i ON i.order_id = o.id
+		GROUP BY o.id
+		ORDER BY o.id ASC
+	`)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	note := "fragile"
+	shippedAt := time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)
+	expected := []testJSONAggItem{
+		{
+			ID:        "item-1",
+			OrderID:   "order-1",
+			CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			Note:      &note,
+			Shipped:   &shippedAt,
+		},
+		{
+			ID:        "item-2",
+			OrderID:   "order-1",
+			CreatedAt: time.Date(2020, 1, 2, 3, 4, 6, 0, time.UTC),
+		},
+	}
+	assert.Equal(t, "order-1", result[0].ID)
+	require.Len(t, result[0].Items, 2)
+	for i, item := range result[0].Items {
+		assert.Equal(t, expected[i].ID, item.ID)
+		assert.Equal(t, expected[i].OrderID, item.OrderID)
+		assert.True(t, expected[i].CreatedAt.Equal(item.CreatedAt))
+		assert.Equal(t, expected[i].Note, item.Note)
+		if expected[i].Shipped == nil {
+			assert.Nil(t, item.Shipped)
+		} else {
+			require.NotNil(t, item.Shipped)
+			assert.True(t, expected[i].Shipped.Equal(*item.Shipped))
+		}
+	}
+	require.Len(t, result[0].ItemPtrs, 2)
+	assert.Equal(t, "item-1", result[0].ItemPtrs[0].ID)
+	assert.Equal(t, "item-2", result[0].ItemPtrs[1].ID)
+
+	assert.Equal(t, "order-2", result[1].ID)
+	assert.Nil(t, result[1].Items)
+	assert.NotNil(t, result[1].ItemPtrs)
+	assert.Empty(t, result[1].ItemPtrs)
+
+	// test some fail cases
+	rows, err := conn.Query(context.Background(), `SELECT 'order-3' AS id, '[{"id": 1}]'::json AS items, NULL AS item_ptrs`)
+	require.NoError(t, err)
+	err = ScanStruct(rows, new(testJSONAggOrder))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to scan column "items" (oid 114) of row 0 into field Items of type []pgxscan.testJSONAggItem: failed to unmarshal json: failed to unmarshal "id"`)
+}
+
+func TestScanStructsJSONMatching(t *testing.T) {
+	type byColumn struct {
+		UserName string `db:"user_name"`
+	}
+	type byTag struct {
+		UserName string `json:"userName"`
+	}
+	var result struct {
+		ID       string   `db:"id"`
+		ByColumn byColumn `db:"created_at,json"`
+		ByTag    byTag    `db:"some_data,json"`
+	}
+
+	rows := newFakeRows(1)
+	rows.fields[1].DataTypeOID = pgtype.JSONOID
+	rows.fields[2].DataTypeOID = pgtype.JSONOID
+	rows.rows[0][1] = []byte(`{"user_name": "by column", "userName": "ignored"}`)
+	rows.rows[0][2] = []byte(`{"user_name": "ignored", "userName": "by tag"}`)
+	err := ScanStruct(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, "by column", result.ByColumn.UserName)
+	assert.Equal(t, "by tag", result.ByTag.UserName)
+}
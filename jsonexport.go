@@ -0,0 +1,119 @@
+package pgxscan
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// WriteJSON writes the rows of r to w as a JSON array of objects keyed by column name, in column
+// order, row by row, so that a result can be proxied without intermediate structs.
+//
+// json and jsonb values are written as is, and NULL as null. The values of the types pgtype
+// encodes to JSON, such as booleans, integers, text, dates and timestamptz, are written as pgtype
+// encodes them, floats and numerics as numbers, and other values as the string of their text
+// representation in Postgres. Function call closes rows, so caller may skip it.
+func WriteJSON(r pgx.Rows, w io.Writer) error {
+	defer r.Close()
+
+	fields := r.FieldDescriptions()
+	keys := make([][]byte, len(fields))
+	for i, fieldDescription := range fields {
+		key, err := json.Marshal(string(fieldDescription.Name))
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	raws := make([]interface{}, len(fields))
+	for i := range raws {
+		raws[i] = new(rawValue)
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	for row := 0; r.Next(); row++ {
+		if err := r.Scan(raws...); err != nil {
+			return err
+		}
+
+		if row > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteByte('{')
+		for i, raw := range raws {
+			value, err := jsonValue(raw.(*rawValue), fields[i].DataTypeOID)
+			if err != nil {
+				return errors.Wrapf(err, "failed to encode column %q", fields[i].Name)
+			}
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			bw.Write(keys[i])
+			bw.WriteByte(':')
+			bw.Write(value)
+		}
+		bw.WriteByte('}')
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	bw.WriteByte(']')
+
+	return bw.Flush()
+}
+
+// jsonValue returns the JSON encoding of raw, a value of type oid.
+func jsonValue(raw *rawValue, oid uint32) ([]byte, error) {
+	if raw.src == nil {
+		return []byte("null"), nil
+	}
+
+	switch oid {
+	case pgtype.JSONOID:
+		var value pgtype.JSON
+		if err := raw.scan(oid, &value); err != nil {
+			return nil, err
+		}
+		return value.Bytes, nil
+	case pgtype.JSONBOID:
+		var value pgtype.JSONB
+		if err := raw.scan(oid, &value); err != nil {
+			return nil, err
+		}
+		return value.Bytes, nil
+	}
+
+	if dt, ok := raw.ci.DataTypeForOID(oid); ok {
+		value := pgtype.NewValue(dt.Value)
+		if err := raw.scan(oid, value); err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case json.Marshaler:
+			return v.MarshalJSON()
+		case *pgtype.Float4, *pgtype.Float8, *pgtype.Numeric:
+			text, _, err := textValue(raw, oid, "")
+			if err != nil {
+				return nil, err
+			}
+			// NaN and infinities are not JSON numbers
+			if _, err := strconv.ParseFloat(text, 64); err == nil && text != "NaN" && text != "Infinity" && text != "-Infinity" {
+				return []byte(text), nil
+			}
+			return json.Marshal(text)
+		}
+	}
+
+	text, _, err := textValue(raw, oid, "")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(text)
+}
@@ -0,0 +1,65 @@
+package pgxscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	conn := connect(t)
+
+	rows, err := conn.Query(context.Background(), `
+		SELECT * FROM (VALUES
+			(1, 'foo', true, 1.5::float8, 12.5::numeric, '{"a": [1, 2]}'::jsonb, '[true]'::json, '{1,2}'::int[], '2020-01-02'::date),
+			(2, NULL, false, 'NaN'::float8, NULL, NULL, NULL, NULL, NULL)
+		) AS t (id, name, active, score, price, metadata, flags, tags, day)
+	`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = WriteJSON(rows, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, `[`+
+		`{"id":1,"name":"foo","active":true,"score":1.5,"price":12.5,"metadata":{"a": [1, 2]},"flags":[true],"tags":"{1,2}","day":"2020-01-02"},`+
+		`{"id":2,"name":null,"active":false,"score":"NaN","price":null,"metadata":null,"flags":null,"tags":null,"day":null}`+
+		`]`, buf.String())
+	assert.True(t, json.Valid(buf.Bytes()))
+
+	// no rows
+	rows, err = conn.Query(context.Background(), "SELECT 1 AS id WHERE false")
+	require.NoError(t, err)
+
+	buf.Reset()
+	err = WriteJSON(rows, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, buf.String())
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), "SELECT 1/0 AS id")
+	require.NoError(t, err)
+	err = WriteJSON(rows, &buf)
+	require.Error(t, err)
+}
+
+func TestWriteJSONFakeRows(t *testing.T) {
+	rows := newFakeRows(2)
+	rows.rows[1][2] = nil
+
+	var buf bytes.Buffer
+	err := WriteJSON(rows, &buf)
+	require.NoError(t, err)
+	assert.True(t, json.Valid(buf.Bytes()))
+
+	var result []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Len(t, result, 2)
+	assert.Equal(t, "bench-0", result[0]["id"])
+	assert.Equal(t, "foo bar baz", result[0]["some_data"])
+	assert.NotEmpty(t, result[0]["created_at"])
+	assert.Nil(t, result[1]["some_data"])
+}
@@ -0,0 +1,48 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// Listen listens to channel on conn and calls fn with the payload of each notification sent to
+// it, decoded from json into a new T, such as the row_to_json(NEW) of a trigger. The keys of the
+// objects decoded into structs without json tags are matched to their fields like columns.
+//
+// Listen blocks until ctx is done, returning its error, or until fn fails, returning its error as
+// is. conn is dedicated to it meanwhile, and stops listening to channel when it returns.
+func Listen[T any](ctx context.Context, conn *pgx.Conn, channel string, fn func(payload T) error) (err error) {
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return errors.Wrapf(err, "failed to listen to channel %q", channel)
+	}
+	defer func() {
+		// ctx may be done already
+		if _, unlistenErr := conn.Exec(context.Background(), "UNLISTEN "+pgx.Identifier{channel}.Sanitize()); unlistenErr != nil && err == nil {
+			err = errors.Wrapf(unlistenErr, "failed to unlisten channel %q", channel)
+		}
+	}()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return err
+		}
+		if notification.Channel != channel {
+			continue
+		}
+
+		var payload T
+		if err := defaultScanner.unmarshalJSON([]byte(notification.Payload), reflect.ValueOf(&payload).Elem()); err != nil {
+			return errors.Wrapf(err, "failed to decode notification on channel %q", channel)
+		}
+		if err := fn(payload); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testListenEvent struct {
+	ID        string    `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	SomeData  string    `db:"some_data"`
+}
+
+func TestListen(t *testing.T) {
+	conn := connect(t)
+	notifier := connect(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	var events []testListenEvent
+	go func() {
+		done <- Listen(ctx, conn, "listen_test", func(event testListenEvent) error {
+			events = append(events, event)
+			if len(events) == 2 {
+				return errStopListening
+			}
+			return nil
+		})
+	}()
+
+	// wait for the listener before notifying
+	require.Eventually(t, func() bool {
+		var listening bool
+		err := notifier.QueryRow(context.Background(), `
+			SELECT count(*) > 0 FROM pg_stat_activity WHERE query LIKE 'LISTEN %listen_test%'
+		`).Scan(&listening)
+		return err == nil && listening
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err := notifier.Exec(context.Background(), `
+		SELECT pg_notify('other_channel', '{}');
+		SELECT pg_notify('listen_test', json_build_object('id', 'event-1', 'created_at', '2020-01-01T00:00:00Z', 'some_data', 'foo')::text);
+		SELECT pg_notify('listen_test', json_build_object('id', 'event-2', 'created_at', '2020-01-02T00:00:00Z', 'some_data', 'bar')::text)
+	`)
+	require.NoError(t, err)
+
+	err = <-done
+	assert.True(t, errors.Is(err, errStopListening))
+	require.Len(t, events, 2)
+	assert.Equal(t, "event-1", events[0].ID)
+	assert.True(t, events[0].CreatedAt.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "foo", events[0].SomeData)
+	assert.Equal(t, "event-2", events[1].ID)
+
+	// test some fail cases
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	err = Listen(canceled, conn, "listen_test", func(testListenEvent) error { return nil })
+	require.Error(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		done <- Listen(ctx, conn, "listen_test", func(testListenEvent) error { return nil })
+	}()
+	require.Eventually(t, func() bool {
+		_, err := notifier.Exec(context.Background(), `SELECT pg_notify('listen_test', 'not json')`)
+		require.NoError(t, err)
+		select {
+		case err = <-done:
+			assert.Contains(t, err.Error(), `failed to decode notification on channel "listen_test"`)
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+var errStopListening = errors.New("stop listening")
@@ -0,0 +1,95 @@
+package pgxscan
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger logs the queries run through the Querier returned by Logging.
+type Logger interface {
+	// BeforeQuery is called when a query starts, the event having no outcome yet.
+	BeforeQuery(ctx context.Context, event QueryEvent)
+	// AfterQuery is called once done with a query, see QueryEvent.
+	AfterQuery(ctx context.Context, event QueryEvent)
+}
+
+// LoggingOption configures Logging.
+type LoggingOption func(*loggingConfig)
+
+type loggingConfig struct {
+	redact func(i int, arg interface{}) interface{}
+}
+
+// RedactArgs replaces the arguments of queries with the result of fn before they are logged,
+// fn being called with their index, from 0, so that secrets don't land in logs. Queries still
+// run with the original arguments.
+func RedactArgs(fn func(i int, arg interface{}) interface{}) LoggingOption {
+	return func(c *loggingConfig) {
+		c.redact = fn
+	}
+}
+
+// RedactAllArgs replaces all the arguments of queries with "[REDACTED]" before they are logged.
+func RedactAllArgs() LoggingOption {
+	return RedactArgs(func(int, interface{}) interface{} { return "[REDACTED]" })
+}
+
+// Logging returns a Middleware calling logger before and after each query.
+func Logging(logger Logger, opts ...LoggingOption) Middleware {
+	var c loggingConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	redact := func(event QueryEvent) QueryEvent {
+		if c.redact == nil || len(event.Args) == 0 {
+			return event
+		}
+		args := make([]interface{}, len(event.Args))
+		for i, arg := range event.Args {
+			args[i] = c.redact(i, arg)
+		}
+		event.Args = args
+		return event
+	}
+
+	return func(q Querier) Querier {
+		return &observedQuerier{
+			inner: q,
+			before: func(ctx context.Context, event QueryEvent) context.Context {
+				logger.BeforeQuery(ctx, redact(event))
+				return ctx
+			},
+			observe: func(ctx context.Context, event QueryEvent) {
+				logger.AfterQuery(ctx, redact(event))
+			},
+		}
+	}
+}
+
+// SlogLogger returns a Logger logging queries once done with to l, at the debug level,
+// or at the error level for failed queries.
+func SlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l: l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (l slogLogger) BeforeQuery(ctx context.Context, event QueryEvent) {}
+
+func (l slogLogger) AfterQuery(ctx context.Context, event QueryEvent) {
+	attrs := []slog.Attr{
+		slog.String("method", event.Method),
+		slog.String("query", event.Query),
+		slog.Any("args", event.Args),
+		slog.Duration("duration", event.Duration),
+		slog.Int64("rows", event.Rows),
+	}
+	if event.Err != nil {
+		l.l.LogAttrs(ctx, slog.LevelError, "query failed", append(attrs, slog.Any("error", event.Err))...)
+		return
+	}
+	l.l.LogAttrs(ctx, slog.LevelDebug, "query", attrs...)
+}
@@ -0,0 +1,61 @@
+package pgxscan
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLogger struct {
+	before []QueryEvent
+	after  []QueryEvent
+}
+
+func (l *recordingLogger) BeforeQuery(ctx context.Context, event QueryEvent) {
+	l.before = append(l.before, event)
+}
+
+func (l *recordingLogger) AfterQuery(ctx context.Context, event QueryEvent) {
+	l.after = append(l.after, event)
+}
+
+func TestLogging(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	logger := &recordingLogger{}
+	q := Wrap(conn, Logging(logger, RedactArgs(func(i int, arg interface{}) interface{} {
+		if i == 1 {
+			return "***"
+		}
+		return arg
+	})))
+
+	var result testEntity
+	err := Get(context.Background(), q, &result, "SELECT * FROM structscan_test WHERE id = $1 AND some_data = $2", e1.ID, e1.SomeData)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+
+	require.Len(t, logger.before, 1)
+	assert.Equal(t, []interface{}{e1.ID, "***"}, logger.before[0].Args)
+	assert.Zero(t, logger.before[0].Duration)
+
+	require.Len(t, logger.after, 1)
+	assert.Equal(t, []interface{}{e1.ID, "***"}, logger.after[0].Args)
+	assert.Equal(t, int64(1), logger.after[0].Rows)
+	assert.NoError(t, logger.after[0].Err)
+
+	// test some fail cases
+	var buf bytes.Buffer
+	q = Wrap(conn, Logging(SlogLogger(slog.New(slog.NewTextHandler(&buf, nil))), RedactAllArgs()))
+	err = Get(context.Background(), q, &result, "SELECT * FROM structscan_test_missing WHERE id = $1", "secret")
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "level=ERROR msg=\"query failed\" method=Query")
+	assert.Contains(t, buf.String(), "[REDACTED]")
+	assert.NotContains(t, buf.String(), "secret")
+}
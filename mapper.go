@@ -0,0 +1,170 @@
+package pgxscan
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// structMapper maps the fields of struct types to column names, as reflectx.Mapper does.
+type structMapper interface {
+	TypeMap(t reflect.Type) *reflectx.StructMap
+	TraversalsByName(t reflect.Type, names []string) [][]int
+}
+
+// traversalsKey identifies the traversals of the columns of a result into a struct type.
+type traversalsKey struct {
+	t reflect.Type
+	// columns are the column names, joined by NUL bytes
+	columns string
+}
+
+// traversalsByName returns the traversals of the fields of t mapped to columns, see
+// reflectx.Mapper.TraversalsByName. They are cached by type and columns, and must not be modified.
+func (s *Scanner) traversalsByName(t reflect.Type, columns []string) [][]int {
+	key := traversalsKey{t: t, columns: strings.Join(columns, "\x00")}
+	if traversals, ok := s.traversals.Load(key); ok {
+		return traversals.([][]int)
+	}
+
+	traversals := s.mapper().TraversalsByName(t, columns)
+	s.traversals.Store(key, traversals)
+	return traversals
+}
+
+// tagChainMapper names the fields after the first of its tags they have. Its reflectx.Mapper
+// reads the first tag and maps the names of fields without any of the tags.
+type tagChainMapper struct {
+	mapper *reflectx.Mapper
+	tags   []string
+
+	// mu guards renamed, and the struct maps of mapper while they are renamed.
+	mu      sync.Mutex
+	renamed map[reflect.Type]bool
+}
+
+func newTagChainMapper(tags []string, nameFunc func(string) string) *tagChainMapper {
+	return &tagChainMapper{
+		mapper:  reflectx.NewMapperFunc(tags[0], nameFunc),
+		tags:    tags,
+		renamed: make(map[reflect.Type]bool),
+	}
+}
+
+func (m *tagChainMapper) TypeMap(t reflect.Type) *reflectx.StructMap {
+	t = reflectx.Deref(t)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tm := m.mapper.TypeMap(t)
+	if !m.renamed[t] {
+		m.rename(tm)
+		m.renamed[t] = true
+	}
+	return tm
+}
+
+func (m *tagChainMapper) TraversalsByName(t reflect.Type, names []string) [][]int {
+	tm := m.TypeMap(t)
+
+	traversals := make([][]int, len(names))
+	for i, name := range names {
+		if fi, ok := tm.Names[name]; ok {
+			traversals[i] = fi.Index
+		}
+	}
+	return traversals
+}
+
+// rename names the fields of tm without the first tag after the next tag they have, and
+// rebuilds the field paths accordingly. Fields whose tag is "-" are removed, with their children.
+func (m *tagChainMapper) rename(tm *reflectx.StructMap) {
+	removed := make(map[*reflectx.FieldInfo]bool)
+
+	var walk func(parent *reflectx.FieldInfo, prefix string)
+	walk = func(parent *reflectx.FieldInfo, prefix string) {
+		for i, fi := range parent.Children {
+			if fi == nil {
+				continue
+			}
+
+			tagged := fi.Field.Tag.Get(m.tags[0]) != ""
+			if !tagged {
+				if tag, ok := m.fallbackTag(fi.Field); ok {
+					fi.Name, fi.Options = parseTag(tag)
+					tagged = true
+				}
+			}
+			if fi.Name == "-" {
+				parent.Children[i] = nil
+				removeTree(fi, removed)
+				continue
+			}
+
+			fi.Path = fi.Name
+			if prefix != "" {
+				fi.Path = prefix + "." + fi.Name
+			}
+
+			// untagged embedded structs don't prefix the paths of their fields
+			if fi.Embedded && !tagged {
+				walk(fi, prefix)
+			} else {
+				walk(fi, fi.Path)
+			}
+		}
+	}
+	walk(tm.Tree, "")
+
+	index := tm.Index[:0]
+	tm.Paths = make(map[string]*reflectx.FieldInfo)
+	tm.Names = make(map[string]*reflectx.FieldInfo)
+	for _, fi := range tm.Index {
+		if removed[fi] {
+			continue
+		}
+		index = append(index, fi)
+		tm.Paths[fi.Path] = fi
+		if fi.Name != "" && !fi.Embedded {
+			tm.Names[fi.Path] = fi
+		}
+	}
+	tm.Index = index
+}
+
+// fallbackTag returns the first tag of f after the first of the chain which names a column.
+func (m *tagChainMapper) fallbackTag(f reflect.StructField) (string, bool) {
+	for _, name := range m.tags[1:] {
+		tag := f.Tag.Get(name)
+		if tag != "" && !strings.HasPrefix(tag, ",") {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+func removeTree(fi *reflectx.FieldInfo, removed map[*reflectx.FieldInfo]bool) {
+	removed[fi] = true
+	for _, child := range fi.Children {
+		if child != nil {
+			removeTree(child, removed)
+		}
+	}
+}
+
+// parseTag splits a tag such as "name,opt,key=value" into the name and its options.
+func parseTag(tag string) (string, map[string]string) {
+	parts := strings.Split(tag, ",")
+	options := make(map[string]string, len(parts)-1)
+	for _, opt := range parts[1:] {
+		if k, v, ok := strings.Cut(opt, "="); ok {
+			options[k] = v
+			continue
+		}
+		options[opt] = ""
+	}
+	return parts[0], options
+}
@@ -0,0 +1,41 @@
+package pgxscan
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTagChainBase struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type testTagChainEntity struct {
+	testTagChainBase
+	ID       string `db:"id" json:"identifier"`
+	SomeData string `json:"some_data,omitempty"`
+	Secret   string `json:"-"`
+	Note     string `json:",omitempty"`
+	Other    string
+}
+
+func TestTagChainMapper(t *testing.T) {
+	m := newTagChainMapper([]string{"db", "json"}, strings.ToLower)
+	typ := reflect.TypeOf(testTagChainEntity{})
+
+	traversals := m.TraversalsByName(typ, []string{"id", "created_at", "some_data", "secret", "note", "other", "identifier"})
+	assert.Equal(t, [][]int{{1}, {0, 0}, {2}, nil, {4}, {5}, nil}, traversals)
+
+	// pointers map like the struct type
+	assert.Equal(t, traversals, m.TraversalsByName(reflect.PtrTo(typ), []string{"id", "created_at", "some_data", "secret", "note", "other", "identifier"}))
+
+	var paths []string
+	for _, fi := range m.TypeMap(typ).Index {
+		paths = append(paths, fi.Path)
+	}
+	assert.Equal(t, []string{"testtagchainbase", "id", "some_data", "note", "other", "created_at"}, paths)
+	assert.Contains(t, m.TypeMap(typ).Names["some_data"].Options, "omitempty")
+}
@@ -0,0 +1,132 @@
+package pgxscan
+
+import (
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// ScanMap scans the first row of r into dest, keyed by column name, for results without
+// a matching struct. Values are decoded the way pgx.Rows.Values decodes them.
+//
+// If there are no rows pgx.ErrNoRows is returned. Function call closes rows, so caller may skip it.
+func ScanMap(r pgx.Rows, dest *map[string]interface{}) error {
+	defer r.Close()
+
+	if dest == nil {
+		return errors.New("dest is nil pointer")
+	}
+
+	if !r.Next() {
+		if err := r.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+
+	row, err := scanMap(r)
+	if err != nil {
+		return err
+	}
+	*dest = row
+
+	return nil
+}
+
+// ScanMaps scans every row of r into dest, see ScanMap. dest is empty if there are no rows.
+func ScanMaps(r pgx.Rows, dest *[]map[string]interface{}) error {
+	defer r.Close()
+
+	if dest == nil {
+		return errors.New("dest is nil pointer")
+	}
+
+	rows := []map[string]interface{}{}
+	for r.Next() {
+		row, err := scanMap(r)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	*dest = rows
+
+	return nil
+}
+
+// ScanValues returns the column names of r and the values of every row, in column order,
+// for callers such as table printers and exporters handling any result. Values are decoded
+// the way pgx.Rows.Values decodes them. values is empty if there are no rows.
+func ScanValues(r pgx.Rows) (columns []string, values [][]interface{}, err error) {
+	defer r.Close()
+
+	fields := r.FieldDescriptions()
+	columns = make([]string, len(fields))
+	for i, fieldDescription := range fields {
+		columns[i] = string(fieldDescription.Name)
+	}
+
+	values = [][]interface{}{}
+	for r.Next() {
+		row, err := r.Values()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to parse a row")
+		}
+		values = append(values, row)
+	}
+	if err := r.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return columns, values, nil
+}
+
+// ScanMatrix returns the column names of r and the text representation in Postgres of the values
+// of every row, in column order, for table renderers and admin UIs only needing strings. NULL is an
+// empty string, as psql shows it. cells is empty if there are no rows.
+func ScanMatrix(r pgx.Rows) (headers []string, cells [][]string, err error) {
+	defer r.Close()
+
+	fields := r.FieldDescriptions()
+	headers = make([]string, len(fields))
+	raws := make([]interface{}, len(fields))
+	for i, fieldDescription := range fields {
+		headers[i] = string(fieldDescription.Name)
+		raws[i] = new(rawValue)
+	}
+
+	cells = [][]string{}
+	for r.Next() {
+		if err := r.Scan(raws...); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to parse a row")
+		}
+		row := make([]string, len(fields))
+		for i, raw := range raws {
+			row[i], _, err = textValue(raw.(*rawValue), fields[i].DataTypeOID, "")
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to format column %q", fields[i].Name)
+			}
+		}
+		cells = append(cells, row)
+	}
+	if err := r.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return headers, cells, nil
+}
+
+func scanMap(r pgx.Rows) (map[string]interface{}, error) {
+	values, err := r.Values()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse a row")
+	}
+
+	row := make(map[string]interface{}, len(values))
+	for i, fieldDescription := range r.FieldDescriptions() {
+		row[string(fieldDescription.Name)] = values[i]
+	}
+	return row, nil
+}
@@ -0,0 +1,133 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanMap(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	rows, err := conn.Query(context.Background(), "SELECT id, some_data, 42 AS answer, NULL::text AS nothing FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	err = ScanMap(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":        e1.ID,
+		"some_data": e1.SomeData,
+		"answer":    int32(42),
+		"nothing":   nil,
+	}, result)
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.NoError(t, err)
+
+	err = ScanMap(rows, &result)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+}
+
+func TestScanMaps(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	var result []map[string]interface{}
+	err := ScanMaps(selectRows(t, conn, e1.ID, e2.ID), &result)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, e1.ID, result[0]["id"])
+	assert.Equal(t, e1.SomeData, result[0]["some_data"])
+	assert.Equal(t, e2.ID, result[1]["id"])
+	assert.Equal(t, e2.SomeData, result[1]["some_data"])
+	assert.Contains(t, result[1], "created_at")
+
+	rows, err := conn.Query(context.Background(), "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.NoError(t, err)
+
+	err = ScanMaps(rows, &result)
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+}
+
+func TestScanValues(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	columns, values, err := ScanValues(selectRows(t, conn, e1.ID, e2.ID))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "some_data", "created_at"}, columns)
+	require.Len(t, values, 2)
+	assert.Equal(t, e1.ID, values[0][0])
+	assert.Equal(t, e1.SomeData, values[0][1])
+	assert.Equal(t, e2.ID, values[1][0])
+
+	rows, err := conn.Query(context.Background(), "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.NoError(t, err)
+
+	columns, values, err = ScanValues(rows)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "some_data", "created_at"}, columns)
+	assert.NotNil(t, values)
+	assert.Empty(t, values)
+}
+
+func TestScanMatrix(t *testing.T) {
+	conn := connect(t)
+
+	rows, err := conn.Query(context.Background(), `
+		SELECT * FROM (VALUES
+			(1, 'foo', true, 12.5::numeric, '{1,2}'::int[]),
+			(2, NULL, false, NULL, NULL)
+		) AS t (id, name, active, price, tags)
+	`)
+	require.NoError(t, err)
+
+	headers, cells, err := ScanMatrix(rows)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name", "active", "price", "tags"}, headers)
+	assert.Equal(t, [][]string{
+		{"1", "foo", "t", "12.5", "{1,2}"},
+		{"2", "", "f", "", ""},
+	}, cells)
+
+	rows, err = conn.Query(context.Background(), "SELECT 1 AS id WHERE false")
+	require.NoError(t, err)
+
+	headers, cells, err = ScanMatrix(rows)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, headers)
+	assert.NotNil(t, cells)
+	assert.Empty(t, cells)
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), "SELECT 1/0 AS id")
+	require.NoError(t, err)
+	_, _, err = ScanMatrix(rows)
+	require.Error(t, err)
+}
+
+func TestScanMatrixFakeRows(t *testing.T) {
+	rows := newFakeRows(2)
+	rows.rows[1][2] = nil
+
+	headers, cells, err := ScanMatrix(rows)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "created_at", "some_data"}, headers)
+	assert.Equal(t, [][]string{
+		{"bench-0", "2020-01-01 00:00:00+00", "foo bar baz"},
+		{"bench-1", "2020-01-01 00:00:00+00", ""},
+	}, cells)
+}
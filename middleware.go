@@ -0,0 +1,20 @@
+package pgxscan
+
+// Middleware decorates a Querier, for instance to log, measure, retry or trace its queries.
+type Middleware func(Querier) Querier
+
+// Wrap returns q decorated by mws, the first of them being the outermost: Wrap(q, a, b)
+// runs the queries through a, then b, then q.
+func Wrap(q Querier, mws ...Middleware) Querier {
+	for i := len(mws) - 1; i >= 0; i-- {
+		q = mws[i](q)
+	}
+	return q
+}
+
+// Retry returns a Middleware retrying queries according to policy, see RetryQueryer.
+func Retry(policy RetryPolicy) Middleware {
+	return func(q Querier) Querier {
+		return RetryQueryer(q, policy)
+	}
+}
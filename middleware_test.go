@@ -0,0 +1,48 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingQuerier records the queries run through it under its name.
+type recordingQuerier struct {
+	Querier
+	name string
+	log  *[]string
+}
+
+func (q *recordingQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	*q.log = append(*q.log, q.name)
+	return q.Querier.Query(ctx, sql, args...)
+}
+
+func recording(name string, log *[]string) Middleware {
+	return func(q Querier) Querier {
+		return &recordingQuerier{Querier: q, name: name, log: log}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	var log []string
+	inner := &failingQuerier{Querier: conn, err: &pgconn.PgError{Code: "57P01"}, failures: 1}
+	q := Wrap(inner, recording("outer", &log), Retry(RetryPolicy{Backoff: time.Millisecond}), recording("inner", &log))
+
+	var result testEntity
+	err := Get(context.Background(), q, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+	assert.Equal(t, []string{"outer", "inner", "inner"}, log)
+
+	assert.Equal(t, Querier(conn), Wrap(conn))
+}
@@ -0,0 +1,184 @@
+package pgxscan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// SelectCursors runs the query returning refcursors and scans the result sets they point to
+// into dests, in order, see Scanner.SelectCursors.
+func SelectCursors(ctx context.Context, querier Querier, dests []interface{}, query string, args ...interface{}) error {
+	return defaultScanner.SelectCursors(ctx, querier, dests, query, args...)
+}
+
+// SelectCursors runs the query, typically calling a function returning refcursors, as columns
+// of its first row or as a SETOF refcursor, and fetches every cursor, in order, into the dest of
+// the same index. A dest is a pointer to a slice of structs, scanned like with ScanStructs, or
+// a pointer to a struct, scanned like with ScanStruct.
+//
+// Cursors only live in the transaction they are opened in, so querier must be a pgx.Tx.
+func (s *Scanner) SelectCursors(ctx context.Context, querier Querier, dests []interface{}, query string, args ...interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	rows, err := s.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	var cursors []string
+	for rows.Next() {
+		// the text and binary formats of a refcursor are both its name
+		for i, value := range rows.RawValues() {
+			if value == nil {
+				rows.Close()
+				return errors.Errorf("cursor column %q is NULL", rows.FieldDescriptions()[i].Name)
+			}
+			cursors = append(cursors, string(value))
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(cursors) != len(dests) {
+		return errors.Errorf("got %d cursors for %d dests", len(cursors), len(dests))
+	}
+	for i, cursor := range cursors {
+		rows, err := querier.Query(ctx, "FETCH ALL FROM "+pgx.Identifier{cursor}.Sanitize())
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch cursor %q", cursor)
+		}
+		if err := s.scanResult(ctx, rows, dests[i]); err != nil {
+			return errors.Wrapf(err, "failed to scan cursor %q", cursor)
+		}
+	}
+	return nil
+}
+
+// SelectMulti runs the statements of sql, separated by semicolons, and scans their result sets
+// into dests, in order, see Scanner.SelectMulti.
+func SelectMulti(ctx context.Context, conn *pgx.Conn, dests []interface{}, sql string) error {
+	return defaultScanner.SelectMulti(ctx, conn, dests, sql)
+}
+
+// SelectMulti runs the statements of sql, separated by semicolons, with the simple protocol, and
+// scans the result set of each into the dest of the same index, like SelectCursors. The simple
+// protocol takes no arguments, so sql must not embed untrusted input.
+//
+// Every statement must return rows, and the statements run in an implicit transaction unless sql
+// controls it.
+func (s *Scanner) SelectMulti(ctx context.Context, conn *pgx.Conn, dests []interface{}, sql string) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	mrr := conn.PgConn().Exec(ctx, sql)
+	results := 0
+	for ; mrr.NextResult(); results++ {
+		if results >= len(dests) {
+			mrr.Close()
+			return errors.Errorf("got more result sets than %d dests", len(dests))
+		}
+		rows := &resultRows{rr: mrr.ResultReader(), ci: conn.ConnInfo()}
+		if err := s.scanResult(ctx, rows, dests[results]); err != nil {
+			mrr.Close()
+			return errors.Wrapf(err, "failed to scan result set %d", results)
+		}
+	}
+	if err := mrr.Close(); err != nil {
+		return err
+	}
+	if results != len(dests) {
+		return errors.Errorf("got %d result sets for %d dests", results, len(dests))
+	}
+	return nil
+}
+
+// scanResult scans the rows of r into dest, a pointer to a slice of structs or to a struct, and
+// closes r, so the next result set or statement can be read.
+func (s *Scanner) scanResult(ctx context.Context, r pgx.Rows, dest interface{}) error {
+	defer r.Close()
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Slice {
+		return s.ScanStructsContext(ctx, r, dest)
+	}
+	return s.scanStruct(ctx, r, dest)
+}
+
+// resultRows adapts a result set read with the simple protocol to pgx.Rows.
+type resultRows struct {
+	rr     *pgconn.ResultReader
+	ci     *pgtype.ConnInfo
+	tag    pgconn.CommandTag
+	err    error
+	closed bool
+}
+
+func (r *resultRows) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	tag, err := r.rr.Close()
+	r.tag = tag
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *resultRows) Err() error                                     { return r.err }
+func (r *resultRows) CommandTag() pgconn.CommandTag                  { return r.tag }
+func (r *resultRows) FieldDescriptions() []pgproto3.FieldDescription { return r.rr.FieldDescriptions() }
+func (r *resultRows) RawValues() [][]byte                            { return r.rr.Values() }
+
+func (r *resultRows) Next() bool {
+	if r.closed {
+		return false
+	}
+	if r.rr.NextRow() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+func (r *resultRows) Scan(dest ...interface{}) error {
+	fieldDescriptions := r.FieldDescriptions()
+	values := r.RawValues()
+	if len(fieldDescriptions) != len(dest) {
+		return errors.Errorf("number of field descriptions must equal number of destinations, got %d and %d", len(fieldDescriptions), len(dest))
+	}
+
+	for i, d := range dest {
+		if d == nil {
+			continue
+		}
+		if err := r.ci.Scan(fieldDescriptions[i].DataTypeOID, fieldDescriptions[i].Format, values[i], d); err != nil {
+			// like pgx
+			return fmt.Errorf("can't scan into dest[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (r *resultRows) Values() ([]interface{}, error) {
+	values := make([]interface{}, len(r.FieldDescriptions()))
+	dest := make([]interface{}, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := r.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
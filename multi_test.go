@@ -0,0 +1,135 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectCursors(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "cursors_test", `
+		id   text PRIMARY KEY,
+		name text NOT NULL
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO cursors_test (id, name) VALUES ('author-1', 'Frank Herbert'), ('author-2', 'Jane Austen');
+
+		CREATE OR REPLACE FUNCTION cursors_test_fn() RETURNS SETOF refcursor AS $$
+		DECLARE
+			authors refcursor := 'authors';
+			first refcursor := 'first';
+		BEGIN
+			OPEN authors FOR SELECT id, name FROM cursors_test ORDER BY id ASC;
+			RETURN NEXT authors;
+			OPEN first FOR SELECT id, name FROM cursors_test ORDER BY id ASC LIMIT 1;
+			RETURN NEXT first;
+		END;
+		$$ LANGUAGE plpgsql
+	`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = conn.Exec(context.Background(), "DROP FUNCTION IF EXISTS cursors_test_fn()")
+	})
+
+	tx, err := conn.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback(context.Background())
+
+	var authors []testAuthor
+	var first testAuthor
+	err = SelectCursors(context.Background(), tx, []interface{}{&authors, &first}, "SELECT cursors_test_fn()")
+	require.NoError(t, err)
+	assert.Equal(t, []testAuthor{{ID: "author-1", Name: "Frank Herbert"}, {ID: "author-2", Name: "Jane Austen"}}, authors)
+	assert.Equal(t, testAuthor{ID: "author-1", Name: "Frank Herbert"}, first)
+
+	// the tx can still be used once the cursors are fetched
+	var count int
+	err = tx.QueryRow(context.Background(), "SELECT count(*) FROM cursors_test").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// struct dests before slice ones
+	_, err = tx.Exec(context.Background(), "CLOSE ALL")
+	require.NoError(t, err)
+	authors = nil
+	first = testAuthor{}
+	err = SelectCursors(context.Background(), tx, []interface{}{&first, &authors}, "SELECT c FROM cursors_test_fn() AS c ORDER BY c::text DESC")
+	require.NoError(t, err)
+	assert.Equal(t, testAuthor{ID: "author-1", Name: "Frank Herbert"}, first)
+	assert.Equal(t, []testAuthor{{ID: "author-1", Name: "Frank Herbert"}, {ID: "author-2", Name: "Jane Austen"}}, authors)
+
+	// test some fail cases
+	tx2, err := conn.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx2.Rollback(context.Background())
+
+	err = SelectCursors(context.Background(), tx2, []interface{}{&authors}, "SELECT cursors_test_fn()")
+	require.Error(t, err)
+	assert.Equal(t, "got 2 cursors for 1 dests", err.Error())
+
+	tx3, err := conn.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx3.Rollback(context.Background())
+
+	err = SelectCursors(context.Background(), tx3, []interface{}{&authors}, "SELECT NULL::refcursor AS cursor")
+	require.Error(t, err)
+	assert.Equal(t, `cursor column "cursor" is NULL`, err.Error())
+}
+
+func TestSelectMulti(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "multi_test", `
+		id   text PRIMARY KEY,
+		name text NOT NULL
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO multi_test (id, name) VALUES ('author-1', 'Frank Herbert'), ('author-2', 'Jane Austen')
+	`)
+	require.NoError(t, err)
+
+	var authors []*testAuthor
+	var count struct {
+		Count int `db:"count"`
+	}
+	err = SelectMulti(context.Background(), conn, []interface{}{&authors, &count}, `
+		SELECT id, name FROM multi_test ORDER BY id ASC;
+		SELECT count(*) FROM multi_test
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []*testAuthor{{ID: "author-1", Name: "Frank Herbert"}, {ID: "author-2", Name: "Jane Austen"}}, authors)
+	assert.Equal(t, 2, count.Count)
+
+	// struct dests before slice ones
+	authors = nil
+	count.Count = 0
+	err = SelectMulti(context.Background(), conn, []interface{}{&count, &authors}, `
+		SELECT count(*) FROM multi_test;
+		SELECT id, name FROM multi_test ORDER BY id ASC
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count.Count)
+	assert.Equal(t, []*testAuthor{{ID: "author-1", Name: "Frank Herbert"}, {ID: "author-2", Name: "Jane Austen"}}, authors)
+
+	// test some fail cases
+	err = SelectMulti(context.Background(), conn, []interface{}{&authors}, `
+		SELECT id, name FROM multi_test;
+		SELECT id, name FROM multi_test
+	`)
+	require.Error(t, err)
+	assert.Equal(t, "got more result sets than 1 dests", err.Error())
+
+	err = SelectMulti(context.Background(), conn, []interface{}{&authors}, `SELECT id, name FROM multi_test_missing`)
+	require.Error(t, err)
+
+	err = SelectMulti(context.Background(), conn, []interface{}{&authors, &count}, `SELECT id, name FROM multi_test`)
+	require.Error(t, err)
+	assert.Equal(t, "got 1 result sets for 2 dests", err.Error())
+
+	err = SelectMulti(context.Background(), conn, []interface{}{&count}, `SELECT 'foo' AS count`)
+	require.Error(t, err)
+}
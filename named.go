@@ -0,0 +1,199 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+)
+
+// GetNamed works like Get, binding the :name parameters of query from arg, see BindNamed.
+func GetNamed(ctx context.Context, querier Querier, dest interface{}, query string, arg interface{}) error {
+	return defaultScanner.GetNamed(ctx, querier, dest, query, arg)
+}
+
+// SelectNamed works like Select, binding the :name parameters of query from arg, see BindNamed.
+func SelectNamed(ctx context.Context, querier Querier, dest interface{}, query string, arg interface{}) error {
+	return defaultScanner.SelectNamed(ctx, querier, dest, query, arg)
+}
+
+// ExecNamed executes query, binding its :name parameters from arg, see BindNamed.
+func ExecNamed(ctx context.Context, querier Querier, query string, arg interface{}) (pgconn.CommandTag, error) {
+	return defaultScanner.ExecNamed(ctx, querier, query, arg)
+}
+
+// BindNamed rewrites the :name parameters of query into $1..$N placeholders, and returns
+// the matching arguments taken from arg, see Scanner.BindNamed.
+func BindNamed(query string, arg interface{}) (string, []interface{}, error) {
+	return defaultScanner.BindNamed(query, arg)
+}
+
+// GetNamed works like the package-level GetNamed, using the Scanner options.
+func (s *Scanner) GetNamed(ctx context.Context, querier Querier, dest interface{}, query string, arg interface{}) error {
+	query, args, err := s.BindNamed(query, arg)
+	if err != nil {
+		return err
+	}
+	return s.Get(ctx, querier, dest, query, args...)
+}
+
+// SelectNamed works like the package-level SelectNamed, using the Scanner options.
+func (s *Scanner) SelectNamed(ctx context.Context, querier Querier, dest interface{}, query string, arg interface{}) error {
+	query, args, err := s.BindNamed(query, arg)
+	if err != nil {
+		return err
+	}
+	return s.Select(ctx, querier, dest, query, args...)
+}
+
+// ExecNamed works like the package-level ExecNamed, using the Scanner options.
+func (s *Scanner) ExecNamed(ctx context.Context, querier Querier, query string, arg interface{}) (pgconn.CommandTag, error) {
+	query, args, err := s.BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return s.exec(ctx, querier, query, args)
+}
+
+// BindNamed rewrites the :name parameters of query into $1..$N placeholders, and returns
+// the matching arguments. arg is a map with string keys, or a struct (or a pointer to one)
+// whose fields are named the way the Scanner maps them to columns.
+//
+// A parameter used several times is bound once. Casts such as ::text, string literals,
+// quoted identifiers and comments are left untouched.
+func (s *Scanner) BindNamed(query string, arg interface{}) (string, []interface{}, error) {
+	query, names := parseNamed(query)
+
+	lookup, err := s.namedLookup(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		v, ok := lookup(name)
+		if !ok {
+			return "", nil, errors.Errorf("missing named argument %q", name)
+		}
+		args[i] = v
+	}
+	return query, args, nil
+}
+
+// namedLookup returns the function looking up the named arguments in arg.
+func (s *Scanner) namedLookup(arg interface{}) (func(name string) (interface{}, bool), error) {
+	v := reflect.Indirect(reflect.ValueOf(arg))
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		return func(name string) (interface{}, bool) {
+			value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				return nil, false
+			}
+			return value.Interface(), true
+		}, nil
+
+	case v.Kind() == reflect.Struct:
+		names := s.mapper().TypeMap(v.Type()).Names
+		return func(name string) (interface{}, bool) {
+			fi, ok := names[name]
+			if !ok {
+				return nil, false
+			}
+			if f, ok := fieldByIndexes(v, fi.Index); ok {
+				return f.Interface(), true
+			}
+			// a nil embedded pointer holds the field
+			return nil, true
+		}, nil
+	}
+
+	return nil, errors.Errorf("expected a map with string keys or a struct, got %T", arg)
+}
+
+// parseNamed replaces the :name parameters of query with $N placeholders, and returns
+// the parameter names in placeholder order.
+func parseNamed(query string) (string, []string) {
+	var (
+		b       strings.Builder
+		names   []string
+		indexes = make(map[string]int)
+	)
+
+	for i := 0; i < len(query); i++ {
+		if end := skipEnd(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+
+		switch c := query[i]; {
+		case strings.HasPrefix(query[i:], "::"):
+			b.WriteString("::")
+			i++
+
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && isNamePart(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+
+			index, ok := indexes[name]
+			if !ok {
+				names = append(names, name)
+				index = len(names)
+				indexes[name] = index
+			}
+			b.WriteString("$" + strconv.Itoa(index))
+			i = end - 1
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), names
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9' || c == '.'
+}
+
+// skipEnd returns the end of the string literal, quoted identifier or comment starting
+// at query[i], where parameters are not replaced, or i if there is none.
+func skipEnd(query string, i int) int {
+	var n int
+	switch {
+	case query[i] == '\'' || query[i] == '"':
+		// a doubled quote escaping a quote reads as two adjacent literals
+		n = strings.IndexByte(query[i+1:], query[i])
+		if n >= 0 {
+			n += 2
+		}
+	case strings.HasPrefix(query[i:], "--"):
+		n = strings.IndexByte(query[i:], '\n')
+		if n >= 0 {
+			n++
+		}
+	case strings.HasPrefix(query[i:], "/*"):
+		n = strings.Index(query[i:], "*/")
+		if n >= 0 {
+			n += 2
+		}
+	default:
+		return i
+	}
+
+	if n < 0 {
+		return len(query)
+	}
+	return i + n
+}
@@ -0,0 +1,70 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamed(t *testing.T) {
+	conn := connect(t)
+
+	e := testEntity{
+		ID:        "named-1-" + time.Now().String(),
+		CreatedAt: time.Now(),
+		SomeData:  "foo bar baz",
+	}
+
+	tag, err := ExecNamed(context.Background(), conn, "INSERT INTO structscan_test (id, some_data, created_at) VALUES (:id, :some_data, :created_at)", e)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tag.RowsAffected())
+
+	var result testEntity
+	err = GetNamed(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = :id", map[string]interface{}{"id": e.ID})
+	require.NoError(t, err)
+	assert.Equal(t, e.ID, result.ID)
+	assert.Equal(t, e.SomeData, result.SomeData)
+	// compare unit timestamp to avoid milliseconds diff
+	assert.Equal(t, e.CreatedAt.Unix(), result.CreatedAt.Unix())
+
+	var results []testEntity
+	err = SelectNamed(context.Background(), conn, &results, "SELECT * FROM structscan_test WHERE id = :id OR some_data = :id::text", &e)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, e.ID, results[0].ID)
+
+	// test some fail cases
+	err = GetNamed(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = :foo", e)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing named argument "foo"`)
+
+	err = GetNamed(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = :id", e.ID)
+	require.Error(t, err)
+}
+
+func TestParseNamed(t *testing.T) {
+	for query, expected := range map[string]struct {
+		query string
+		names []string
+	}{
+		"SELECT * FROM t WHERE a = :a AND b = :b OR a = :a": {
+			"SELECT * FROM t WHERE a = $1 AND b = $2 OR a = $1", []string{"a", "b"},
+		},
+		"SELECT :created_at::timestamptz, ':not_a_param', \":nor_this\"": {
+			"SELECT $1::timestamptz, ':not_a_param', \":nor_this\"", []string{"created_at"},
+		},
+		"SELECT 1 -- :comment\nFROM t /* :comment */ WHERE x = :x": {
+			"SELECT 1 -- :comment\nFROM t /* :comment */ WHERE x = $1", []string{"x"},
+		},
+		"SELECT arr[1:2] FROM t WHERE s = 'it''s :x'": {
+			"SELECT arr[1:2] FROM t WHERE s = 'it''s :x'", nil,
+		},
+	} {
+		rewritten, names := parseNamed(query)
+		assert.Equal(t, expected.query, rewritten, query)
+		assert.Equal(t, expected.names, names, query)
+	}
+}
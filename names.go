@@ -0,0 +1,32 @@
+package pgxscan
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SnakeCase converts a Go identifier to snake case, keeping acronyms together:
+// CreatedAt becomes created_at, UserID user_id and HTTPServer http_server.
+func SnakeCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	b.Grow(len(name) + 4)
+	for i, r := range runes {
+		if !unicode.IsUpper(r) {
+			b.WriteRune(r)
+			continue
+		}
+
+		if i > 0 && runes[i-1] != '_' {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
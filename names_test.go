@@ -0,0 +1,23 @@
+package pgxscan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"ID":         "id",
+		"Name":       "name",
+		"CreatedAt":  "created_at",
+		"UserID":     "user_id",
+		"HTTPServer": "http_server",
+		"Address2":   "address2",
+		"Version2ID": "version2_id",
+		"some_data":  "some_data",
+		"Some_Data":  "some_data",
+	} {
+		assert.Equal(t, expected, SnakeCase(name), name)
+	}
+}
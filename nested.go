@@ -0,0 +1,370 @@
+package pgxscan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgproto3/v2"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// SelectNested runs the query and folds its rows into parent structs with child slices,
+// see ScanNested.
+func SelectNested(ctx context.Context, querier Querier, dest interface{}, keys []string, query string, args ...interface{}) error {
+	return defaultScanner.SelectNested(ctx, querier, dest, keys, query, args...)
+}
+
+// SelectNested works like the package-level SelectNested, using the Scanner options.
+func (s *Scanner) SelectNested(ctx context.Context, querier Querier, dest interface{}, keys []string, query string, args ...interface{}) error {
+	rows, err := s.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	return s.scanNested(ctx, rows, dest, keys)
+}
+
+// ScanNested scans the rows of a one-to-many join into dest, a pointer to a slice of structs or
+// pointers to structs, folding the rows sharing the values of the key columns into one parent.
+// A column aliased as "orders.total" is scanned into the total field of a new element of the
+// slice field mapped to orders, such as Orders []Order `db:"orders"`, which is appended for
+// every row of the parent. Columns without the prefix of a slice field are scanned into the
+// parent once, from its first row.
+//
+// Parents are kept in the order of their first row. The children whose columns are all NULL,
+// as a LEFT JOIN without a match returns, are skipped. AfterScan is called on each child once
+// scanned, and on each parent once all of its rows are folded.
+//
+// Keys prefixed like the columns of a slice field, such as "tags.id", identify its elements
+// instead: a child is appended once per parent, and skipped if one of its keys is NULL. This
+// assembles many-to-many relations from a join through a link table, and several relations
+// joined at once, which repeat each child for every combination of rows of the others:
+//
+//	SELECT p.id, p.title, t.id AS "tags.id", t.name AS "tags.name"
+//	FROM posts p
+//	LEFT JOIN post_tags pt ON pt.post_id = p.id
+//	LEFT JOIN tags t ON t.id = pt.tag_id
+//
+// scanned with the keys "id" and "tags.id". Function call closes rows, so caller may skip it.
+func ScanNested(r pgx.Rows, dest interface{}, keys []string) error {
+	return defaultScanner.ScanNested(r, dest, keys)
+}
+
+// ScanNested works like the package-level ScanNested, using the Scanner options.
+func (s *Scanner) ScanNested(r pgx.Rows, dest interface{}, keys []string) error {
+	return s.scanNested(context.Background(), r, dest, keys)
+}
+
+func (s *Scanner) scanNested(ctx context.Context, r pgx.Rows, dest interface{}, keys []string) error {
+	defer r.Close()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.IsNil() || destVal.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("expected a pointer to a slice, got %T", dest)
+	}
+	sliceVal := destVal.Elem()
+	parentType := sliceVal.Type().Elem()
+	isPtr := parentType.Kind() == reflect.Ptr
+	if isPtr {
+		parentType = parentType.Elem()
+	}
+	if parentType.Kind() != reflect.Struct {
+		return errors.Errorf("expected a slice of structs, got %T", dest)
+	}
+	if len(keys) == 0 {
+		return errors.New("no key columns")
+	}
+
+	var (
+		groups  []*nestedGroup
+		raws    []interface{}
+		parents []reflect.Value
+		byKey   = make(map[string]reflect.Value)
+	)
+	for row := 0; r.Next(); row++ {
+		if groups == nil {
+			var err error
+			groups, err = s.nestedGroups(r.FieldDescriptions(), parentType, keys)
+			if err != nil {
+				return err
+			}
+			raws = make([]interface{}, len(r.FieldDescriptions()))
+			for i := range raws {
+				raws[i] = new(rawValue)
+			}
+		}
+
+		if err := r.Scan(raws...); err != nil {
+			return &ScanError{Row: row, Err: err}
+		}
+
+		key, null := nestedKey(raws, groups[0].keys)
+		if null >= 0 {
+			return errors.Errorf("key column %q is NULL", r.FieldDescriptions()[null].Name)
+		}
+		parent, ok := byKey[key]
+		if !ok {
+			parent = reflect.New(parentType)
+			if err := s.decodeNested(groups[0], row, parent, raws); err != nil {
+				return err
+			}
+			byKey[key] = parent
+			parents = append(parents, parent)
+		}
+
+		for _, g := range groups[1:] {
+			if len(g.keys) == 0 && allNull(raws, g.indexes) {
+				continue
+			}
+			if len(g.keys) > 0 {
+				childKey, null := nestedKey(raws, g.keys)
+				if null >= 0 || g.seen[key+childKey] {
+					continue
+				}
+				g.seen[key+childKey] = true
+			}
+			child := reflect.New(g.elemType)
+			if err := s.decodeNested(g, row, child, raws); err != nil {
+				return err
+			}
+			if err := afterScanHook(ctx, child); err != nil {
+				return err
+			}
+			children := reflectx.FieldByIndexes(parent, g.field)
+			if g.isPtr {
+				children.Set(reflect.Append(children, child))
+			} else {
+				children.Set(reflect.Append(children, child.Elem()))
+			}
+		}
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	result := reflect.MakeSlice(sliceVal.Type(), len(parents), len(parents))
+	for i, parent := range parents {
+		// once all of its rows are folded, for its hook to see its children
+		if err := afterScanHook(ctx, parent); err != nil {
+			return err
+		}
+		if isPtr {
+			result.Index(i).Set(parent)
+		} else {
+			result.Index(i).Set(parent.Elem())
+		}
+	}
+	sliceVal.Set(result)
+
+	return nil
+}
+
+// nestedGroup is a group of the columns of a nested scan, scanned into the parent struct,
+// or into the elements of the slice field of the parent traversed by field.
+type nestedGroup struct {
+	field      []int
+	elemType   reflect.Type
+	isPtr      bool
+	indexes    []int
+	columns    []string
+	oids       []uint32
+	traversals [][]int
+
+	// keys are the indexes of the key columns of the group, and seen the keys of the parent
+	// and the child of the children already appended.
+	keys []int
+	seen map[string]bool
+}
+
+// nestedGroups groups the columns described by fieldDescriptions by the struct they are scanned
+// into, the parent of type parentType first, and assigns them the key columns.
+func (s *Scanner) nestedGroups(fieldDescriptions []pgproto3.FieldDescription, parentType reflect.Type, keys []string) ([]*nestedGroup, error) {
+	tm := s.mapper().TypeMap(parentType)
+	groups := []*nestedGroup{{elemType: parentType}}
+	byPrefix := make(map[string]*nestedGroup)
+	found := make([]bool, len(keys))
+
+	for i, fieldDescription := range fieldDescriptions {
+		column := string(fieldDescription.Name)
+		g, name := groups[0], column
+		if prefix, rest, ok := strings.Cut(column, "."); ok {
+			if child, found := byPrefix[prefix]; found {
+				g, name = child, rest
+			} else if fi := tm.GetByPath(prefix); fi != nil && isStructSlice(fi.Field.Type) {
+				elemType := fi.Field.Type.Elem()
+				child = &nestedGroup{
+					field:    fi.Index,
+					elemType: reflectx.Deref(elemType),
+					isPtr:    elemType.Kind() == reflect.Ptr,
+					seen:     make(map[string]bool),
+				}
+				groups = append(groups, child)
+				byPrefix[prefix] = child
+				g, name = child, rest
+			}
+		}
+
+		g.indexes = append(g.indexes, i)
+		g.columns = append(g.columns, name)
+		g.oids = append(g.oids, fieldDescription.DataTypeOID)
+		for j, key := range keys {
+			if key == column && !found[j] {
+				found[j] = true
+				g.keys = append(g.keys, i)
+			}
+		}
+	}
+
+	for j, ok := range found {
+		if !ok {
+			return nil, errors.Errorf("missing key column %q", keys[j])
+		}
+	}
+	if len(groups[0].keys) == 0 {
+		return nil, errors.New("no key columns of the parent")
+	}
+	for _, g := range groups {
+		g.traversals = s.traversalsByName(g.elemType, g.columns)
+		if f, err := missingFields(g.traversals); err != nil && !s.unsafe {
+			return nil, fmt.Errorf("missing column %q in dest %s", string(fieldDescriptions[g.indexes[f]].Name), g.elemType)
+		}
+	}
+	return groups, nil
+}
+
+// decodeNested decodes the raw values of the columns of g, from the row of index row, into v, a
+// pointer to a new struct.
+func (s *Scanner) decodeNested(g *nestedGroup, row int, v reflect.Value, raws []interface{}) error {
+	values := make([]interface{}, len(g.indexes))
+	conversions, err := s.fieldsByTraversal(v, g.columns, g.oids, g.traversals, values)
+	if err != nil {
+		return err
+	}
+
+	for j, i := range g.indexes {
+		if err := raws[i].(*rawValue).scan(g.oids[j], values[j]); err != nil {
+			return s.scanError(err, row, v, g.columns, g.oids, g.traversals, j)
+		}
+	}
+	for j, convert := range conversions {
+		if convert == nil {
+			continue
+		}
+		if err := convert(); err != nil {
+			return s.scanError(err, row, v, g.columns, g.oids, g.traversals, j)
+		}
+	}
+	return s.afterScan(v, g.columns, g.traversals)
+}
+
+// nestedKey returns the values of the columns of indexes in raws as a map key, and the index
+// of the first of them being NULL, or -1.
+func nestedKey(raws []interface{}, indexes []int) (string, int) {
+	srcs := make([][]byte, len(indexes))
+	for j, i := range indexes {
+		srcs[j] = raws[i].(*rawValue).src
+		if srcs[j] == nil {
+			return "", i
+		}
+	}
+	return fmt.Sprintf("%q", srcs), -1
+}
+
+// allNull reports whether the values of the columns of indexes in raws are all NULL.
+func allNull(raws []interface{}, indexes []int) bool {
+	for _, i := range indexes {
+		if raws[i].(*rawValue).src != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isStructSlice reports whether t is a slice of structs or of pointers to structs.
+func isStructSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && reflectx.Deref(t.Elem()).Kind() == reflect.Struct && !isColumnType(reflectx.Deref(t.Elem()))
+}
+
+// nilStructDepth returns the length of the prefix of traversal reaching the outermost nil
+// pointer to a struct in v, or 0 if the traversal doesn't go through one.
+func nilStructDepth(v reflect.Value, traversal []int) int {
+	if len(traversal) == 0 {
+		return 0
+	}
+
+	v = reflect.Indirect(v)
+	for depth, i := range traversal[:len(traversal)-1] {
+		v = v.Field(i)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return depth + 1
+			}
+			v = v.Elem()
+		}
+	}
+	return 0
+}
+
+// keepNilStructs lets the nil pointers to structs which fields are scanned into stay nil when
+// all of their columns are NULL, as a LEFT JOIN without a match returns. Their columns are
+// scanned through raw values, decoded into the allocated struct only if one of them is not NULL.
+// depths holds the nilStructDepth of each traversal, computed before the pointers got allocated.
+func keepNilStructs(v reflect.Value, oids []uint32, traversals [][]int, depths []int, values []interface{}, conversions []func() error) {
+	type group struct {
+		raws          []*rawValue
+		decided, null bool
+	}
+
+	groups := make(map[string]*group)
+	for i, depth := range depths {
+		if depth == 0 {
+			continue
+		}
+
+		prefix := traversals[i][:depth]
+		key := fmt.Sprint(prefix)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{}
+			groups[key] = g
+		}
+
+		raw := new(rawValue)
+		g.raws = append(g.raws, raw)
+		target, convert, oid := values[i], conversions[i], oids[i]
+
+		values[i] = raw
+		conversions[i] = func() error {
+			if !g.decided {
+				g.decided, g.null = true, true
+				for _, raw := range g.raws {
+					if raw.src != nil {
+						g.null = false
+					}
+				}
+				if g.null {
+					f := reflectx.FieldByIndexesReadOnly(v, prefix)
+					f.Set(reflect.Zero(f.Type()))
+				}
+			}
+			if g.null {
+				return nil
+			}
+
+			if err := raw.scan(oid, target); err != nil {
+				return err
+			}
+			if convert != nil {
+				return convert()
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package pgxscan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNestedOrder struct {
+	ID    string `db:"id"`
+	Total int    `db:"total"`
+}
+
+type testNestedCustomer struct {
+	ID     string            `db:"id"`
+	Name   string            `db:"name"`
+	Orders []testNestedOrder `db:"orders"`
+}
+
+func TestSelectNested(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "nested_customers", `
+		id   text PRIMARY KEY,
+		name text NOT NULL
+	`)
+	createTable(t, conn, "nested_orders", `
+		id          text PRIMARY KEY,
+		customer_id text NOT NULL,
+		total       int NOT NULL
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO nested_customers (id, name) VALUES ('customer-1', 'Alice'), ('customer-2', 'Bob'), ('customer-3', 'Carol');
+		INSERT INTO nested_orders (id, customer_id, total) VALUES
+			('order-1', 'customer-1', 10),
+			('order-2', 'customer-3', 20),
+			('order-3', 'customer-1', 30)
+	`)
+	require.NoError(t, err)
+
+	query := `
+		SELECT c.id, c.name, o.id AS "orders.id", o.total AS "orders.total"
+		FROM nested_customers c
+		LEFT JOIN nested_orders o ON o.customer_id = c.id
+		ORDER BY c.id ASC, o.id ASC
+	`
+
+	var result []testNestedCustomer
+	err = SelectNested(context.Background(), conn, &result, []string{"id"}, query)
+	require.NoError(t, err)
+	assert.Equal(t, []testNestedCustomer{
+		{ID: "customer-1", Name: "Alice", Orders: []testNestedOrder{{ID: "order-1", Total: 10}, {ID: "order-3", Total: 30}}},
+		{ID: "customer-2", Name: "Bob"},
+		{ID: "customer-3", Name: "Carol", Orders: []testNestedOrder{{ID: "order-2", Total: 20}}},
+	}, result)
+
+	// pointers to parents and children
+	var ptrResult []*struct {
+		ID     string             `db:"id"`
+		Orders []*testNestedOrder `db:"orders"`
+	}
+	err = SelectNested(context.Background(), conn, &ptrResult, []string{"id"}, `
+		SELECT c.id, o.id AS "orders.id", o.total AS "orders.total"
+		FROM nested_customers c
+		JOIN nested_orders o ON o.customer_id = c.id
+		ORDER BY c.id ASC, o.id ASC
+	`)
+	require.NoError(t, err)
+	require.Len(t, ptrResult, 2)
+	assert.Equal(t, "customer-1", ptrResult[0].ID)
+	assert.Equal(t, []*testNestedOrder{{ID: "order-1", Total: 10}, {ID: "order-3", Total: 30}}, ptrResult[0].Orders)
+	assert.Equal(t, "customer-3", ptrResult[1].ID)
+	assert.Equal(t, []*testNestedOrder{{ID: "order-2", Total: 20}}, ptrResult[1].Orders)
+
+	// test some fail cases
+	err = SelectNested(context.Background(), conn, &result, []string{"customer_id"}, query)
+	require.Error(t, err)
+	assert.Equal(t, `missing key column "customer_id"`, err.Error())
+
+	err = SelectNested(context.Background(), conn, &result, []string{"orders.id"}, query)
+	require.Error(t, err)
+	assert.Equal(t, `no key columns of the parent`, err.Error())
+
+	err = SelectNested(context.Background(), conn, &result, []string{"id"}, `SELECT NULL::text AS id, 'Dave' AS name`)
+	require.Error(t, err)
+	assert.Equal(t, `key column "id" is NULL`, err.Error())
+
+	err = SelectNested(context.Background(), conn, &result, []string{"id"}, `SELECT c.id, c.name, 1 AS "orders.quantity" FROM nested_customers c`)
+	require.Error(t, err)
+	assert.Equal(t, `missing column "orders.quantity" in dest pgxscan.testNestedOrder`, err.Error())
+
+	err = SelectNested(context.Background(), conn, &result, nil, query)
+	require.Error(t, err)
+
+	err = SelectNested(context.Background(), conn, result, []string{"id"}, query)
+	require.Error(t, err)
+}
+
+type testNestedTag struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+type testNestedPost struct {
+	ID       string           `db:"id"`
+	Title    string           `db:"title"`
+	Tags     []testNestedTag  `db:"tags"`
+	Comments []*testNestedTag `db:"comments"`
+}
+
+func TestSelectNestedManyToMany(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "nested_posts", `
+		id    text PRIMARY KEY,
+		title text NOT NULL
+	`)
+	createTable(t, conn, "nested_tags", `
+		id   text PRIMARY KEY,
+		name text NOT NULL
+	`)
+	createTable(t, conn, "nested_post_tags", `
+		post_id text NOT NULL,
+		tag_id  text NOT NULL,
+		PRIMARY KEY (post_id, tag_id)
+	`)
+	createTable(t, conn, "nested_comments", `
+		id      text PRIMARY KEY,
+		post_id text NOT NULL,
+		name    text NOT NULL
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO nested_posts (id, title) VALUES ('post-1', 'Hello'), ('post-2', 'World'), ('post-3', 'Empty');
+		INSERT INTO nested_tags (id, name) VALUES ('tag-1', 'go'), ('tag-2', 'sql');
+		INSERT INTO nested_post_tags (post_id, tag_id) VALUES
+			('post-1', 'tag-1'),
+			('post-1', 'tag-2'),
+			('post-2', 'tag-1');
+		INSERT INTO nested_comments (id, post_id, name) VALUES
+			('comment-1', 'post-1', 'first'),
+			('comment-2', 'post-1', 'second')
+	`)
+	require.NoError(t, err)
+
+	var result []testNestedPost
+	err = SelectNested(context.Background(), conn, &result, []string{"id", "tags.id", "comments.id"}, `
+		SELECT
+			p.id, p.title,
+			t.id AS "tags.id", t.name AS "tags.name",
+			c.id AS "comments.id", c.name AS "comments.name"
+		FROM nested_posts p
+		LEFT JOIN nested_post_tags pt ON pt.post_id = p.id
+		LEFT JOIN nested_tags t ON t.id = pt.tag_id
+		LEFT JOIN nested_comments c ON c.post_id = p.id
+		ORDER BY p.id ASC, t.id ASC, c.id ASC
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []testNestedPost{
+		{
+			ID:       "post-1",
+			Title:    "Hello",
+			Tags:     []testNestedTag{{ID: "tag-1", Name: "go"}, {ID: "tag-2", Name: "sql"}},
+			Comments: []*testNestedTag{{ID: "comment-1", Name: "first"}, {ID: "comment-2", Name: "second"}},
+		},
+		{ID: "post-2", Title: "World", Tags: []testNestedTag{{ID: "tag-1", Name: "go"}}},
+		{ID: "post-3", Title: "Empty"},
+	}, result)
+
+	// test some fail cases
+	err = SelectNested(context.Background(), conn, &result, []string{"id", "tags.slug"}, `
+		SELECT p.id, p.title, t.id AS "tags.id", t.name AS "tags.name"
+		FROM nested_posts p
+		JOIN nested_post_tags pt ON pt.post_id = p.id
+		JOIN nested_tags t ON t.id = pt.tag_id
+	`)
+	require.Error(t, err)
+	assert.Equal(t, `missing key column "tags.slug"`, err.Error())
+}
+
+type testNestedHookOrder struct {
+	ID    string `db:"id"`
+	Total int    `db:"total"`
+	Label string `db:"-"`
+}
+
+func (o *testNestedHookOrder) AfterScan(ctx context.Context) error {
+	o.Label = fmt.Sprintf("%s: %d", o.ID, o.Total)
+	return nil
+}
+
+type testNestedHookCustomer struct {
+	ID         string                `db:"id"`
+	Orders     []testNestedHookOrder `db:"orders"`
+	OrderCount int                   `db:"-"`
+}
+
+func (c *testNestedHookCustomer) AfterScan(ctx context.Context) error {
+	if c.ID == "" {
+		return errors.New("missing id")
+	}
+	c.OrderCount = len(c.Orders)
+	return nil
+}
+
+func TestScanNestedFakeRows(t *testing.T) {
+	rows := newFakeRows(0)
+	rows.fields = []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("name"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("orders.id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("orders.total"), DataTypeOID: pgtype.Int4OID},
+	}
+	rows.rows = [][][]byte{
+		{[]byte("customer-1"), []byte("Alice"), []byte("order-1"), []byte("10")},
+		{[]byte("customer-2"), []byte("Bob"), nil, nil},
+		{[]byte("customer-1"), []byte("ignored"), []byte("order-3"), []byte("30")},
+	}
+
+	var result []testNestedCustomer
+	err := ScanNested(rows, &result, []string{"id"})
+	require.NoError(t, err)
+	assert.Equal(t, []testNestedCustomer{
+		{ID: "customer-1", Name: "Alice", Orders: []testNestedOrder{{ID: "order-1", Total: 10}, {ID: "order-3", Total: 30}}},
+		{ID: "customer-2", Name: "Bob"},
+	}, result)
+
+	// children are appended once per parent by key
+	rows = newFakeRows(0)
+	rows.fields = []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("tags.id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("tags.name"), DataTypeOID: pgtype.TextOID},
+	}
+	rows.rows = [][][]byte{
+		{[]byte("post-1"), []byte("tag-1"), []byte("go")},
+		{[]byte("post-1"), []byte("tag-2"), []byte("sql")},
+		{[]byte("post-1"), []byte("tag-1"), []byte("go")},
+		{[]byte("post-2"), []byte("tag-1"), []byte("go")},
+		{[]byte("post-3"), nil, []byte("orphan")},
+	}
+
+	var posts []testNestedPost
+	err = ScanNested(rows, &posts, []string{"id", "tags.id"})
+	require.NoError(t, err)
+	assert.Equal(t, []testNestedPost{
+		{ID: "post-1", Tags: []testNestedTag{{ID: "tag-1", Name: "go"}, {ID: "tag-2", Name: "sql"}}},
+		{ID: "post-2", Tags: []testNestedTag{{ID: "tag-1", Name: "go"}}},
+		{ID: "post-3"},
+	}, posts)
+
+	// AfterScan is called on the children, and on the parents once folded
+	rows = newFakeRows(0)
+	rows.fields = []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("orders.id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("orders.total"), DataTypeOID: pgtype.Int4OID},
+	}
+	rows.rows = [][][]byte{
+		{[]byte("customer-1"), []byte("order-1"), []byte("10")},
+		{[]byte("customer-1"), []byte("order-2"), []byte("20")},
+		{[]byte("customer-2"), nil, nil},
+	}
+
+	var hooked []*testNestedHookCustomer
+	err = ScanNested(rows, &hooked, []string{"id"})
+	require.NoError(t, err)
+	assert.Equal(t, []*testNestedHookCustomer{
+		{ID: "customer-1", Orders: []testNestedHookOrder{{ID: "order-1", Total: 10, Label: "order-1: 10"}, {ID: "order-2", Total: 20, Label: "order-2: 20"}}, OrderCount: 2},
+		{ID: "customer-2"},
+	}, hooked)
+
+	rows = newFakeRows(0)
+	rows.fields = []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+	}
+	rows.rows = [][][]byte{{[]byte("")}}
+	err = ScanNested(rows, &hooked, []string{"id"})
+	require.Error(t, err)
+	assert.Equal(t, "AfterScan failed: missing id", err.Error())
+}
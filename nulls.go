@@ -0,0 +1,61 @@
+package pgxscan
+
+import (
+	"reflect"
+
+	pgx "github.com/jackc/pgx/v4"
+)
+
+// ScanStructNulls works like ScanStruct, and also returns the names of the columns which were
+// NULL in the scanned row. This tells a zero value from a NULL one without pointer fields:
+// NULL columns leave plain fields at their zero value instead of failing the scan.
+func ScanStructNulls(r pgx.Rows, dest interface{}) (nullColumns []string, err error) {
+	return defaultScanner.ScanStructNulls(r, dest)
+}
+
+// ScanStructNulls works like the package-level ScanStructNulls, using the Scanner options.
+func (s *Scanner) ScanStructNulls(r pgx.Rows, dest interface{}) (nullColumns []string, err error) {
+	rows := &nullTrackingRows{Rows: r}
+	if err := s.ScanStruct(rows, dest); err != nil {
+		return nil, err
+	}
+	return rows.nullColumns, nil
+}
+
+// nullTrackingRows records the NULL columns of the rows it scans, and skips scanning them
+// into destinations which can't represent NULL.
+type nullTrackingRows struct {
+	pgx.Rows
+	nullColumns []string
+}
+
+func (r *nullTrackingRows) Scan(dest ...interface{}) error {
+	fieldDescriptions := r.FieldDescriptions()
+	for i, raw := range r.RawValues() {
+		if raw != nil {
+			continue
+		}
+
+		r.nullColumns = append(r.nullColumns, string(fieldDescriptions[i].Name))
+		if d, ok := dest[i].(*columnDecoder); ok {
+			if !canScanNull(d.dest) {
+				d.dest = nil
+			}
+		} else if !canScanNull(dest[i]) {
+			dest[i] = nil
+		}
+	}
+	return r.Rows.Scan(dest...)
+}
+
+// canScanNull returns whether NULL can be scanned into dest, which is skipped if nil.
+func canScanNull(dest interface{}) bool {
+	if dest == nil || isDecoder(dest) {
+		return true
+	}
+	switch reflect.TypeOf(dest).Elem().Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNullsEntity struct {
+	ID    string  `db:"id"`
+	Name  string  `db:"name"`
+	Age   int     `db:"age"`
+	Email *string `db:"email"`
+}
+
+func TestScanStructNulls(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "nulls_test", `
+		id    text PRIMARY KEY,
+		name  text,
+		age   int,
+		email text
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO nulls_test (id, name, age, email) VALUES
+			('nulls-1', NULL, 0, NULL),
+			('nulls-2', 'foo', NULL, 'foo@example.com')
+	`)
+	require.NoError(t, err)
+
+	rows, err := conn.Query(context.Background(), "SELECT * FROM nulls_test WHERE id = $1", "nulls-1")
+	require.NoError(t, err)
+	result := new(testNullsEntity)
+	nullColumns, err := ScanStructNulls(rows, result)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "email"}, nullColumns)
+	assert.Equal(t, testNullsEntity{ID: "nulls-1"}, *result)
+
+	rows, err = conn.Query(context.Background(), "SELECT * FROM nulls_test WHERE id = $1", "nulls-2")
+	require.NoError(t, err)
+	result = new(testNullsEntity)
+	nullColumns, err = ScanStructNulls(rows, result)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"age"}, nullColumns)
+	assert.Equal(t, "foo", result.Name)
+	assert.Equal(t, 0, result.Age)
+	require.NotNil(t, result.Email)
+	assert.Equal(t, "foo@example.com", *result.Email)
+
+	rows, err = conn.Query(context.Background(), "SELECT id, age FROM nulls_test WHERE id = $1", "nulls-1")
+	require.NoError(t, err)
+	nullColumns, err = ScanStructNulls(rows, new(testNullsEntity))
+	require.NoError(t, err)
+	assert.Empty(t, nullColumns)
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), "SELECT * FROM nulls_test WHERE id = $1", "foo")
+	require.NoError(t, err)
+	nullColumns, err = ScanStructNulls(rows, new(testNullsEntity))
+	require.Error(t, err)
+	assert.Equal(t, pgx.ErrNoRows, err)
+	assert.Nil(t, nullColumns)
+}
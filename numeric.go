@@ -0,0 +1,68 @@
+package pgxscan
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/jackc/pgtype"
+	"github.com/pkg/errors"
+)
+
+var (
+	ratType    = reflect.TypeOf(big.Rat{})
+	ratPtrType = reflect.TypeOf(&big.Rat{})
+)
+
+// numericTarget returns the scan destination of the field f and the conversion decoding it,
+// if f is a big.Rat or a *big.Rat and oid the numeric type, which pgtype can't assign to them.
+func numericTarget(f reflect.Value, oid uint32) (interface{}, func() error, bool) {
+	if oid != pgtype.NumericOID || (f.Type() != ratType && f.Type() != ratPtrType) {
+		return nil, nil, false
+	}
+
+	holder := new(pgtype.Numeric)
+	return holder, func() error {
+		if holder.Status != pgtype.Present {
+			return setNull(f)
+		}
+
+		r, err := numericRat(holder)
+		if err != nil {
+			return err
+		}
+		setValue(f, reflect.ValueOf(r).Elem())
+		return nil
+	}, true
+}
+
+// numericRat returns the exact value of the numeric n.
+func numericRat(n *pgtype.Numeric) (*big.Rat, error) {
+	if n.NaN {
+		return nil, errors.New("cannot scan NaN into a big.Rat")
+	}
+
+	r := new(big.Rat).SetInt(n.Int)
+	exp := int64(n.Exp)
+	if exp < 0 {
+		exp = -exp
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	if n.Exp < 0 {
+		return r.Quo(r, scale), nil
+	}
+	return r.Mul(r, scale), nil
+}
+
+// numericText returns the text representation of the numeric n in Postgres, keeping its scale,
+// as pgtype encodes it with an exponent instead.
+func numericText(n *pgtype.Numeric) string {
+	if n.NaN {
+		return "NaN"
+	}
+
+	r, _ := numericRat(n)
+	if n.Exp < 0 {
+		return r.FloatString(int(-n.Exp))
+	}
+	return r.FloatString(0)
+}
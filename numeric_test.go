@@ -0,0 +1,84 @@
+package pgxscan
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRatEntity struct {
+	ID       string   `db:"id"`
+	Amount   big.Rat  `db:"amount"`
+	Discount *big.Rat `db:"discount"`
+}
+
+func TestScanStructsBigRat(t *testing.T) {
+	conn := connect(t)
+
+	var result []testRatEntity
+	err := Select(context.Background(), conn, &result, `
+		SELECT * FROM (VALUES
+			('rat-1', 12345678901234567890.125::numeric, 0.1::numeric),
+			('rat-2', -1.5e3::numeric, NULL::numeric)
+		) AS t (id, amount, discount)
+		ORDER BY id ASC
+	`)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, "98765431209876543121/8", result[0].Amount.String())
+	require.NotNil(t, result[0].Discount)
+	assert.Equal(t, "1/10", result[0].Discount.String())
+
+	assert.Equal(t, "-1500/1", result[1].Amount.String())
+	assert.Nil(t, result[1].Discount)
+
+	// test some fail cases
+	var nan testRatEntity
+	err = Get(context.Background(), conn, &nan, "SELECT 'rat-3' AS id, 'NaN'::numeric AS amount, NULL::numeric AS discount")
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "amount" (oid 1700) of row 0 into field Amount of type big.Rat: cannot scan NaN into a big.Rat`, err.Error())
+
+	var null testRatEntity
+	err = Get(context.Background(), conn, &null, "SELECT 'rat-4' AS id, NULL::numeric AS amount, NULL::numeric AS discount")
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "amount" (oid 1700) of row 0 into field Amount of type big.Rat: cannot scan NULL into big.Rat`, err.Error())
+}
+
+func TestScanStructBigRatText(t *testing.T) {
+	type testRatData struct {
+		ID        string  `db:"id"`
+		CreatedAt string  `db:"created_at"`
+		SomeData  big.Rat `db:"some_data"`
+	}
+
+	rows := newFakeRows(1)
+	rows.fields[1].DataTypeOID = pgtype.TextOID
+	rows.fields[2].DataTypeOID = pgtype.NumericOID
+	rows.rows[0][2] = []byte("-0.0025")
+
+	var result testRatData
+	err := ScanStruct(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, "-1/400", result.SomeData.String())
+}
+
+func TestNumericText(t *testing.T) {
+	for text, expected := range map[string]string{
+		"12.30": "12.30",
+		"-0.05": "-0.05",
+		"1200":  "1200",
+		"0":     "0",
+	} {
+		var n pgtype.Numeric
+		require.NoError(t, n.DecodeText(nil, []byte(text)))
+		assert.Equal(t, expected, numericText(&n), text)
+	}
+
+	assert.Equal(t, "1500", numericText(&pgtype.Numeric{Int: big.NewInt(15), Exp: 2, Status: pgtype.Present}))
+	assert.Equal(t, "NaN", numericText(&pgtype.Numeric{NaN: true, Status: pgtype.Present}))
+}
@@ -0,0 +1,115 @@
+// Package otelpgxscan traces the queries of a pgxscan.Querier with OpenTelemetry, so that the time
+// spent querying and scanning shows up in distributed traces.
+package otelpgxscan
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+const instrumentationName = "github.com/pyr-sh/pgxscan/v2/otelpgxscan"
+
+var (
+	systemKey    = attribute.Key("db.system")
+	statementKey = attribute.Key("db.statement")
+	argsKey      = attribute.Key("pgxscan.args")
+	rowsKey      = attribute.Key("pgxscan.rows")
+)
+
+// Option configures the tracing of queries.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	statement      func(query string) string
+}
+
+// WithTracerProvider starts spans with the tracers of tp instead of the global TracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithStatement records the result of fn applied to the query text as the statement of spans,
+// instead of the query text itself, to truncate it or remove sensitive parts. Spans have no
+// statement if fn returns an empty string.
+func WithStatement(fn func(query string) string) Option {
+	return func(c *config) {
+		c.statement = fn
+	}
+}
+
+// Truncate returns a function for WithStatement truncating query texts to at most n bytes,
+// without splitting a UTF-8 character.
+func Truncate(n int) func(query string) string {
+	return func(query string) string {
+		if len(query) <= n {
+			return query
+		}
+		for n > 0 && !utf8.RuneStart(query[n]) {
+			n--
+		}
+		return query[:n]
+	}
+}
+
+// Middleware returns a pgxscan.Middleware tracing queries, see NewQuerier.
+func Middleware(opts ...Option) pgxscan.Middleware {
+	return func(q pgxscan.Querier) pgxscan.Querier {
+		return NewQuerier(q, opts...)
+	}
+}
+
+// NewQuerier returns a pgxscan.Querier starting a span for each query run with q, so the Get,
+// Select and other functions using it are traced. Spans record the query text, the number of
+// arguments, the number of rows read and the error the query failed with. The span of a query
+// returning rows ends once they are closed, so it covers the time spent scanning them.
+func NewQuerier(q pgxscan.Querier, opts ...Option) pgxscan.Querier {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		statement:      func(query string) string { return query },
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return pgxscan.Trace(&tracer{
+		tracer:    c.tracerProvider.Tracer(instrumentationName),
+		statement: c.statement,
+	})(q)
+}
+
+// tracer is a pgxscan.TraceHook starting a span when each query starts, ending it once done
+// with the query.
+type tracer struct {
+	tracer    trace.Tracer
+	statement func(query string) string
+}
+
+func (t *tracer) StartQuery(ctx context.Context, event pgxscan.QueryEvent) context.Context {
+	attrs := []attribute.KeyValue{systemKey.String("postgresql"), argsKey.Int(len(event.Args))}
+	if statement := t.statement(event.Query); statement != "" {
+		attrs = append(attrs, statementKey.String(statement))
+	}
+	ctx, _ = t.tracer.Start(ctx, "pgxscan."+event.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (t *tracer) ObserveQuery(ctx context.Context, event pgxscan.QueryEvent) {
+	span := trace.SpanFromContext(ctx)
+	if event.Err != nil {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	} else {
+		span.SetAttributes(rowsKey.Int64(event.Rows))
+	}
+	span.End()
+}
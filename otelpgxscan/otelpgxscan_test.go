@@ -0,0 +1,106 @@
+package otelpgxscan
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+type testEntity struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestQuerier(t *testing.T) {
+	conn := connect(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	q := pgxscan.Wrap(conn, Middleware(WithTracerProvider(tp), WithStatement(Truncate(20))))
+
+	var results []*testEntity
+	err := pgxscan.Select(context.Background(), q, &results, "SELECT * FROM otelpgxscan_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	var name string
+	err = q.QueryRow(context.Background(), "SELECT name FROM otelpgxscan_test WHERE id = $1", "foo").Scan(&name)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = q.Exec(context.Background(), "UPDATE otelpgxscan_test SET name = name")
+	require.NoError(t, err)
+
+	// test some fail cases
+	var result testEntity
+	err = pgxscan.Get(context.Background(), q, &result, "SELECT * FROM otelpgxscan_test_missing")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	assert.Equal(t, "pgxscan.Query", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.statement", "SELECT * FROM otelpgx"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("pgxscan.args", 0))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("pgxscan.rows", 2))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "pgxscan.QueryRow", spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), attribute.Int("pgxscan.args", 1))
+	assert.Contains(t, spans[1].Attributes(), attribute.Int("pgxscan.rows", 0))
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+
+	assert.Equal(t, "pgxscan.Exec", spans[2].Name())
+	assert.Contains(t, spans[2].Attributes(), attribute.Int64("pgxscan.rows", 2))
+
+	assert.Equal(t, "pgxscan.Query", spans[3].Name())
+	assert.Equal(t, codes.Error, spans[3].Status().Code)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "SELECT", Truncate(6)("SELECT 1"))
+	assert.Equal(t, "SELECT 1", Truncate(20)("SELECT 1"))
+
+	// UTF-8 characters are not split
+	assert.Equal(t, "SELECT '", Truncate(9)("SELECT 'é'"))
+	assert.Equal(t, "SELECT 'é", Truncate(10)("SELECT 'é'"))
+}
+
+func connect(t *testing.T) *pgx.Conn {
+	t.Helper()
+
+	connString := os.Getenv("TEST_POSTGRES_URI")
+	require.NotEmpty(t, connString)
+
+	conn, err := pgx.Connect(context.Background(), connString)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := conn.Close(context.Background())
+		assert.NoError(t, err)
+	})
+
+	_, err = conn.Exec(context.Background(), `DROP TABLE IF EXISTS otelpgxscan_test`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `
+		CREATE TABLE otelpgxscan_test (
+			id   text PRIMARY KEY,
+			name text not null
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `INSERT INTO otelpgxscan_test (id, name) VALUES ('a', 'foo'), ('b', 'bar')`)
+	require.NoError(t, err)
+
+	return conn
+}
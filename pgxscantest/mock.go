@@ -0,0 +1,262 @@
+package pgxscantest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+// MockQuerier is a pgxscan.Querier serving the rows and errors of the expectations set on it,
+// like sqlmock does for database/sql, so that code running queries can be unit tested. Queries
+// must run in the order of the expectations, and each expectation is met once.
+type MockQuerier struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+var _ pgxscan.Querier = (*MockQuerier)(nil)
+
+// Call is a query run through a MockQuerier.
+type Call struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Argument matches the argument of a query in place of an expected value, see AnyArg.
+type Argument interface {
+	Match(arg interface{}) bool
+}
+
+type anyArg struct{}
+
+func (anyArg) Match(interface{}) bool { return true }
+
+// AnyArg returns an Argument matching any value, for arguments such as generated ids and times.
+func AnyArg() Argument {
+	return anyArg{}
+}
+
+// Expectation is a query expected by a MockQuerier, and what it returns.
+type Expectation struct {
+	exec    bool
+	sql     string
+	pattern *regexp.Regexp
+	args    []interface{}
+	anyArgs bool
+	rows    *Rows
+	tag     pgconn.CommandTag
+	err     error
+	met     bool
+}
+
+// NewMockQuerier returns a MockQuerier without expectations.
+func NewMockQuerier() *MockQuerier {
+	return &MockQuerier{}
+}
+
+// ExpectQuery expects a call to Query or QueryRow with sql, compared with whitespace collapsed so
+// that indentation does not matter. It returns no rows unless set otherwise.
+func (m *MockQuerier) ExpectQuery(sql string) *Expectation {
+	return m.expect(&Expectation{sql: sql})
+}
+
+// ExpectQueryRegexp expects a call to Query or QueryRow with a query matching pattern.
+func (m *MockQuerier) ExpectQueryRegexp(pattern string) *Expectation {
+	return m.expect(&Expectation{pattern: regexp.MustCompile(pattern)})
+}
+
+// ExpectExec expects a call to Exec with sql, compared like ExpectQuery does.
+func (m *MockQuerier) ExpectExec(sql string) *Expectation {
+	return m.expect(&Expectation{exec: true, sql: sql})
+}
+
+// ExpectExecRegexp expects a call to Exec with a query matching pattern.
+func (m *MockQuerier) ExpectExecRegexp(pattern string) *Expectation {
+	return m.expect(&Expectation{exec: true, pattern: regexp.MustCompile(pattern)})
+}
+
+func (m *MockQuerier) expect(e *Expectation) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e.anyArgs = true
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// WithArgs makes e match queries with args only, compared with reflect.DeepEqual unless they are
+// Arguments. Queries match whatever their arguments without it.
+func (e *Expectation) WithArgs(args ...interface{}) *Expectation {
+	e.args = args
+	e.anyArgs = false
+	return e
+}
+
+// WillReturnRows makes the query return rows.
+func (e *Expectation) WillReturnRows(rows *Rows) *Expectation {
+	e.rows = rows
+	return e
+}
+
+// WillReturnCommandTag makes Exec return tag, such as "UPDATE 1".
+func (e *Expectation) WillReturnCommandTag(tag pgconn.CommandTag) *Expectation {
+	e.tag = tag
+	return e
+}
+
+// WillReturnError makes the query fail with err.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	kind := "query"
+	if e.exec {
+		kind = "exec"
+	}
+	if e.pattern != nil {
+		return fmt.Sprintf("%s matching %q", kind, e.pattern)
+	}
+	return fmt.Sprintf("%s %q", kind, e.sql)
+}
+
+func (e *Expectation) matches(exec bool, sql string, args []interface{}) bool {
+	if e.exec != exec {
+		return false
+	}
+	if e.pattern != nil {
+		if !e.pattern.MatchString(sql) {
+			return false
+		}
+	} else if strings.Join(strings.Fields(e.sql), " ") != strings.Join(strings.Fields(sql), " ") {
+		return false
+	}
+	if e.anyArgs {
+		return true
+	}
+
+	if len(e.args) != len(args) {
+		return false
+	}
+	for i, expected := range e.args {
+		if matcher, ok := expected.(Argument); ok {
+			if !matcher.Match(args[i]) {
+				return false
+			}
+		} else if !reflect.DeepEqual(expected, args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// next records the call and returns the expectation it meets, or an error if it is unexpected.
+func (m *MockQuerier) next(exec bool, sql string, args []interface{}) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, Call{SQL: sql, Args: args})
+	for _, e := range m.expectations {
+		if e.met {
+			continue
+		}
+		if !e.matches(exec, sql, args) {
+			return nil, errors.Errorf("pgxscantest: query %q with args %v does not match the next expectation, %s", sql, args, e)
+		}
+		e.met = true
+		return e, nil
+	}
+	return nil, errors.Errorf("pgxscantest: unexpected query %q with args %v", sql, args)
+}
+
+func (m *MockQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	e, err := m.next(false, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.rows == nil {
+		return NewRows(nil), nil
+	}
+	return e.rows, nil
+}
+
+func (m *MockQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := m.Query(ctx, sql, args...)
+	return &row{rows: rows, err: err}
+}
+
+func (m *MockQuerier) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	e, err := m.next(true, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.tag, nil
+}
+
+// Calls returns the queries run so far, expected or not, in order.
+func (m *MockQuerier) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// ExpectationsWereMet returns an error listing the expectations not met yet, if any, to be
+// checked at the end of tests.
+func (m *MockQuerier) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var unmet []string
+	for _, e := range m.expectations {
+		if !e.met {
+			unmet = append(unmet, e.String())
+		}
+	}
+	if len(unmet) > 0 {
+		return errors.Errorf("pgxscantest: expectations not met: %s", strings.Join(unmet, ", "))
+	}
+	return nil
+}
+
+// row is the pgx.Row of QueryRow, scanning the first row of rows like pgx does.
+type row struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
@@ -0,0 +1,81 @@
+package pgxscantest
+
+import (
+	"context"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+type testAuthor struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestMockQuerier(t *testing.T) {
+	m := NewMockQuerier()
+	m.ExpectQuery(`
+		SELECT id, name
+		FROM authors
+		WHERE id = $1
+	`).WithArgs(int64(1)).WillReturnRows(NewRows([]string{"id", "name"}).AddRow(int64(1), "Frank Herbert"))
+	m.ExpectQueryRegexp(`^SELECT .* FROM authors ORDER BY`).WillReturnRows(RowsFromStructs([]testAuthor{
+		{ID: 1, Name: "Frank Herbert"},
+		{ID: 2, Name: "Jane Austen"},
+	}))
+	m.ExpectExec("UPDATE authors SET name = $1 WHERE id = $2").WithArgs(AnyArg(), int64(2)).WillReturnCommandTag([]byte("UPDATE 1"))
+	m.ExpectQuery("SELECT count(*) FROM authors")
+
+	var author testAuthor
+	err := pgxscan.Get(context.Background(), m, &author, "SELECT id, name FROM authors WHERE id = $1", int64(1))
+	require.NoError(t, err)
+	assert.Equal(t, testAuthor{ID: 1, Name: "Frank Herbert"}, author)
+
+	var authors []testAuthor
+	err = pgxscan.Select(context.Background(), m, &authors, "SELECT id, name FROM authors ORDER BY id")
+	require.NoError(t, err)
+	assert.Equal(t, []testAuthor{{ID: 1, Name: "Frank Herbert"}, {ID: 2, Name: "Jane Austen"}}, authors)
+
+	tag, err := m.Exec(context.Background(), "UPDATE authors SET name = $1 WHERE id = $2", "Emma", int64(2))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tag.RowsAffected())
+
+	require.Error(t, m.ExpectationsWereMet())
+
+	var count int
+	err = m.QueryRow(context.Background(), "SELECT count(*) FROM authors").Scan(&count)
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Len(t, m.Calls(), 4)
+	assert.Equal(t, Call{SQL: "SELECT id, name FROM authors WHERE id = $1", Args: []interface{}{int64(1)}}, m.Calls()[0])
+
+	// test some fail cases
+	queryErr := errors.New("relation does not exist")
+	m = NewMockQuerier()
+	m.ExpectQuery("SELECT * FROM missing").WillReturnError(queryErr)
+	m.ExpectExec("DELETE FROM authors").WithArgs(int64(1))
+
+	err = pgxscan.Select(context.Background(), m, &authors, "SELECT * FROM missing")
+	assert.True(t, errors.Is(err, queryErr))
+
+	_, err = m.Exec(context.Background(), "DELETE FROM authors", int64(2))
+	require.Error(t, err)
+	assert.Equal(t, `pgxscantest: query "DELETE FROM authors" with args [2] does not match the next expectation, exec "DELETE FROM authors"`, err.Error())
+
+	err = m.ExpectationsWereMet()
+	require.Error(t, err)
+	assert.Equal(t, `pgxscantest: expectations not met: exec "DELETE FROM authors"`, err.Error())
+
+	_, err = m.Exec(context.Background(), "DELETE FROM authors", int64(1))
+	require.NoError(t, err)
+
+	_, err = m.Query(context.Background(), "SELECT 1")
+	require.Error(t, err)
+	assert.Equal(t, `pgxscantest: unexpected query "SELECT 1" with args []`, err.Error())
+}
@@ -0,0 +1,240 @@
+// Package pgxscantest provides in-memory fakes of pgx results, so that code scanning rows with
+// pgxscan can be unit tested without a live Postgres.
+package pgxscantest
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// Rows is an in-memory pgx.Rows serving the rows added with AddRow. Values are encoded the way
+// Postgres sends them, in the binary format if their type has one, so they are scanned like the
+// result of a query, through pgtype.
+//
+// The type of each column is inferred from the Go type of its first non-nil value, text if they
+// are all nil, unless set with WithTypes. Maps, structs and slices of types without a matching
+// Postgres type are encoded as jsonb. Rows can be read once.
+type Rows struct {
+	columns []string
+	oids    []uint32
+	values  [][]interface{}
+	rowErrs map[int]error
+	ci      *pgtype.ConnInfo
+
+	fields  []pgproto3.FieldDescription
+	encoded [][]byte
+	row     int
+	err     error
+	closed  bool
+}
+
+var _ pgx.Rows = (*Rows)(nil)
+
+// NewRows returns empty Rows with columns.
+func NewRows(columns []string) *Rows {
+	return &Rows{
+		columns: columns,
+		rowErrs: make(map[int]error),
+		ci:      pgtype.NewConnInfo(),
+		row:     -1,
+	}
+}
+
+// AddRow adds a row of values, one per column, such as strings, ints, time.Time or nil for NULL.
+// It panics if the number of values doesn't match the number of columns.
+func (r *Rows) AddRow(values ...interface{}) *Rows {
+	if len(values) != len(r.columns) {
+		panic(fmt.Sprintf("pgxscantest: got %d values for %d columns", len(values), len(r.columns)))
+	}
+	r.values = append(r.values, values)
+	return r
+}
+
+// WithTypes sets the type OIDs of the columns, such as pgtype.JSONBOID, instead of inferring
+// them from the values. An OID of 0 keeps the type of its column inferred. It panics if the
+// number of OIDs doesn't match the number of columns.
+func (r *Rows) WithTypes(oids ...uint32) *Rows {
+	if len(oids) != len(r.columns) {
+		panic(fmt.Sprintf("pgxscantest: got %d types for %d columns", len(oids), len(r.columns)))
+	}
+	r.oids = oids
+	return r
+}
+
+// RowError makes Next fail with err when reaching the row of index row, from 0, as a query
+// failing midway does.
+func (r *Rows) RowError(row int, err error) *Rows {
+	r.rowErrs[row] = err
+	return r
+}
+
+// ConnInfo returns the types the values are encoded and decoded with, to register custom ones.
+func (r *Rows) ConnInfo() *pgtype.ConnInfo {
+	return r.ci
+}
+
+func (r *Rows) Close() {
+	r.closed = true
+}
+
+func (r *Rows) Err() error {
+	return r.err
+}
+
+func (r *Rows) CommandTag() pgconn.CommandTag {
+	if !r.closed {
+		return nil
+	}
+	return pgconn.CommandTag(fmt.Sprintf("SELECT %d", len(r.values)))
+}
+
+func (r *Rows) FieldDescriptions() []pgproto3.FieldDescription {
+	if r.fields == nil {
+		r.fields = make([]pgproto3.FieldDescription, len(r.columns))
+		for i, column := range r.columns {
+			oid, err := r.columnOID(i)
+			if err != nil && r.err == nil {
+				r.err = err
+			}
+			r.fields[i] = pgproto3.FieldDescription{
+				Name:        []byte(column),
+				DataTypeOID: oid,
+				Format:      r.format(oid),
+			}
+		}
+	}
+	return r.fields
+}
+
+// format returns the format Postgres sends values of type oid in to pgx: binary if pgtype can
+// decode and encode it.
+func (r *Rows) format(oid uint32) int16 {
+	dt, ok := r.ci.DataTypeForOID(oid)
+	if !ok {
+		return pgtype.TextFormatCode
+	}
+	if _, ok := dt.Value.(pgtype.BinaryEncoder); !ok {
+		return pgtype.TextFormatCode
+	}
+	return r.ci.ResultFormatCodeForOID(oid)
+}
+
+// columnOID returns the type OID of the column of index i.
+func (r *Rows) columnOID(i int) (uint32, error) {
+	if r.oids != nil && r.oids[i] != 0 {
+		return r.oids[i], nil
+	}
+	for _, values := range r.values {
+		if values[i] == nil {
+			continue
+		}
+		if dt, ok := r.ci.DataTypeForValue(values[i]); ok {
+			return dt.OID, nil
+		}
+		switch reflect.Indirect(reflect.ValueOf(values[i])).Kind() {
+		case reflect.Map, reflect.Struct, reflect.Slice:
+			return pgtype.JSONBOID, nil
+		}
+		return pgtype.TextOID, errors.Errorf("cannot infer the type of column %q from %T, see WithTypes", r.columns[i], values[i])
+	}
+	return pgtype.TextOID, nil
+}
+
+func (r *Rows) Next() bool {
+	fields := r.FieldDescriptions()
+	if r.closed || r.err != nil {
+		return false
+	}
+
+	r.row++
+	if err, ok := r.rowErrs[r.row]; ok {
+		r.err = err
+		r.Close()
+		return false
+	}
+	if r.row >= len(r.values) {
+		r.Close()
+		return false
+	}
+
+	r.encoded = make([][]byte, len(fields))
+	for i, value := range r.values[r.row] {
+		src, err := r.encode(fields[i], value)
+		if err != nil {
+			r.err = errors.Wrapf(err, "failed to encode column %q of row %d", r.columns[i], r.row)
+			r.Close()
+			return false
+		}
+		r.encoded[i] = src
+	}
+	return true
+}
+
+// encode returns the encoding of value as a column described by field, nil for NULL.
+func (r *Rows) encode(field pgproto3.FieldDescription, value interface{}) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	dt, ok := r.ci.DataTypeForOID(field.DataTypeOID)
+	if !ok {
+		return nil, errors.Errorf("unknown type oid %d", field.DataTypeOID)
+	}
+
+	v := pgtype.NewValue(dt.Value)
+	if err := v.Set(value); err != nil {
+		return nil, err
+	}
+	if field.Format == pgtype.BinaryFormatCode {
+		return v.(pgtype.BinaryEncoder).EncodeBinary(r.ci, nil)
+	}
+	encoder, ok := v.(pgtype.TextEncoder)
+	if !ok {
+		return nil, errors.Errorf("%T has no text format", v)
+	}
+	return encoder.EncodeText(r.ci, nil)
+}
+
+func (r *Rows) Scan(dest ...interface{}) error {
+	fields := r.FieldDescriptions()
+	if len(fields) != len(dest) {
+		return errors.Errorf("number of field descriptions must equal number of destinations, got %d and %d", len(fields), len(dest))
+	}
+
+	for i, d := range dest {
+		if d == nil {
+			continue
+		}
+		if err := r.ci.Scan(fields[i].DataTypeOID, fields[i].Format, r.encoded[i], d); err != nil {
+			// like pgx
+			return fmt.Errorf("can't scan into dest[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rows) Values() ([]interface{}, error) {
+	fields := r.FieldDescriptions()
+	values := make([]interface{}, len(fields))
+	for i, src := range r.encoded {
+		if src == nil {
+			continue
+		}
+		dt, _ := r.ci.DataTypeForOID(fields[i].DataTypeOID)
+		v := pgtype.NewValue(dt.Value)
+		if err := r.ci.Scan(fields[i].DataTypeOID, fields[i].Format, src, v); err != nil {
+			return nil, err
+		}
+		values[i] = v.Get()
+	}
+	return values, nil
+}
+
+func (r *Rows) RawValues() [][]byte {
+	return r.encoded
+}
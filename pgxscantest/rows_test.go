@@ -0,0 +1,77 @@
+package pgxscantest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+type testEntity struct {
+	ID        int64             `db:"id"`
+	Name      *string           `db:"name"`
+	CreatedAt time.Time         `db:"created_at"`
+	Tags      []string          `db:"tags"`
+	Metadata  map[string]string `db:"metadata,json"`
+}
+
+func TestRows(t *testing.T) {
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := NewRows([]string{"id", "name", "created_at", "tags", "metadata"}).
+		WithTypes(0, 0, 0, 0, pgtype.JSONBOID).
+		AddRow(int64(1), "foo", createdAt, []string{"a", "b"}, `{"k": "v"}`).
+		AddRow(int64(2), nil, createdAt, nil, nil)
+
+	var result []testEntity
+	err := pgxscan.ScanStructs(rows, &result)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	foo := "foo"
+	assert.Equal(t, int64(1), result[0].ID)
+	assert.Equal(t, &foo, result[0].Name)
+	assert.True(t, createdAt.Equal(result[0].CreatedAt))
+	assert.Equal(t, []string{"a", "b"}, result[0].Tags)
+	assert.Equal(t, map[string]string{"k": "v"}, result[0].Metadata)
+	assert.Nil(t, result[1].Name)
+	assert.Nil(t, result[1].Tags)
+	assert.Nil(t, result[1].Metadata)
+	assert.Equal(t, "SELECT 2", string(rows.CommandTag()))
+
+	// values and raw values
+	rows = NewRows([]string{"id", "name"}).AddRow(1, "foo")
+	require.True(t, rows.Next())
+	values, err := rows.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(1), "foo"}, values)
+	assert.Equal(t, []byte("foo"), rows.RawValues()[1])
+	assert.Equal(t, uint32(pgtype.Int8OID), rows.FieldDescriptions()[0].DataTypeOID)
+	assert.False(t, rows.Next())
+	assert.NoError(t, rows.Err())
+
+	// test some fail cases
+	rowErr := errors.New("connection reset")
+	rows = NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, rowErr)
+	var ids []int
+	err = pgxscan.ScanFlat(rows, &ids)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, rowErr))
+
+	rows = NewRows([]string{"id"}).AddRow(make(chan int))
+	err = pgxscan.ScanFlat(rows, &ids)
+	require.Error(t, err)
+	assert.Equal(t, `cannot infer the type of column "id" from chan int, see WithTypes`, err.Error())
+
+	rows = NewRows([]string{"id"}).AddRow("foo")
+	err = pgxscan.ScanStruct(rows, &testEntity{})
+	require.Error(t, err)
+
+	assert.Panics(t, func() {
+		NewRows([]string{"id", "name"}).AddRow(1)
+	})
+}
@@ -0,0 +1,65 @@
+package pgxscantest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+// RowsFromStructs returns Rows with a row per element of slice, a slice of structs or pointers to
+// structs, which columns are the fields mapped like pgxscan.InsertArgs maps them, with db tags.
+// It panics if slice is not a slice of structs.
+func RowsFromStructs(slice interface{}) *Rows {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("pgxscantest: expected a slice of structs, got %T", slice))
+	}
+
+	// the columns of an empty slice come from its element type
+	prototype := reflect.New(v.Type().Elem()).Elem()
+	if prototype.Kind() == reflect.Ptr {
+		prototype = reflect.New(prototype.Type().Elem())
+	}
+	columns, _, err := pgxscan.InsertArgs(prototype.Interface())
+	if err != nil {
+		panic(fmt.Sprintf("pgxscantest: %v", err))
+	}
+
+	rows := NewRows(columns)
+	for i := 0; i < v.Len(); i++ {
+		_, values, err := pgxscan.InsertArgs(v.Index(i).Interface())
+		if err != nil {
+			panic(fmt.Sprintf("pgxscantest: element %d: %v", i, err))
+		}
+		rows.AddRow(values...)
+	}
+	return rows
+}
+
+// RowsFromMaps returns Rows with a row per map, which columns are the keys of all the maps, in
+// alphabetical order. The columns missing from a map are NULL in its row.
+func RowsFromMaps(maps []map[string]interface{}) *Rows {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, m := range maps {
+		for column := range m {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	rows := NewRows(columns)
+	for _, m := range maps {
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			values[i] = m[column]
+		}
+		rows.AddRow(values...)
+	}
+	return rows
+}
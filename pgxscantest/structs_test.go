@@ -0,0 +1,81 @@
+package pgxscantest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+func TestRowsFromStructs(t *testing.T) {
+	name := "foo"
+	entities := []*testEntity{
+		{ID: 1, Name: &name, CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Tags: []string{"a"}, Metadata: map[string]string{"k": "v"}},
+		{ID: 2, CreatedAt: time.Date(2020, 1, 3, 3, 4, 5, 0, time.UTC)},
+	}
+
+	rows := RowsFromStructs(entities)
+	cols := make([]string, len(rows.FieldDescriptions()))
+	for i, fieldDescription := range rows.FieldDescriptions() {
+		cols[i] = string(fieldDescription.Name)
+	}
+	assert.Equal(t, []string{"id", "name", "created_at", "tags", "metadata"}, cols)
+
+	var result []*testEntity
+	err := pgxscan.ScanStructs(rows, &result)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	for i := range entities {
+		assert.Equal(t, entities[i].ID, result[i].ID)
+		assert.Equal(t, entities[i].Name, result[i].Name)
+		assert.True(t, entities[i].CreatedAt.Equal(result[i].CreatedAt))
+		assert.Equal(t, entities[i].Tags, result[i].Tags)
+		assert.Equal(t, entities[i].Metadata, result[i].Metadata)
+	}
+
+	// an empty slice still has columns
+	rows = RowsFromStructs([]testEntity{})
+	assert.Len(t, rows.FieldDescriptions(), 5)
+	assert.False(t, rows.Next())
+
+	// test some fail cases
+	assert.Panics(t, func() {
+		RowsFromStructs(entities[0])
+	})
+	assert.Panics(t, func() {
+		RowsFromStructs([]int{1})
+	})
+}
+
+func TestRowsFromMaps(t *testing.T) {
+	rows := RowsFromMaps([]map[string]interface{}{
+		{"id": int64(1), "name": "foo"},
+		{"id": int64(2), "created_at": time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	})
+
+	var result []struct {
+		ID        int64      `db:"id"`
+		Name      *string    `db:"name"`
+		CreatedAt *time.Time `db:"created_at"`
+	}
+	err := pgxscan.ScanStructs(rows, &result)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	foo := "foo"
+	assert.Equal(t, int64(1), result[0].ID)
+	assert.Equal(t, &foo, result[0].Name)
+	assert.Nil(t, result[0].CreatedAt)
+	assert.Equal(t, int64(2), result[1].ID)
+	assert.Nil(t, result[1].Name)
+	require.NotNil(t, result[1].CreatedAt)
+	assert.True(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Equal(*result[1].CreatedAt))
+
+	// test some fail cases
+	rows = RowsFromMaps([]map[string]interface{}{{"id": "foo"}})
+	err = pgxscan.ScanStructs(rows, &[]testEntity{})
+	require.Error(t, err)
+}
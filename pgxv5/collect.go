@@ -0,0 +1,71 @@
+package pgxv5
+
+import (
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+// RowToStructByTag scans row into a T like pgxscan.ScanStruct, mapping columns to fields by
+// their db tag. It is a pgx.RowToFunc, to use pgxscan's mapping with pgx.CollectRows and the
+// other collection functions of pgx:
+//
+//	users, err := pgx.CollectRows(rows, pgxv5.RowToStructByTag[User])
+func RowToStructByTag[T any](row pgx.CollectableRow) (T, error) {
+	var value T
+	err := pgxscan.ScanStruct(Wrap(&collectedRow{row: row}), &value)
+	return value, err
+}
+
+// RowToAddrOfStructByTag works like RowToStructByTag, returning a pointer to the T.
+func RowToAddrOfStructByTag[T any](row pgx.CollectableRow) (*T, error) {
+	value := new(T)
+	err := pgxscan.ScanStruct(Wrap(&collectedRow{row: row}), value)
+	return value, err
+}
+
+// collectedRow implements pgx.Rows over the current row passed to a pgx.RowToFunc, so that
+// scanning it doesn't move the underlying rows, which pgx iterates itself.
+type collectedRow struct {
+	row  pgx.CollectableRow
+	read bool
+}
+
+func (r *collectedRow) Close() {}
+
+func (r *collectedRow) Err() error {
+	return nil
+}
+
+func (r *collectedRow) CommandTag() pgconn.CommandTag {
+	return pgconn.CommandTag{}
+}
+
+func (r *collectedRow) FieldDescriptions() []pgconn.FieldDescription {
+	return r.row.FieldDescriptions()
+}
+
+func (r *collectedRow) Next() bool {
+	if r.read {
+		return false
+	}
+	r.read = true
+	return true
+}
+
+func (r *collectedRow) Scan(dest ...interface{}) error {
+	return r.row.Scan(dest...)
+}
+
+func (r *collectedRow) Values() ([]interface{}, error) {
+	return r.row.Values()
+}
+
+func (r *collectedRow) RawValues() [][]byte {
+	return r.row.RawValues()
+}
+
+func (r *collectedRow) Conn() *pgx.Conn {
+	return nil
+}
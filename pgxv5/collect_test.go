@@ -0,0 +1,92 @@
+package pgxv5
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRow is a pgx.CollectableRow of text values, decoded by pgx v5.
+type fakeRow struct {
+	fields []pgconn.FieldDescription
+	values [][]byte
+}
+
+func (r *fakeRow) FieldDescriptions() []pgconn.FieldDescription { return r.fields }
+func (r *fakeRow) RawValues() [][]byte                          { return r.values }
+func (r *fakeRow) Values() ([]interface{}, error)               { return nil, nil }
+
+func (r *fakeRow) Scan(dest ...interface{}) error {
+	m := pgtype.NewMap()
+	for i, d := range dest {
+		if err := m.Scan(r.fields[i].DataTypeOID, pgx.TextFormatCode, r.values[i], d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newFakeRow(id, note string) *fakeRow {
+	return &fakeRow{
+		fields: []pgconn.FieldDescription{
+			{Name: "id", DataTypeOID: pgtype.TextOID},
+			{Name: "note", DataTypeOID: pgtype.TextOID},
+		},
+		values: [][]byte{[]byte(id), []byte(note)},
+	}
+}
+
+func TestRowToStructByTag(t *testing.T) {
+	type entity struct {
+		ID   string  `db:"id"`
+		Note *string `db:"note"`
+	}
+
+	result, err := RowToStructByTag[entity](newFakeRow("foo", "bar"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", result.ID)
+	require.NotNil(t, result.Note)
+	assert.Equal(t, "bar", *result.Note)
+
+	ptr, err := RowToAddrOfStructByTag[entity](newFakeRow("baz", "qux"))
+	require.NoError(t, err)
+	assert.Equal(t, "baz", ptr.ID)
+
+	// test some fail cases
+	_, err = RowToStructByTag[struct {
+		ID string `db:"id"`
+	}](newFakeRow("foo", "bar"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing column "note"`)
+}
+
+func TestCollectRows(t *testing.T) {
+	conn := connect(t)
+
+	rows, err := conn.Query(context.Background(), "SELECT * FROM pgxv5_test ORDER BY id ASC")
+	require.NoError(t, err)
+	results, err := pgx.CollectRows(rows, RowToStructByTag[testEntity])
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "pgxv5-1", results[0].ID)
+	assert.Equal(t, "bar", results[0].Label.String)
+	assert.Nil(t, results[1].Note)
+
+	rows, err = conn.Query(context.Background(), "SELECT * FROM pgxv5_test WHERE id = $1", "pgxv5-2")
+	require.NoError(t, err)
+	result, err := pgx.CollectOneRow(rows, RowToAddrOfStructByTag[testEntity])
+	require.NoError(t, err)
+	assert.Equal(t, "pgxv5-2", result.ID)
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), "SELECT id, 1 AS foo FROM pgxv5_test")
+	require.NoError(t, err)
+	_, err = pgx.CollectRows(rows, RowToStructByTag[testEntity])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing column "foo"`)
+}
@@ -0,0 +1,177 @@
+// Package pgxv5 provides the pgxscan functions for pgx v5 connections, pools and transactions.
+//
+// The rows returned by pgx v5 are adapted to the pgx v4 interface the scanning code works with,
+// so the scanning behavior is the same as pgxscan's. Wrap adapts rows to use a pgxscan.Scanner.
+package pgxv5
+
+import (
+	"context"
+
+	pgconnv4 "github.com/jackc/pgconn"
+	pgprotov4 "github.com/jackc/pgproto3/v2"
+	pgtypev4 "github.com/jackc/pgtype"
+	pgxv4 "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+// Querier is implemented by *pgx.Conn, *pgxpool.Pool and pgx.Tx of pgx v5.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// Get works like pgxscan.Get.
+func Get(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	rows, err := querier.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return ScanStruct(rows, dest)
+}
+
+// Select works like pgxscan.Select.
+func Select(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	rows, err := querier.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return ScanStructs(rows, dest)
+}
+
+// ScanStruct works like pgxscan.ScanStruct, returning the pgx v5 pgx.ErrNoRows if there are no rows.
+func ScanStruct(r pgx.Rows, dest interface{}) error {
+	err := pgxscan.ScanStruct(Wrap(r), dest)
+	if errors.Is(err, pgxv4.ErrNoRows) {
+		return pgx.ErrNoRows
+	}
+	return err
+}
+
+// ScanStructs works like pgxscan.ScanStructs.
+func ScanStructs(r pgx.Rows, dest interface{}) error {
+	return pgxscan.ScanStructs(Wrap(r), dest)
+}
+
+// Wrap adapts pgx v5 rows to the pgx v4 pgx.Rows interface, to scan them with a pgxscan.Scanner.
+// The errors returned by the Scanner are the pgx v4 ones, such as its pgx.ErrNoRows.
+//
+// Values are decoded with a new pgx v4 pgtype.ConnInfo, which only knows the builtin types, not
+// the types registered with the pgx v5 connection: see WrapConnInfo to scan those.
+func Wrap(r pgx.Rows) pgxv4.Rows {
+	return &rows{rows: r}
+}
+
+// WrapConnInfo works like Wrap, decoding the values with ci, such as a ConnInfo the composite,
+// enum or other user types of the query are registered in. Decoding uses its data types in
+// place, so ci must not be used by several goroutines at once.
+func WrapConnInfo(r pgx.Rows, ci *pgtypev4.ConnInfo) pgxv4.Rows {
+	return &rows{rows: r, ci: ci}
+}
+
+// rows implements the pgx v4 pgx.Rows on top of pgx v5 rows.
+type rows struct {
+	rows              pgx.Rows
+	fieldDescriptions []pgprotov4.FieldDescription
+
+	// ci decodes the values scanned into pgx v4 pgtype decoders, which pgx v5 does not know
+	// about. Data types are decoded in place, so unless set by WrapConnInfo it is not shared
+	// between rows.
+	ci *pgtypev4.ConnInfo
+}
+
+func (r *rows) Close() {
+	r.rows.Close()
+}
+
+func (r *rows) Err() error {
+	return r.rows.Err()
+}
+
+func (r *rows) CommandTag() pgconnv4.CommandTag {
+	return pgconnv4.CommandTag(r.rows.CommandTag().String())
+}
+
+func (r *rows) FieldDescriptions() []pgprotov4.FieldDescription {
+	if r.fieldDescriptions != nil {
+		return r.fieldDescriptions
+	}
+
+	fieldDescriptions := r.rows.FieldDescriptions()
+	r.fieldDescriptions = make([]pgprotov4.FieldDescription, len(fieldDescriptions))
+	for i, fd := range fieldDescriptions {
+		r.fieldDescriptions[i] = pgprotov4.FieldDescription{
+			Name:                 []byte(fd.Name),
+			TableOID:             fd.TableOID,
+			TableAttributeNumber: fd.TableAttributeNumber,
+			DataTypeOID:          fd.DataTypeOID,
+			DataTypeSize:         fd.DataTypeSize,
+			TypeModifier:         fd.TypeModifier,
+			Format:               fd.Format,
+		}
+	}
+	return r.fieldDescriptions
+}
+
+func (r *rows) Next() bool {
+	return r.rows.Next()
+}
+
+// Scan decodes the values scanned into pgx v4 pgtype decoders itself, and leaves the others
+// to pgx v5.
+func (r *rows) Scan(dest ...interface{}) error {
+	fieldDescriptions := r.rows.FieldDescriptions()
+	rawValues := r.rows.RawValues()
+
+	values := make([]interface{}, len(dest))
+	for i, d := range dest {
+		if i >= len(rawValues) {
+			values[i] = d
+			continue
+		}
+
+		var err error
+		switch format := fieldDescriptions[i].Format; {
+		case format == pgx.BinaryFormatCode && isBinaryDecoder(d):
+			err = d.(pgtypev4.BinaryDecoder).DecodeBinary(r.connInfo(), rawValues[i])
+		case format == pgx.TextFormatCode && isTextDecoder(d):
+			err = d.(pgtypev4.TextDecoder).DecodeText(r.connInfo(), rawValues[i])
+		default:
+			values[i] = d
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to scan column %q", fieldDescriptions[i].Name)
+		}
+	}
+
+	return r.rows.Scan(values...)
+}
+
+func (r *rows) Values() ([]interface{}, error) {
+	return r.rows.Values()
+}
+
+func (r *rows) RawValues() [][]byte {
+	return r.rows.RawValues()
+}
+
+func (r *rows) connInfo() *pgtypev4.ConnInfo {
+	if r.ci == nil {
+		r.ci = pgtypev4.NewConnInfo()
+	}
+	return r.ci
+}
+
+func isBinaryDecoder(v interface{}) bool {
+	_, ok := v.(pgtypev4.BinaryDecoder)
+	return ok
+}
+
+func isTextDecoder(v interface{}) bool {
+	_, ok := v.(pgtypev4.TextDecoder)
+	return ok
+}
@@ -0,0 +1,140 @@
+package pgxv5
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	pgtypev4 "github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+type testEntity struct {
+	ID        string        `db:"id"`
+	Note      *string       `db:"note"`
+	Label     pgtypev4.Text `db:"label"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+func TestGetSelect(t *testing.T) {
+	conn := connect(t)
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("TEST_POSTGRES_URI"))
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	for name, querier := range map[string]Querier{"conn": conn, "pool": pool} {
+		t.Run(name, func(t *testing.T) {
+			var result testEntity
+			err := Get(context.Background(), querier, &result, "SELECT * FROM pgxv5_test WHERE id = $1", "pgxv5-1")
+			require.NoError(t, err)
+			assert.Equal(t, "pgxv5-1", result.ID)
+			require.NotNil(t, result.Note)
+			assert.Equal(t, "foo", *result.Note)
+			assert.Equal(t, pgtypev4.Text{String: "bar", Status: pgtypev4.Present}, result.Label)
+			assert.Equal(t, int64(1577836800), result.CreatedAt.Unix())
+
+			var results []*testEntity
+			err = Select(context.Background(), querier, &results, "SELECT * FROM pgxv5_test ORDER BY id ASC")
+			require.NoError(t, err)
+			require.Len(t, results, 2)
+			assert.Equal(t, "pgxv5-1", results[0].ID)
+			assert.Equal(t, "pgxv5-2", results[1].ID)
+			assert.Nil(t, results[1].Note)
+			assert.Equal(t, pgtypev4.Null, results[1].Label.Status)
+
+			// test some fail cases
+			err = Get(context.Background(), querier, &result, "SELECT * FROM pgxv5_test WHERE id = $1", "foo")
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, pgx.ErrNoRows))
+
+			var resultMissing struct {
+				ID string `db:"id"`
+			}
+			err = Get(context.Background(), querier, &resultMissing, "SELECT * FROM pgxv5_test WHERE id = $1", "pgxv5-1")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `missing column "note"`)
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	conn := connect(t)
+
+	rows, err := conn.Query(context.Background(), "SELECT * FROM pgxv5_test ORDER BY id ASC")
+	require.NoError(t, err)
+
+	scanner := pgxscan.New(pgxscan.WithTimeLocation(time.UTC))
+
+	var results []testEntity
+	err = scanner.ScanStructs(Wrap(rows), &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, time.UTC, results[0].CreatedAt.Location())
+	assert.Equal(t, "bar", results[0].Label.String)
+
+	// user types are decoded with the ConnInfo they are registered in
+	_, err = conn.Exec(context.Background(), `
+		DROP TYPE IF EXISTS pgxv5_mood;
+		CREATE TYPE pgxv5_mood AS ENUM ('happy', 'sad');
+	`)
+	require.NoError(t, err)
+	var oid uint32
+	err = conn.QueryRow(context.Background(), "SELECT 'pgxv5_mood'::regtype::oid").Scan(&oid)
+	require.NoError(t, err)
+
+	ci := pgtypev4.NewConnInfo()
+	ci.RegisterDataType(pgtypev4.DataType{Value: pgtypev4.NewEnumType("pgxv5_mood", []string{"happy", "sad"}), Name: "pgxv5_mood", OID: oid})
+
+	rows, err = conn.Query(context.Background(), "SELECT 'sad'::pgxv5_mood AS mood")
+	require.NoError(t, err)
+	var mood struct {
+		Mood string `db:"mood"`
+	}
+	err = scanner.ScanStruct(WrapConnInfo(rows, ci), &mood)
+	require.NoError(t, err)
+	assert.Equal(t, "sad", mood.Mood)
+}
+
+func connect(t *testing.T) *pgx.Conn {
+	t.Helper()
+
+	connString := os.Getenv("TEST_POSTGRES_URI")
+	require.NotEmpty(t, connString)
+
+	conn, err := pgx.Connect(context.Background(), connString)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := conn.Close(context.Background())
+		assert.NoError(t, err)
+	})
+
+	_, err = conn.Exec(context.Background(), `DROP TABLE IF EXISTS pgxv5_test`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `
+		CREATE TABLE pgxv5_test (
+			id         text PRIMARY KEY,
+			note       text,
+			label      text,
+			created_at timestamptz not null
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `
+		INSERT INTO pgxv5_test (id, note, label, created_at) VALUES
+			('pgxv5-1', 'foo', 'bar', '2020-01-01 00:00:00+00'),
+			('pgxv5-2', NULL, NULL, '2020-01-02 00:00:00+00')
+	`)
+	require.NoError(t, err)
+
+	return conn
+}
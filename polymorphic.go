@@ -0,0 +1,149 @@
+package pgxscan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// discriminator describes the rows scanned into an interface, each into the concrete type
+// picked by the value of a discriminator column.
+type discriminator struct {
+	column   string
+	registry map[string]reflect.Type
+}
+
+// WithDiscriminator makes ScanStructs, Select and the functions using them scan into a slice
+// of the interface T, such as []Document, each row into a new value of the type registered in
+// registry for the value of column in the row, such as "invoice" or "receipt".
+//
+// A registered type is appended as a value if it implements T, and as a pointer otherwise.
+// The columns without a field in the type of a row are skipped, since the rows of all types
+// share the columns of the result. A NULL or unregistered discriminator fails the scan.
+func WithDiscriminator[T any](column string, registry map[string]reflect.Type) Option {
+	return func(s *Scanner) {
+		s.discriminators[reflect.TypeOf((*T)(nil)).Elem()] = discriminator{column: column, registry: registry}
+	}
+}
+
+// scanPolymorphic scans the rows of r into sliceVal, a slice of an interface registered with
+// WithDiscriminator.
+func (s *Scanner) scanPolymorphic(ctx context.Context, r pgx.Rows, sliceVal reflect.Value) error {
+	ifaceType := sliceVal.Type().Elem()
+	d, ok := s.discriminators[ifaceType]
+	if !ok {
+		return errors.Errorf("no discriminator registered for %s", ifaceType)
+	}
+
+	var (
+		column  = -1
+		raws    []interface{}
+		groups  = make(map[reflect.Type]*nestedGroup)
+		results = reflect.MakeSlice(sliceVal.Type(), 0, s.capacity)
+	)
+	for r.Next() {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "scan aborted")
+		}
+		if s.maxRows > 0 && results.Len() >= s.maxRows {
+			return ErrTooManyRows
+		}
+
+		fieldDescriptions := r.FieldDescriptions()
+		if raws == nil {
+			for i, fieldDescription := range fieldDescriptions {
+				if string(fieldDescription.Name) == d.column {
+					column = i
+				}
+			}
+			if column < 0 {
+				return errors.Errorf("missing discriminator column %q", d.column)
+			}
+			raws = make([]interface{}, len(fieldDescriptions))
+			for i := range raws {
+				raws[i] = new(rawValue)
+			}
+		}
+
+		if err := r.Scan(raws...); err != nil {
+			return &ScanError{Row: results.Len(), Err: err}
+		}
+
+		kind, err := discriminatorKey(raws[column].(*rawValue), fieldDescriptions[column].DataTypeOID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scan discriminator column %q", d.column)
+		}
+		if kind == nil {
+			return errors.Errorf("discriminator column %q is NULL", d.column)
+		}
+		t, ok := d.registry[*kind]
+		if !ok {
+			return errors.Errorf("no type registered for %s %q", d.column, *kind)
+		}
+
+		v := reflect.New(t)
+		g, ok := groups[t]
+		if !ok {
+			// called on the first struct of each type
+			if hook, ok := v.Interface().(BeforeScanner); ok {
+				if err := hook.BeforeScan(fieldDescriptions); err != nil {
+					return errors.Wrap(err, "BeforeScan failed")
+				}
+			}
+			g = &nestedGroup{elemType: t}
+			for i, fieldDescription := range fieldDescriptions {
+				g.indexes = append(g.indexes, i)
+				g.columns = append(g.columns, string(fieldDescription.Name))
+				g.oids = append(g.oids, fieldDescription.DataTypeOID)
+			}
+			g.traversals = s.traversalsByName(t, g.columns)
+			groups[t] = g
+		}
+
+		if err := s.decodeNested(g, results.Len(), v, raws); err != nil {
+			return err
+		}
+		if err := afterScanHook(ctx, v); err != nil {
+			return err
+		}
+
+		switch {
+		case t.Implements(ifaceType):
+			results = reflect.Append(results, v.Elem())
+		case v.Type().Implements(ifaceType):
+			results = reflect.Append(results, v)
+		default:
+			return errors.Errorf("%s registered for %s %q does not implement %s", t, d.column, *kind, ifaceType)
+		}
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	sliceVal.Set(results)
+	return nil
+}
+
+// discriminatorKey returns the registry key of raw, the value of the discriminator column of
+// type oid, or nil if it is NULL. Values in text format, such as the ones of the enums pgx
+// doesn't know, are used as is, and the others are decoded and formatted.
+func discriminatorKey(raw *rawValue, oid uint32) (*string, error) {
+	if raw.src == nil {
+		return nil, nil
+	}
+	if raw.format == pgtype.TextFormatCode {
+		key := string(raw.src)
+		return &key, nil
+	}
+
+	var value interface{}
+	if err := raw.scan(oid, &value); err != nil {
+		return nil, err
+	}
+	key := fmt.Sprint(value)
+	return &key, nil
+}
@@ -0,0 +1,158 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDocument interface {
+	documentID() string
+}
+
+type testInvoice struct {
+	ID    string `db:"id"`
+	Kind  string `db:"kind"`
+	Total int    `db:"total"`
+}
+
+func (i testInvoice) documentID() string {
+	return i.ID
+}
+
+type testReceipt struct {
+	ID     string  `db:"id"`
+	Kind   string  `db:"kind"`
+	PaidBy *string `db:"paid_by"`
+}
+
+func (r *testReceipt) documentID() string {
+	return r.ID
+}
+
+// testNote records the number of columns of the result in BeforeScan.
+type testNote struct {
+	ID      string `db:"id"`
+	Kind    string `db:"kind"`
+	Columns int    `db:"-"`
+}
+
+func (n *testNote) documentID() string {
+	return n.ID
+}
+
+func (n *testNote) BeforeScan(fields []pgproto3.FieldDescription) error {
+	n.Columns = len(fields)
+	return nil
+}
+
+var testDocumentTypes = map[string]reflect.Type{
+	"invoice": reflect.TypeOf(testInvoice{}),
+	"receipt": reflect.TypeOf(testReceipt{}),
+}
+
+func TestScannerDiscriminator(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "discriminator_test", `
+		id      text PRIMARY KEY,
+		kind    text,
+		total   int,
+		paid_by text
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO discriminator_test (id, kind, total, paid_by) VALUES
+			('doc-1', 'invoice', 42, NULL),
+			('doc-2', 'receipt', NULL, 'card'),
+			('doc-3', 'invoice', 7, NULL)
+	`)
+	require.NoError(t, err)
+
+	scanner := New(WithDiscriminator[testDocument]("kind", testDocumentTypes))
+
+	var result []testDocument
+	err = scanner.Select(context.Background(), conn, &result, "SELECT * FROM discriminator_test ORDER BY id ASC")
+	require.NoError(t, err)
+	card := "card"
+	assert.Equal(t, []testDocument{
+		testInvoice{ID: "doc-1", Kind: "invoice", Total: 42},
+		&testReceipt{ID: "doc-2", Kind: "receipt", PaidBy: &card},
+		testInvoice{ID: "doc-3", Kind: "invoice", Total: 7},
+	}, result)
+
+	// enum discriminators, unknown to pgx, are matched by their text
+	_, err = conn.Exec(context.Background(), `
+		DROP TYPE IF EXISTS discriminator_kind;
+		CREATE TYPE discriminator_kind AS ENUM ('invoice', 'receipt');
+	`)
+	require.NoError(t, err)
+	err = scanner.Select(context.Background(), conn, &result,
+		"SELECT id, kind::discriminator_kind AS kind, total, paid_by FROM discriminator_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, &testReceipt{ID: "doc-2", Kind: "receipt", PaidBy: &card}, result[1])
+
+	// test some fail cases
+	err = scanner.Select(context.Background(), conn, &result, "SELECT id, total FROM discriminator_test")
+	require.Error(t, err)
+	assert.Equal(t, `missing discriminator column "kind"`, err.Error())
+
+	err = scanner.Select(context.Background(), conn, &result, "SELECT 'doc-4' AS id, 'memo' AS kind")
+	require.Error(t, err)
+	assert.Equal(t, `no type registered for kind "memo"`, err.Error())
+
+	err = scanner.Select(context.Background(), conn, &result, "SELECT 'doc-5' AS id, NULL::text AS kind")
+	require.Error(t, err)
+	assert.Equal(t, `discriminator column "kind" is NULL`, err.Error())
+
+	err = Select(context.Background(), conn, &result, "SELECT * FROM discriminator_test")
+	require.Error(t, err)
+	assert.Equal(t, `no discriminator registered for pgxscan.testDocument`, err.Error())
+}
+
+func TestScanStructsDiscriminatorFakeRows(t *testing.T) {
+	rows := newFakeRows(0)
+	rows.fields = []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("kind"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("total"), DataTypeOID: pgtype.Int4OID},
+		{Name: []byte("paid_by"), DataTypeOID: pgtype.TextOID},
+	}
+	rows.rows = [][][]byte{
+		{[]byte("doc-1"), []byte("receipt"), nil, nil},
+		{[]byte("doc-2"), []byte("invoice"), []byte("42"), nil},
+	}
+	// an unregistered enum
+	rows.fields[1].DataTypeOID = 20000
+
+	var result []testDocument
+	err := New(WithDiscriminator[testDocument]("kind", testDocumentTypes)).ScanStructs(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, []testDocument{
+		&testReceipt{ID: "doc-1", Kind: "receipt"},
+		testInvoice{ID: "doc-2", Kind: "invoice", Total: 42},
+	}, result)
+
+	// BeforeScan is called on the first struct of each type
+	rows = newFakeRows(0)
+	rows.fields = []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+		{Name: []byte("kind"), DataTypeOID: pgtype.TextOID},
+	}
+	rows.rows = [][][]byte{
+		{[]byte("doc-1"), []byte("note")},
+		{[]byte("doc-2"), []byte("note")},
+	}
+	types := map[string]reflect.Type{"note": reflect.TypeOf(testNote{})}
+	err = New(WithDiscriminator[testDocument]("kind", types)).ScanStructs(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, []testDocument{
+		&testNote{ID: "doc-1", Kind: "note", Columns: 2},
+		&testNote{ID: "doc-2", Kind: "note"},
+	}, result)
+}
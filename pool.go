@@ -0,0 +1,94 @@
+package pgxscan
+
+import (
+	"context"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Querier is implemented by the connection pool and its connections.
+var (
+	_ Querier = (*pgxpool.Pool)(nil)
+	_ Querier = (*pgxpool.Conn)(nil)
+)
+
+// GetAcquired runs Get with a connection acquired from pool, released once the row is scanned
+// whatever happens, instead of when the rows are closed.
+func GetAcquired(ctx context.Context, pool *pgxpool.Pool, dest interface{}, query string, args ...interface{}) error {
+	return AcquireFunc(ctx, pool, func(conn Querier) error {
+		return Get(ctx, conn, dest, query, args...)
+	})
+}
+
+// SelectAcquired runs Select with a connection acquired from pool, see GetAcquired.
+func SelectAcquired(ctx context.Context, pool *pgxpool.Pool, dest interface{}, query string, args ...interface{}) error {
+	return AcquireFunc(ctx, pool, func(conn Querier) error {
+		return Select(ctx, conn, dest, query, args...)
+	})
+}
+
+// AcquireFunc calls fn with a connection acquired from pool and releases it once fn returns.
+// The rows fn leaves open are closed first, as the pool destroys a connection released busy
+// instead of taking it back.
+func AcquireFunc(ctx context.Context, pool *pgxpool.Pool, fn func(conn Querier) error) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	q := &acquiredQuerier{Conn: conn}
+	defer func() {
+		for _, rows := range q.rows {
+			rows.Close()
+		}
+		conn.Release()
+	}()
+
+	return fn(q)
+}
+
+// acquiredQuerier records the rows of the queries run with the acquired Conn, to close them
+// before releasing it.
+type acquiredQuerier struct {
+	*pgxpool.Conn
+	rows []pgx.Rows
+}
+
+func (q *acquiredQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := q.Conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	q.rows = append(q.rows, rows)
+	return rows, nil
+}
+
+func (q *acquiredQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := q.Query(ctx, sql, args...)
+	return &rowsRow{rows: rows, err: err}
+}
+
+// rowsRow is the pgx.Row of the first row of rows, or of err.
+type rowsRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *rowsRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
@@ -0,0 +1,57 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquired(t *testing.T) {
+	conn := connect(t)
+	e1, e2 := prepareData(t, conn)
+
+	pool, err := pgxpool.Connect(context.Background(), initDB(t))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var result testEntity
+	err = GetAcquired(context.Background(), pool, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+
+	var results []*testEntity
+	err = SelectAcquired(context.Background(), pool, &results, "SELECT * FROM structscan_test WHERE id IN ($1, $2) ORDER BY id ASC", e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, e2.ID, results[1].ID)
+
+	// connections are released even if rows are left open, and kept by the pool
+	total := pool.Stat().TotalConns()
+	err = AcquireFunc(context.Background(), pool, func(conn Querier) error {
+		_, err := conn.Query(context.Background(), "SELECT * FROM structscan_test")
+		return err
+	})
+	require.NoError(t, err)
+	assert.Zero(t, pool.Stat().AcquiredConns())
+	assert.Equal(t, total, pool.Stat().TotalConns())
+
+	err = AcquireFunc(context.Background(), pool, func(conn Querier) error {
+		var id string
+		return conn.QueryRow(context.Background(), "SELECT id FROM structscan_test WHERE id = $1", e1.ID).Scan(&id)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, total, pool.Stat().TotalConns())
+
+	// the pool is a Querier itself
+	err = Get(context.Background(), pool, &result, "SELECT * FROM structscan_test WHERE id = $1", e2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e2.ID, result.ID)
+
+	// test some fail cases
+	err = GetAcquired(context.Background(), pool, &result, "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	require.Error(t, err)
+	assert.Zero(t, pool.Stat().AcquiredConns())
+}
@@ -0,0 +1,99 @@
+// Package prompgxscan records metrics of the queries of a pgxscan.Querier with Prometheus.
+package prompgxscan
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+// Metrics is a pgxscan.MetricsHook recording the queries, their duration including the time
+// spent scanning their rows, the rows read and the errors, by Querier method.
+// It is a prometheus.Collector to register, for instance with prometheus.MustRegister.
+type Metrics struct {
+	queries  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	rows     *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+}
+
+var _ pgxscan.MetricsHook = (*Metrics)(nil)
+
+// NewMetrics returns Metrics named within namespace, such as "myapp_pgxscan_queries_total".
+func NewMetrics(namespace string) *Metrics {
+	return &Metrics{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pgxscan_queries_total",
+			Help:      "Number of queries run.",
+		}, []string{"method"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "pgxscan_query_duration_seconds",
+			Help:      "Duration of queries, including the time spent scanning their rows.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		rows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pgxscan_rows_total",
+			Help:      "Number of rows read, or affected by Exec.",
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pgxscan_errors_total",
+			Help:      "Number of failed queries, by type of error.",
+		}, []string{"method", "type"}),
+	}
+}
+
+// Middleware returns a pgxscan.Middleware recording the queries with m.
+func (m *Metrics) Middleware() pgxscan.Middleware {
+	return pgxscan.Metrics(m)
+}
+
+// ObserveQuery records event.
+func (m *Metrics) ObserveQuery(ctx context.Context, event pgxscan.QueryEvent) {
+	m.queries.WithLabelValues(event.Method).Inc()
+	m.duration.WithLabelValues(event.Method).Observe(event.Duration.Seconds())
+	m.rows.WithLabelValues(event.Method).Add(float64(event.Rows))
+	if event.Err != nil {
+		m.errors.WithLabelValues(event.Method, ErrorType(event.Err)).Inc()
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.queries.Describe(ch)
+	m.duration.Describe(ch)
+	m.rows.Describe(ch)
+	m.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.queries.Collect(ch)
+	m.duration.Collect(ch)
+	m.rows.Collect(ch)
+	m.errors.Collect(ch)
+}
+
+// ErrorType returns the type of err used as label: the SQLSTATE class of errors returned
+// by the server, such as "class_23" for integrity constraint violations, "canceled" and
+// "timeout" for the errors of contexts, and "other" otherwise.
+func ErrorType(err error) string {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &pgErr) && len(pgErr.Code) == 5:
+		return "class_" + pgErr.Code[:2]
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err):
+		return "timeout"
+	default:
+		return "other"
+	}
+}
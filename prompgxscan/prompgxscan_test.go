@@ -0,0 +1,92 @@
+package prompgxscan
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+type testEntity struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestMetrics(t *testing.T) {
+	conn := connect(t)
+
+	m := NewMetrics("test")
+	q := pgxscan.Wrap(conn, m.Middleware())
+
+	var results []*testEntity
+	err := pgxscan.Select(context.Background(), q, &results, "SELECT * FROM prompgxscan_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	_, err = q.Exec(context.Background(), "UPDATE prompgxscan_test SET name = name")
+	require.NoError(t, err)
+
+	// test some fail cases
+	var result testEntity
+	err = pgxscan.Get(context.Background(), q, &result, "SELECT * FROM prompgxscan_test_missing")
+	require.Error(t, err)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.queries.WithLabelValues("Query")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.queries.WithLabelValues("Exec")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.rows.WithLabelValues("Query")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.rows.WithLabelValues("Exec")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.errors.WithLabelValues("Query", "class_42")))
+
+	err = testutil.CollectAndCompare(m, strings.NewReader(`
+# HELP test_pgxscan_errors_total Number of failed queries, by type of error.
+# TYPE test_pgxscan_errors_total counter
+test_pgxscan_errors_total{method="Query",type="class_42"} 1
+`), "test_pgxscan_errors_total")
+	require.NoError(t, err)
+}
+
+func TestErrorType(t *testing.T) {
+	assert.Equal(t, "class_23", ErrorType(errors.Wrap(&pgconn.PgError{Code: "23505"}, "failed")))
+	assert.Equal(t, "canceled", ErrorType(context.Canceled))
+	assert.Equal(t, "timeout", ErrorType(errors.Wrap(context.DeadlineExceeded, "failed")))
+	assert.Equal(t, "other", ErrorType(errors.New("foo")))
+}
+
+func connect(t *testing.T) *pgx.Conn {
+	t.Helper()
+
+	connString := os.Getenv("TEST_POSTGRES_URI")
+	require.NotEmpty(t, connString)
+
+	conn, err := pgx.Connect(context.Background(), connString)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := conn.Close(context.Background())
+		assert.NoError(t, err)
+	})
+
+	_, err = conn.Exec(context.Background(), `DROP TABLE IF EXISTS prompgxscan_test`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `
+		CREATE TABLE prompgxscan_test (
+			id   text PRIMARY KEY,
+			name text not null
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `INSERT INTO prompgxscan_test (id, name) VALUES ('a', 'foo'), ('b', 'bar')`)
+	require.NoError(t, err)
+
+	return conn
+}
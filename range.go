@@ -0,0 +1,211 @@
+package pgxscan
+
+import (
+	"encoding/binary"
+	"reflect"
+
+	"github.com/jackc/pgtype"
+	"github.com/pkg/errors"
+)
+
+// Range is a Postgres range with bounds of type T. LowerType and UpperType tell whether each
+// bound is pgtype.Inclusive, pgtype.Exclusive or pgtype.Unbounded, and are pgtype.Empty
+// for an empty range. The bounds of unbounded and empty ranges are the zero T.
+//
+// Range columns scan into Range[T] fields, such as Range[int32] for int4range or Range[time.Time]
+// for tstzrange and daterange, and pointers to them, which NULL sets to nil.
+// Multirange columns scan into []Range[T] fields, such as []Range[int32] for int4multirange
+// or []Range[time.Time] for tstzmultirange.
+type Range[T any] struct {
+	Lower     T
+	Upper     T
+	LowerType pgtype.BoundType
+	UpperType pgtype.BoundType
+}
+
+// decodeRange sets r from src, the range encoded in format, decoding its bounds as elemOID values.
+func (r *Range[T]) decodeRange(ci *pgtype.ConnInfo, elemOID uint32, format int16, src []byte) error {
+	*r = Range[T]{}
+
+	var lower, upper []byte
+	if format == pgtype.TextFormatCode {
+		untyped, err := pgtype.ParseUntypedTextRange(string(src))
+		if err != nil {
+			return err
+		}
+		r.LowerType, r.UpperType = untyped.LowerType, untyped.UpperType
+		lower, upper = []byte(untyped.Lower), []byte(untyped.Upper)
+	} else {
+		untyped, err := pgtype.ParseUntypedBinaryRange(src)
+		if err != nil {
+			return err
+		}
+		r.LowerType, r.UpperType = untyped.LowerType, untyped.UpperType
+		lower, upper = untyped.Lower, untyped.Upper
+	}
+
+	if hasBound(r.LowerType) {
+		if err := ci.Scan(elemOID, format, lower, &r.Lower); err != nil {
+			return errors.Wrap(err, "failed to decode the lower bound")
+		}
+	}
+	if hasBound(r.UpperType) {
+		if err := ci.Scan(elemOID, format, upper, &r.Upper); err != nil {
+			return errors.Wrap(err, "failed to decode the upper bound")
+		}
+	}
+	return nil
+}
+
+func hasBound(t pgtype.BoundType) bool {
+	return t == pgtype.Inclusive || t == pgtype.Exclusive
+}
+
+// rangeDecoder is implemented by *Range[T] for any T.
+type rangeDecoder interface {
+	decodeRange(ci *pgtype.ConnInfo, elemOID uint32, format int16, src []byte) error
+}
+
+var rangeDecoderType = reflect.TypeOf((*rangeDecoder)(nil)).Elem()
+
+// rangeElemOIDs maps the OIDs of the built-in range types to the OIDs of their elements.
+var rangeElemOIDs = map[uint32]uint32{
+	pgtype.Int4rangeOID: pgtype.Int4OID,
+	pgtype.Int8rangeOID: pgtype.Int8OID,
+	pgtype.NumrangeOID:  pgtype.NumericOID,
+	pgtype.TsrangeOID:   pgtype.TimestampOID,
+	pgtype.TstzrangeOID: pgtype.TimestamptzOID,
+	pgtype.DaterangeOID: pgtype.DateOID,
+}
+
+// multirangeElemOIDs maps the OIDs of the built-in multirange types to the OIDs of their
+// elements. pgtype predates multiranges, so their values are decoded here.
+var multirangeElemOIDs = map[uint32]uint32{
+	4451: pgtype.Int4OID,        // int4multirange
+	4532: pgtype.NumericOID,     // nummultirange
+	4533: pgtype.TimestampOID,   // tsmultirange
+	4534: pgtype.TimestamptzOID, // tstzmultirange
+	4535: pgtype.DateOID,        // datemultirange
+	4536: pgtype.Int8OID,        // int8multirange
+}
+
+// rangeTarget returns the scan destination of the field f and the conversion decoding it,
+// if f is a Range, or a pointer to one, and oid a range type, or f is a slice of Range and
+// oid a multirange type.
+func rangeTarget(f reflect.Value, oid uint32) (interface{}, func() error, bool) {
+	if elemOID, ok := rangeElemOIDs[oid]; ok {
+		t := f.Type()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if !reflect.PtrTo(t).Implements(rangeDecoderType) {
+			return nil, nil, false
+		}
+
+		raw := new(rawValue)
+		return raw, func() error {
+			if raw.src == nil {
+				return setNull(f)
+			}
+			r := reflect.New(t)
+			if err := r.Interface().(rangeDecoder).decodeRange(raw.ci, elemOID, raw.format, raw.src); err != nil {
+				return err
+			}
+			setValue(f, r.Elem())
+			return nil
+		}, true
+	}
+
+	elemOID, ok := multirangeElemOIDs[oid]
+	if !ok || f.Kind() != reflect.Slice || !reflect.PtrTo(f.Type().Elem()).Implements(rangeDecoderType) {
+		return nil, nil, false
+	}
+
+	raw := new(rawValue)
+	return raw, func() error {
+		return decodeMultirange(f, elemOID, raw)
+	}, true
+}
+
+// decodeMultirange sets the slice dst to the ranges of the multirange raw. NULL makes dst nil,
+// while an empty multirange makes it an empty slice.
+func decodeMultirange(dst reflect.Value, elemOID uint32, raw *rawValue) error {
+	if raw.src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	var ranges [][]byte
+	var err error
+	if raw.format == pgtype.TextFormatCode {
+		ranges, err = splitTextMultirange(raw.src)
+	} else {
+		ranges, err = splitBinaryMultirange(raw.src)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := reflect.MakeSlice(dst.Type(), len(ranges), len(ranges))
+	for i, src := range ranges {
+		r := result.Index(i).Addr().Interface().(rangeDecoder)
+		if err := r.decodeRange(raw.ci, elemOID, raw.format, src); err != nil {
+			return errors.Wrapf(err, "failed to decode range %d", i)
+		}
+	}
+	dst.Set(result)
+	return nil
+}
+
+// splitTextMultirange splits a multirange in text format, such as {[1,3),[5,7)}, into its ranges.
+func splitTextMultirange(src []byte) ([][]byte, error) {
+	if len(src) < 2 || src[0] != '{' || src[len(src)-1] != '}' {
+		return nil, errors.Errorf("invalid multirange %q", src)
+	}
+	src = src[1 : len(src)-1]
+
+	ranges := [][]byte{}
+	start, quoted := 0, false
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case (c == ')' || c == ']') && !quoted:
+			ranges = append(ranges, src[start:i+1])
+			// skip the comma separating ranges
+			start = i + 2
+		}
+	}
+	return ranges, nil
+}
+
+// splitBinaryMultirange splits a multirange in binary format, a range count followed by
+// length prefixed ranges, into its ranges.
+func splitBinaryMultirange(src []byte) ([][]byte, error) {
+	if len(src) < 4 {
+		return nil, errors.Errorf("invalid multirange length %d", len(src))
+	}
+	count := int(int32(binary.BigEndian.Uint32(src)))
+	src = src[4:]
+	// each range takes at least its 4 bytes of length
+	if count < 0 || count > len(src)/4 {
+		return nil, errors.Errorf("invalid multirange count %d", count)
+	}
+
+	ranges := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		if len(src) < 4 {
+			return nil, errors.Errorf("invalid multirange: range %d is truncated", i)
+		}
+		n := int(int32(binary.BigEndian.Uint32(src)))
+		src = src[4:]
+		if n < 0 || len(src) < n {
+			return nil, errors.Errorf("invalid multirange: range %d is truncated", i)
+		}
+		ranges = append(ranges, src[:n])
+		src = src[n:]
+	}
+	return ranges, nil
+}
@@ -0,0 +1,172 @@
+package pgxscan
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMultirangeEntity struct {
+	ID      string             `db:"id"`
+	Slots   []Range[int32]     `db:"slots"`
+	Periods []Range[time.Time] `db:"periods"`
+}
+
+func TestScanStructsMultirange(t *testing.T) {
+	conn := connect(t)
+
+	var version string
+	err := conn.QueryRow(context.Background(), "SHOW server_version_num").Scan(&version)
+	require.NoError(t, err)
+	if n, _ := strconv.Atoi(version); n < 140000 {
+		t.Skipf("multiranges require Postgres 14, got server version %s", version)
+	}
+
+	createTable(t, conn, "multirange_test", `
+		id      text PRIMARY KEY,
+		slots   int4multirange,
+		periods tstzmultirange
+	`)
+	_, err = conn.Exec(context.Background(), `
+		INSERT INTO multirange_test (id, slots, periods) VALUES
+			('multirange-1', '{[1,3), [5,7]}', '{["2020-01-01 00:00:00+00","2020-01-02 00:00:00+00"), ["2020-02-01 00:00:00+00",)}'),
+			('multirange-2', '{}', '{}'),
+			('multirange-3', NULL, NULL)
+	`)
+	require.NoError(t, err)
+
+	var result []*testMultirangeEntity
+	err = Select(context.Background(), conn, &result, "SELECT * FROM multirange_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	// inclusive upper bounds of discrete ranges are normalized to exclusive ones
+	assert.Equal(t, []Range[int32]{
+		{Lower: 1, Upper: 3, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive},
+		{Lower: 5, Upper: 8, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive},
+	}, result[0].Slots)
+	require.Len(t, result[0].Periods, 2)
+	assert.True(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Equal(result[0].Periods[0].Lower))
+	assert.True(t, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Equal(result[0].Periods[0].Upper))
+	assert.True(t, time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC).Equal(result[0].Periods[1].Lower))
+	assert.Equal(t, pgtype.Unbounded, result[0].Periods[1].UpperType)
+	assert.True(t, result[0].Periods[1].Upper.IsZero())
+
+	assert.NotNil(t, result[1].Slots)
+	assert.Empty(t, result[1].Slots)
+	assert.NotNil(t, result[1].Periods)
+	assert.Empty(t, result[1].Periods)
+
+	assert.Nil(t, result[2].Slots)
+	assert.Nil(t, result[2].Periods)
+}
+
+type testRangeEntity struct {
+	ID     string            `db:"id"`
+	Seats  Range[int32]      `db:"seats"`
+	During *Range[time.Time] `db:"during"`
+	Days   *Range[time.Time] `db:"days"`
+	Size   Range[int64]      `db:"size"`
+}
+
+func TestScanStructsRange(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "range_test", `
+		id     text PRIMARY KEY,
+		seats  int4range NOT NULL,
+		during tstzrange,
+		days   daterange,
+		size   int8range NOT NULL
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO range_test (id, seats, during, days, size) VALUES
+			('range-1', '[1,10]', '["2020-01-01 00:00:00+00","2020-01-02 00:00:00+00")', '[2020-01-01,)', '(,100)'),
+			('range-2', 'empty', NULL, NULL, '[0,0]')
+	`)
+	require.NoError(t, err)
+
+	var result []testRangeEntity
+	err = Select(context.Background(), conn, &result, "SELECT * FROM range_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, Range[int32]{Lower: 1, Upper: 11, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive}, result[0].Seats)
+	require.NotNil(t, result[0].During)
+	assert.True(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Equal(result[0].During.Lower))
+	assert.True(t, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Equal(result[0].During.Upper))
+	require.NotNil(t, result[0].Days)
+	assert.True(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Equal(result[0].Days.Lower))
+	assert.Equal(t, pgtype.Unbounded, result[0].Days.UpperType)
+	assert.Equal(t, Range[int64]{Upper: 100, LowerType: pgtype.Unbounded, UpperType: pgtype.Exclusive}, result[0].Size)
+
+	assert.Equal(t, Range[int32]{LowerType: pgtype.Empty, UpperType: pgtype.Empty}, result[1].Seats)
+	assert.Nil(t, result[1].During)
+	assert.Nil(t, result[1].Days)
+
+	// test some fail cases
+	var null testRangeEntity
+	err = Get(context.Background(), conn, &null, "SELECT 'range-3' AS id, NULL::int4range AS seats, NULL::tstzrange AS during, NULL::daterange AS days, 'empty'::int8range AS size")
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "seats" (oid 3904) of row 0 into field Seats of type pgxscan.Range[int32]: cannot scan NULL into pgxscan.Range[int32]`, err.Error())
+}
+
+func TestScanStructRangeText(t *testing.T) {
+	type testRangeData struct {
+		ID        string       `db:"id"`
+		CreatedAt string       `db:"created_at"`
+		SomeData  Range[int32] `db:"some_data"`
+	}
+
+	rows := newFakeRows(1)
+	rows.fields[1].DataTypeOID = pgtype.TextOID
+	rows.fields[2].DataTypeOID = pgtype.Int4rangeOID
+	rows.rows[0][2] = []byte("[3,7)")
+
+	var result testRangeData
+	err := ScanStruct(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, Range[int32]{Lower: 3, Upper: 7, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive}, result.SomeData)
+}
+
+func TestSplitTextMultirange(t *testing.T) {
+	ranges, err := splitTextMultirange([]byte(`{[1,3),(5,7]}`))
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`[1,3)`), []byte(`(5,7]`)}, ranges)
+
+	ranges, err = splitTextMultirange([]byte(`{["a)","b]"),[c,)}`))
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`["a)","b]")`), []byte(`[c,)`)}, ranges)
+
+	ranges, err = splitTextMultirange([]byte(`{}`))
+	require.NoError(t, err)
+	assert.Empty(t, ranges)
+
+	_, err = splitTextMultirange([]byte(`[1,3)`))
+	require.Error(t, err)
+}
+
+func TestSplitBinaryMultirange(t *testing.T) {
+	ranges, err := splitBinaryMultirange([]byte{0, 0, 0, 2, 0, 0, 0, 1, 'a', 0, 0, 0, 0})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), {}}, ranges)
+
+	// test some fail cases
+	_, err = splitBinaryMultirange([]byte{0xff, 0xff, 0xff, 0xff})
+	require.Error(t, err)
+	assert.Equal(t, "invalid multirange count -1", err.Error())
+
+	_, err = splitBinaryMultirange([]byte{0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+	require.Error(t, err)
+
+	_, err = splitBinaryMultirange([]byte{0, 0, 0, 1, 0, 0, 0, 5, 'a'})
+	require.Error(t, err)
+
+	_, err = splitBinaryMultirange([]byte{0, 0, 0, 1, 0xff, 0xff, 0xff, 0xff})
+	require.Error(t, err)
+}
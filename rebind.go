@@ -0,0 +1,87 @@
+package pgxscan
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Rebind rewrites the ? parameters of query into $1..$N placeholders, to run queries written
+// for sqlx or generated by query builders with their default placeholder format.
+//
+// String literals, quoted identifiers and comments are left untouched, and a literal question
+// mark, such as the jsonb ?, ?| and ?& operators, is escaped by doubling it, like for In: ??,
+// ??| and ??& are rewritten into ?, ?| and ?&.
+func Rebind(query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+
+	for i := 0; i < len(query); i++ {
+		if end := skipEnd(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+
+		if query[i] != '?' {
+			b.WriteByte(query[i])
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == '?' {
+			b.WriteByte('?')
+			i++
+			continue
+		}
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return b.String()
+}
+
+// RebindQuestion rewrites the $1..$N placeholders of query into ? parameters, the reverse of
+// Rebind, for drivers and tools expecting them. It fails if the placeholders are not numbered
+// in order, each used once, which ? parameters can't express. Literal question marks are
+// escaped into ??, as expected by Rebind.
+func RebindQuestion(query string) (string, error) {
+	var (
+		b strings.Builder
+		n int
+	)
+
+	for i := 0; i < len(query); i++ {
+		if end := skipEnd(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+
+		if query[i] == '?' {
+			b.WriteString("??")
+			continue
+		}
+		if query[i] != '$' || i+1 >= len(query) || query[i+1] < '0' || query[i+1] > '9' {
+			b.WriteByte(query[i])
+			continue
+		}
+		end := i + 1
+		for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+			end++
+		}
+		index, err := strconv.Atoi(query[i+1 : end])
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid placeholder %s", query[i:end])
+		}
+		n++
+		if index != n {
+			return "", errors.Errorf("placeholder %s found where $%d was expected", query[i:end], n)
+		}
+		b.WriteByte('?')
+		i = end - 1
+	}
+
+	return b.String(), nil
+}
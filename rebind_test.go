@@ -0,0 +1,47 @@
+package pgxscan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebind(t *testing.T) {
+	query := Rebind("SELECT * FROM t WHERE a = ? AND b IN (?, ?)")
+	assert.Equal(t, "SELECT * FROM t WHERE a = $1 AND b IN ($2, $3)", query)
+
+	query = Rebind("SELECT '?', \"?\" /* ? */ -- ?\nFROM t WHERE b = ?")
+	assert.Equal(t, "SELECT '?', \"?\" /* ? */ -- ?\nFROM t WHERE b = $1", query)
+
+	query = Rebind("SELECT * FROM t WHERE data ?? 'a' AND tags ??| ? AND tags ??& ? AND id = ?")
+	assert.Equal(t, "SELECT * FROM t WHERE data ? 'a' AND tags ?| $1 AND tags ?& $2 AND id = $3", query)
+
+	assert.Equal(t, "SELECT 1", Rebind("SELECT 1"))
+}
+
+func TestRebindQuestion(t *testing.T) {
+	query, err := RebindQuestion("SELECT * FROM t WHERE a = $1 AND b IN ($2, $3) LIMIT $4")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = ? AND b IN (?, ?) LIMIT ?", query)
+
+	query, err = RebindQuestion("SELECT '$1', $$body$$ /* $1 */ FROM t WHERE b = $1")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT '$1', $$body$$ /* $1 */ FROM t WHERE b = ?", query)
+
+	query, err = RebindQuestion(Rebind("SELECT * FROM t WHERE a = ? AND b = ?"))
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = ? AND b = ?", query)
+
+	query, err = RebindQuestion("SELECT * FROM t WHERE data ? 'a' AND tags ?| $1")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE data ?? 'a' AND tags ??| ?", query)
+	assert.Equal(t, "SELECT * FROM t WHERE data ? 'a' AND tags ?| $1", Rebind(query))
+
+	// test some fail cases
+	_, err = RebindQuestion("SELECT * FROM t WHERE a = $1 OR b = $1")
+	require.Error(t, err)
+
+	_, err = RebindQuestion("SELECT * FROM t WHERE a = $2 AND b = $1")
+	require.Error(t, err)
+}
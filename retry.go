@@ -0,0 +1,131 @@
+package pgxscan
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// Classifier decides which errors RetryQueryer retries.
+type Classifier interface {
+	Retryable(err error) bool
+}
+
+// ClassifierFunc is a function implementing Classifier.
+type ClassifierFunc func(err error) bool
+
+// Retryable calls f.
+func (f ClassifierFunc) Retryable(err error) bool {
+	return f(err)
+}
+
+// ConnectionErrors classifies as retryable the errors of queries that were not sent to the server,
+// as reported by pgconn.SafeToRetry, connection exceptions (SQLSTATE class 08), and errors due to
+// the server shutting down or starting up (SQLSTATE 57P01, 57P02 and 57P03).
+var ConnectionErrors Classifier = ClassifierFunc(func(err error) bool {
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case "57P01", "57P02", "57P03":
+		return true
+	}
+	return len(pgErr.Code) == 5 && pgErr.Code[:2] == "08"
+})
+
+// RetryPolicy configures RetryQueryer.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a query is run at most, 3 if zero.
+	MaxAttempts int
+	// Backoff is the delay before the second attempt, doubled before each next one, 50ms if zero.
+	// A random jitter of up to half of it is added, so that clients don't retry in lockstep.
+	Backoff time.Duration
+	// Classifier decides which errors are retried, ConnectionErrors if nil.
+	Classifier Classifier
+}
+
+// RetryQueryer returns a Querier running the queries of Query and QueryRow with inner again,
+// according to policy, when they fail with a transient error, such as a connection reset.
+// Exec is not retried, as statements it runs may have side effects, and is passed to inner as is.
+//
+// Rows are only retried as long as Query fails, not when reading them fails. The rows of
+// QueryRow are retried when their Scan fails.
+func RetryQueryer(inner Querier, policy RetryPolicy) Querier {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = 50 * time.Millisecond
+	}
+	if policy.Classifier == nil {
+		policy.Classifier = ConnectionErrors
+	}
+	return &retryQuerier{inner: inner, policy: policy}
+}
+
+type retryQuerier struct {
+	inner  Querier
+	policy RetryPolicy
+}
+
+func (q *retryQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := q.retry(ctx, func() error {
+		var err error
+		rows, err = q.inner.Query(ctx, sql, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (q *retryQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return &retryRow{q: q, ctx: ctx, sql: sql, args: args}
+}
+
+func (q *retryQuerier) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return q.inner.Exec(ctx, sql, args...)
+}
+
+// retry calls fn until it succeeds, fails with an error which is not retryable, or the
+// attempts are exhausted, and returns its last error.
+func (q *retryQuerier) retry(ctx context.Context, fn func() error) error {
+	backoff := q.policy.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= q.policy.MaxAttempts || !q.policy.Classifier.Retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// retryRow runs its query when scanned, so that it can be run again.
+type retryRow struct {
+	q    *retryQuerier
+	ctx  context.Context
+	sql  string
+	args []interface{}
+}
+
+func (r *retryRow) Scan(dest ...interface{}) error {
+	return r.q.retry(r.ctx, func() error {
+		return r.q.inner.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	})
+}
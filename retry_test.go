@@ -0,0 +1,83 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingQuerier fails the first queries with err before passing them to Querier.
+type failingQuerier struct {
+	Querier
+	err      error
+	failures int
+	calls    int
+}
+
+func (q *failingQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	q.calls++
+	if q.calls <= q.failures {
+		return nil, q.err
+	}
+	return q.Querier.Query(ctx, sql, args...)
+}
+
+func (q *failingQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := q.Query(ctx, sql, args...)
+	return &rowsRow{rows: rows, err: err}
+}
+
+func TestRetryQueryer(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	policy := RetryPolicy{Backoff: time.Millisecond}
+	inner := &failingQuerier{Querier: conn, err: &pgconn.PgError{Code: "57P01"}, failures: 2}
+	var result testEntity
+	err := Get(context.Background(), RetryQueryer(inner, policy), &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+	assert.Equal(t, 3, inner.calls)
+
+	inner = &failingQuerier{Querier: conn, err: &pgconn.PgError{Code: "08006"}, failures: 1}
+	var data string
+	err = RetryQueryer(inner, policy).QueryRow(context.Background(), "SELECT some_data FROM structscan_test WHERE id = $1", e1.ID).Scan(&data)
+	require.NoError(t, err)
+	assert.Equal(t, e1.SomeData, data)
+	assert.Equal(t, 2, inner.calls)
+
+	inner = &failingQuerier{Querier: conn, err: errors.New("custom"), failures: 1}
+	policy.Classifier = ClassifierFunc(func(err error) bool { return err.Error() == "custom" })
+	err = Get(context.Background(), RetryQueryer(inner, policy), &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+
+	// test some fail cases
+	inner = &failingQuerier{Querier: conn, err: &pgconn.PgError{Code: "57P01"}, failures: 5}
+	err = Get(context.Background(), RetryQueryer(inner, RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}), &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+	assert.Equal(t, 2, inner.calls)
+
+	inner = &failingQuerier{Querier: conn, err: &pgconn.PgError{Code: "23505"}, failures: 5}
+	err = Get(context.Background(), RetryQueryer(inner, RetryPolicy{Backoff: time.Millisecond}), &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+	assert.Equal(t, 1, inner.calls)
+}
+
+func TestConnectionErrors(t *testing.T) {
+	for _, code := range []string{"08000", "08006", "57P01", "57P02", "57P03"} {
+		assert.True(t, ConnectionErrors.Retryable(&pgconn.PgError{Code: code}), code)
+	}
+	for _, code := range []string{"23505", "40001", "57014"} {
+		assert.False(t, ConnectionErrors.Retryable(&pgconn.PgError{Code: code}), code)
+	}
+	assert.True(t, ConnectionErrors.Retryable(errors.Wrap(&pgconn.PgError{Code: "08006"}, "failed")))
+	assert.False(t, ConnectionErrors.Retryable(errors.New("foo")))
+}
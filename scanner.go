@@ -0,0 +1,331 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// Scanner scans pgx rows into structs like the package-level functions do, with extra
+// behavior configured through options, so that parts of a program can use different
+// conventions. The package-level functions use a Scanner without options.
+type Scanner struct {
+	fieldMapper    structMapper
+	tagNames       []string
+	nameFunc       func(string) string
+	pipelines      map[string][]func(reflect.Value) error
+	location       *time.Location
+	unions         map[string]union
+	discriminators map[reflect.Type]discriminator
+	converters     map[reflect.Type]ConverterFunc
+	defaults       *dbDefaults
+	unsafe         bool
+	strict         bool
+	capacity       int
+	maxRows        int
+	slowQuery      *slowQuery
+	explain        func(query string, args []interface{}, plan []byte, err error)
+	notFound       error
+
+	// traversals caches the traversals of result columns into struct types.
+	traversals sync.Map
+
+	// err is the error an option failed with, returned by every scan.
+	err error
+}
+
+// Option configures a Scanner.
+type Option func(*Scanner)
+
+var defaultScanner = New()
+
+// New returns a Scanner configured with opts.
+func New(opts ...Option) *Scanner {
+	s := &Scanner{
+		pipelines:      make(map[string][]func(reflect.Value) error),
+		unions:         make(map[string]union),
+		discriminators: make(map[reflect.Type]discriminator),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.fieldMapper == nil && (len(s.tagNames) > 0 || s.nameFunc != nil) {
+		nameFunc := s.nameFunc
+		if nameFunc == nil {
+			nameFunc = sqlx.NameMapper
+		}
+		if len(s.tagNames) > 1 {
+			s.fieldMapper = newTagChainMapper(s.tagNames, nameFunc)
+		} else {
+			s.fieldMapper = reflectx.NewMapperFunc(s.tags()[0], nameFunc)
+		}
+	}
+	return s
+}
+
+// WithMapper maps struct fields to columns with m instead of DefaultMapper.
+// It takes precedence over WithTagName, WithTagNames and WithNameMapper.
+func WithMapper(m *reflectx.Mapper) Option {
+	return func(s *Scanner) {
+		s.fieldMapper = m
+	}
+}
+
+// WithTagName names the columns of struct fields after their tag named name, instead of "db".
+func WithTagName(name string) Option {
+	return WithTagNames(name)
+}
+
+// WithTagNames names the columns of struct fields after the first of the tags named names
+// they have, such as "db" then "json". Fields with none of them fall back to the name mapper.
+func WithTagNames(names ...string) Option {
+	return func(s *Scanner) {
+		s.tagNames = names
+	}
+}
+
+// WithNameMapper maps the names of the struct fields without a tag with fn,
+// instead of lowercasing them. SnakeCase maps CreatedAt to created_at.
+func WithNameMapper(fn func(string) string) Option {
+	return func(s *Scanner) {
+		s.nameFunc = fn
+	}
+}
+
+// WithUnsafe makes the Scanner ignore the result columns without a matching struct field,
+// like sqlx's Unsafe, instead of failing with a missing column error.
+func WithUnsafe() Option {
+	return func(s *Scanner) {
+		s.unsafe = true
+	}
+}
+
+// WithStrict makes the Scanner fail when a field with a tag has no column in the result,
+// to catch typos in SELECT lists. Fields tagged with the omitmissing option stay optional.
+func WithStrict() Option {
+	return func(s *Scanner) {
+		s.strict = true
+	}
+}
+
+// WithCapacityHint makes ScanStructs, ScanFlat and the functions using them allocate the
+// result slice for n rows upfront, to avoid growing it repeatedly for large results.
+// Growing past n is handled as usual.
+func WithCapacityHint(n int) Option {
+	return func(s *Scanner) {
+		s.capacity = n
+	}
+}
+
+// WithMaxRows makes ScanStructs, ScanFlat and the functions using them, such as Select, fail
+// with ErrTooManyRows once the result has more than n rows, instead of reading them all into
+// memory, to guard against queries missing a WHERE or LIMIT clause.
+func WithMaxRows(n int) Option {
+	return func(s *Scanner) {
+		s.maxRows = n
+	}
+}
+
+// WithSlowQueryThreshold makes the Scanner call fn with the query, the arguments and the elapsed
+// time of each query run by Get, Select and the other methods running or executing queries,
+// such as Insert or ExecNamed, taking longer than threshold, scanning included, to report
+// scanning hot spots. The package-level functions, such as SelectEach or Count, run their
+// queries without options, so they aren't reported.
+func WithSlowQueryThreshold(threshold time.Duration, fn func(query string, args []interface{}, elapsed time.Duration)) Option {
+	return func(s *Scanner) {
+		s.slowQuery = &slowQuery{threshold: threshold, fn: fn}
+	}
+}
+
+// WithExplainAnalyze makes the Scanner run EXPLAIN (ANALYZE, FORMAT JSON) before each query
+// run by Get, Select and the other methods running queries, and call fn with the plan, or
+// the error EXPLAIN failed with, to find out why a query is slow. It is meant for debugging.
+//
+// EXPLAIN ANALYZE runs the query, so it runs in a transaction, or a savepoint of the
+// transaction the querier is, always rolled back: writes are not applied twice, and a failing
+// EXPLAIN doesn't abort the caller's transaction. Queriers which can't begin a transaction
+// only get plain SELECT queries explained, fn getting an error for the others.
+func WithExplainAnalyze(fn func(query string, args []interface{}, plan []byte, err error)) Option {
+	return func(s *Scanner) {
+		s.explain = fn
+	}
+}
+
+// WithNotFoundError makes Get and GetOne return err as is, instead of pgx.ErrNoRows, when the
+// query returns no rows, so that callers can check for an application-level sentinel error.
+func WithNotFoundError(err error) Option {
+	return func(s *Scanner) {
+		s.notFound = err
+	}
+}
+
+// WithFieldPipeline registers transforms applied in order to the field mapped to the column
+// named field, after each row is scanned. Each transform receives the settable field value
+// and may modify it in place; the first error aborts the scan. Registering more transforms
+// for the same field appends them to its pipeline.
+func WithFieldPipeline(field string, transforms ...func(reflect.Value) error) Option {
+	return func(s *Scanner) {
+		s.pipelines[field] = append(s.pipelines[field], transforms...)
+	}
+}
+
+// Get works like the package-level Get, using the Scanner options.
+func (s *Scanner) Get(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	rows, err := s.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return s.notFoundError(s.scanStruct(ctx, rows, dest))
+}
+
+// GetOne works like the package-level GetOne, using the Scanner options.
+func (s *Scanner) GetOne(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	rows, err := s.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return s.notFoundError(s.scanStructStrict(ctx, rows, dest))
+}
+
+// Select works like the package-level Select, using the Scanner options.
+func (s *Scanner) Select(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	rows, err := s.query(ctx, querier, query, args)
+	if err != nil {
+		return err
+	}
+	return s.ScanStructsContext(ctx, rows, dest)
+}
+
+// query runs query with querier, explaining it and reporting it if slow.
+func (s *Scanner) query(ctx context.Context, querier Querier, query string, args []interface{}) (pgx.Rows, error) {
+	return s.observed(ctx, querier, query, args).Query(ctx, query, args...)
+}
+
+// exec executes query with querier, explaining it and reporting it if slow.
+func (s *Scanner) exec(ctx context.Context, querier Querier, query string, args []interface{}) (pgconn.CommandTag, error) {
+	return s.observed(ctx, querier, query, args).Exec(ctx, query, args...)
+}
+
+// observed explains query, if set to, and returns querier to run it with, reporting it if slow.
+func (s *Scanner) observed(ctx context.Context, querier Querier, query string, args []interface{}) Querier {
+	if s.explain != nil {
+		plan, err := explainAnalyze(ctx, querier, query, args)
+		s.explain(query, args, plan, err)
+	}
+	if s.slowQuery != nil {
+		querier = &observedQuerier{inner: querier, observe: s.slowQuery.observe}
+	}
+	return querier
+}
+
+// beginner is implemented by the queriers which can begin a transaction, or a savepoint of
+// the transaction they are, such as *pgx.Conn, *pgxpool.Pool and pgx.Tx.
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// explainAnalyze returns the plan of query run with args, in a transaction rolled back.
+func explainAnalyze(ctx context.Context, querier Querier, query string, args []interface{}) ([]byte, error) {
+	b, ok := querier.(beginner)
+	if !ok {
+		if !isSelect(query) {
+			return nil, errors.New("can't explain a query other than a SELECT without a transaction to roll back")
+		}
+		var plan []byte
+		err := querier.QueryRow(ctx, "EXPLAIN (ANALYZE, FORMAT JSON) "+query, args...).Scan(&plan)
+		return plan, err
+	}
+
+	tx, err := b.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin the transaction to explain the query in")
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var plan []byte
+	err = tx.QueryRow(ctx, "EXPLAIN (ANALYZE, FORMAT JSON) "+query, args...).Scan(&plan)
+	return plan, err
+}
+
+// isSelect reports whether query is a plain SELECT.
+func isSelect(query string) bool {
+	query = strings.TrimSpace(query)
+	return len(query) > len("SELECT") && strings.EqualFold(query[:len("SELECT")], "SELECT") &&
+		!isNamePart(query[len("SELECT")])
+}
+
+// notFoundError replaces pgx.ErrNoRows with the error set by WithNotFoundError, if any.
+func (s *Scanner) notFoundError(err error) error {
+	if s.notFound != nil && errors.Is(err, pgx.ErrNoRows) {
+		return s.notFound
+	}
+	return err
+}
+
+// slowQuery reports the queries taking longer than threshold to fn.
+type slowQuery struct {
+	threshold time.Duration
+	fn        func(query string, args []interface{}, elapsed time.Duration)
+}
+
+func (q *slowQuery) observe(ctx context.Context, event QueryEvent) {
+	if event.Duration > q.threshold {
+		q.fn(event.Query, event.Args, event.Duration)
+	}
+}
+
+// tags returns the names of the struct tags naming columns.
+func (s *Scanner) tags() []string {
+	if len(s.tagNames) > 0 {
+		return s.tagNames
+	}
+	return []string{"db"}
+}
+
+func (s *Scanner) mapper() structMapper {
+	if s.fieldMapper != nil {
+		return s.fieldMapper
+	}
+	return DefaultMapper
+}
+
+// afterScan runs the per-field post-processing on v, a struct freshly scanned from columns.
+func (s *Scanner) afterScan(v reflect.Value, columns []string, fields [][]int) error {
+	for i, column := range columns {
+		if len(fields[i]) == 0 {
+			continue
+		}
+
+		// fields of structs left nil by NULL columns are skipped
+		f, ok := fieldByIndexes(v, fields[i])
+		if !ok {
+			continue
+		}
+		if s.location != nil {
+			setLocation(f, s.location)
+		}
+
+		for _, transform := range s.pipelines[column] {
+			if err := transform(f); err != nil {
+				return errors.Wrapf(err, "pipeline of field %q failed", column)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package pgxscan
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScannerFieldPipeline(t *testing.T) {
+	conn := connect(t)
+
+	_, err := conn.Exec(
+		context.Background(),
+		"INSERT INTO structscan_test (id, some_data, created_at) VALUES ($1, $2, $3)",
+		"pipeline-1", "  Foo BAR Baz ", time.Now(),
+	)
+	require.NoError(t, err)
+
+	trim := func(v reflect.Value) error {
+		v.SetString(strings.TrimSpace(v.String()))
+		return nil
+	}
+	lower := func(v reflect.Value) error {
+		v.SetString(strings.ToLower(v.String()))
+		return nil
+	}
+
+	scanner := New(WithFieldPipeline("some_data", trim, lower))
+	result := new(testEntity)
+	err = scanner.Get(context.Background(), conn, result, "SELECT * FROM structscan_test WHERE id = $1", "pipeline-1")
+	require.NoError(t, err)
+	assert.Equal(t, "pipeline-1", result.ID)
+	assert.Equal(t, "foo bar baz", result.SomeData)
+
+	// test some fail cases
+	reject := func(v reflect.Value) error {
+		return errors.New("rejected")
+	}
+	scanner = New(WithFieldPipeline("some_data", trim, reject, lower))
+	resultFail := new(testEntity)
+	err = scanner.Get(context.Background(), conn, resultFail, "SELECT * FROM structscan_test WHERE id = $1", "pipeline-1")
+	require.Error(t, err)
+	assert.Equal(t, `pipeline of field "some_data" failed: rejected`, err.Error())
+	assert.Equal(t, "Foo BAR Baz", resultFail.SomeData)
+}
+
+func TestScannerUnsafe(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	scanner := New(WithUnsafe())
+
+	var result testMissingField
+	err := scanner.Get(context.Background(), conn, &result, "SELECT *, 'extra' AS extra FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+	// compare unit timestamp to avoid milliseconds diff
+	assert.Equal(t, e1.CreatedAt.Unix(), result.CreatedAt.Unix())
+
+	var results []*testMissingField
+	err = scanner.ScanStructs(selectRows(t, conn, e1.ID, e2.ID), &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, e1.ID, results[0].ID)
+	assert.Equal(t, e2.ID, results[1].ID)
+
+	// test some fail cases
+	err = Get(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing column "some_data"`)
+}
+
+func TestScannerStrict(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	scanner := New(WithStrict())
+
+	var result testEntity
+	err := scanner.Get(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+
+	// omitmissing fields are optional
+	var resultOmit testOmitMissing
+	err = scanner.Get(context.Background(), conn, &resultOmit, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, resultOmit.ID)
+
+	// test some fail cases
+	err = scanner.Get(context.Background(), conn, &result, "SELECT id, created_at FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+	assert.Equal(t, `column "some_data" of dest *pgxscan.testEntity is missing from the result`, err.Error())
+
+	var results []testEntity
+	err = scanner.Select(context.Background(), conn, &results, "SELECT id, created_at FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+}
+
+type testSQLTagEntity struct {
+	Key     string    `sql:"id"`
+	Created time.Time `sql:"created_at"`
+	Data    string    `sql:"some_data"`
+}
+
+func TestScannerTagName(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	for name, scanner := range map[string]*Scanner{
+		"tag name":  New(WithTagName("sql")),
+		"tag names": New(WithTagNames("db", "sql")),
+		"mapper":    New(WithMapper(reflectx.NewMapperFunc("sql", strings.ToLower))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var result testSQLTagEntity
+			err := scanner.Get(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, e1.ID, result.Key)
+			assert.Equal(t, e1.SomeData, result.Data)
+			// compare unit timestamp to avoid milliseconds diff
+			assert.Equal(t, e1.CreatedAt.Unix(), result.Created.Unix())
+		})
+	}
+
+	// test some fail cases
+	var result testSQLTagEntity
+	err := Get(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing column "id"`)
+}
+
+func TestScannerCapacityHint(t *testing.T) {
+	scanner := New(WithCapacityHint(150))
+
+	var result []testEntity
+	require.NoError(t, scanner.ScanStructs(newFakeRows(100), &result))
+	assert.Len(t, result, 100)
+	assert.Equal(t, 150, cap(result))
+
+	var ids []string
+	rows := newFakeRows(100)
+	rows.fields = rows.fields[:1]
+	require.NoError(t, scanner.ScanFlat(rows, &ids))
+	assert.Len(t, ids, 100)
+	assert.Equal(t, 150, cap(ids))
+
+	// results larger than the hint grow as usual
+	require.NoError(t, scanner.ScanStructs(newFakeRows(200), &result))
+	assert.Len(t, result, 200)
+}
+
+func TestScannerMaxRows(t *testing.T) {
+	scanner := New(WithMaxRows(3))
+
+	var result []testEntity
+	require.NoError(t, scanner.ScanStructs(newFakeRows(3), &result))
+	assert.Len(t, result, 3)
+
+	var ids []string
+	rows := newFakeRows(3)
+	rows.fields = rows.fields[:1]
+	require.NoError(t, scanner.ScanFlat(rows, &ids))
+	assert.Len(t, ids, 3)
+
+	// test some fail cases
+	err := scanner.ScanStructs(newFakeRows(4), &result)
+	assert.Equal(t, ErrTooManyRows, err)
+
+	rows = newFakeRows(4)
+	rows.fields = rows.fields[:1]
+	err = scanner.ScanFlat(rows, &ids)
+	assert.Equal(t, ErrTooManyRows, err)
+}
+
+func TestScannerSlowQueryThreshold(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	type slowQuery struct {
+		query   string
+		args    []interface{}
+		elapsed time.Duration
+	}
+	var slow []slowQuery
+	scanner := New(WithSlowQueryThreshold(50*time.Millisecond, func(query string, args []interface{}, elapsed time.Duration) {
+		slow = append(slow, slowQuery{query: query, args: args, elapsed: elapsed})
+	}))
+
+	var result testEntity
+	err := scanner.Get(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Empty(t, slow)
+
+	var results []*testEntity
+	query := "SELECT * FROM structscan_test, pg_sleep(0.1) WHERE id IN ($1, $2)"
+	err = scanner.Select(context.Background(), conn, &results, query, e1.ID, e2.ID)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	require.Len(t, slow, 1)
+	assert.Equal(t, query, slow[0].query)
+	assert.Equal(t, []interface{}{e1.ID, e2.ID}, slow[0].args)
+	assert.GreaterOrEqual(t, slow[0].elapsed, 100*time.Millisecond)
+
+	// executed queries are reported too
+	query = "UPDATE structscan_test SET some_data = some_data FROM pg_sleep(0.1) WHERE id = $1"
+	_, err = scanner.ExecNamed(context.Background(), conn, "UPDATE structscan_test SET some_data = some_data FROM pg_sleep(0.1) WHERE id = :id", e1)
+	require.NoError(t, err)
+	require.Len(t, slow, 2)
+	assert.Equal(t, query, slow[1].query)
+	assert.Equal(t, []interface{}{e1.ID}, slow[1].args)
+}
+
+func TestScannerExplainAnalyze(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	var plans [][]byte
+	scanner := New(WithExplainAnalyze(func(query string, args []interface{}, plan []byte, err error) {
+		assert.Equal(t, "SELECT * FROM structscan_test WHERE id = $1", query)
+		assert.Equal(t, []interface{}{e1.ID}, args)
+		require.NoError(t, err)
+		plans = append(plans, plan)
+	}))
+
+	var result testEntity
+	err := scanner.Get(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+
+	require.Len(t, plans, 1)
+	var plan []struct {
+		Plan          map[string]interface{} `json:"Plan"`
+		ExecutionTime float64                `json:"Execution Time"`
+	}
+	require.NoError(t, json.Unmarshal(plans[0], &plan))
+	require.Len(t, plan, 1)
+	assert.Contains(t, plan[0].Plan, "Actual Rows")
+
+	// test some fail cases
+	var explainErr error
+	scanner = New(WithExplainAnalyze(func(query string, args []interface{}, plan []byte, err error) {
+		explainErr = err
+	}))
+	err = scanner.Get(context.Background(), conn, &result, "SELECT * FROM structscan_test_missing")
+	require.Error(t, err)
+	require.Error(t, explainErr)
+
+	// the explained writes are rolled back
+	explainErr = nil
+	var id string
+	err = scanner.Get(context.Background(), conn, &id,
+		"INSERT INTO structscan_test (id, some_data, created_at) VALUES ($1, 'explained', now()) RETURNING id", "explain-1")
+	require.NoError(t, err)
+	require.NoError(t, explainErr)
+	var count int
+	err = conn.QueryRow(context.Background(), "SELECT count(*) FROM structscan_test WHERE id = 'explain-1'").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// in a transaction, EXPLAIN runs in a savepoint rolled back
+	tx, err := conn.Begin(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		_ = tx.Rollback(context.Background())
+	}()
+	err = scanner.Get(context.Background(), tx, &id,
+		"INSERT INTO structscan_test (id, some_data, created_at) VALUES ($1, 'explained', now()) RETURNING id", "explain-2")
+	require.NoError(t, err)
+	require.NoError(t, explainErr)
+	err = tx.QueryRow(context.Background(), "SELECT count(*) FROM structscan_test WHERE id = 'explain-2'").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// queriers which can't begin a transaction only get SELECT queries explained
+	explainErr = nil
+	_, err = explainAnalyze(context.Background(), &observedQuerier{inner: conn, observe: func(context.Context, QueryEvent) {}},
+		"DELETE FROM structscan_test", nil)
+	require.Error(t, err)
+	err = Get(context.Background(), conn, &count, "SELECT count(*) FROM structscan_test")
+	require.NoError(t, err)
+	assert.NotZero(t, count)
+}
+
+func TestScannerNotFoundError(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	errUserNotFound := errors.New("user not found")
+	scanner := New(WithNotFoundError(errUserNotFound))
+
+	var result testEntity
+	err := scanner.Get(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+
+	// test some fail cases
+	err = scanner.Get(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	assert.Equal(t, errUserNotFound, err)
+
+	err = scanner.GetOne(context.Background(), conn, &result, "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	assert.Equal(t, errUserNotFound, err)
+
+	err = scanner.Get(context.Background(), conn, &result, "SELECT * FROM structscan_test_missing")
+	require.Error(t, err)
+	assert.NotEqual(t, errUserNotFound, err)
+}
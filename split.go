@@ -0,0 +1,130 @@
+package pgxscan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// ScanRowSplit scans the first row of r into several structs, see Scanner.ScanRowSplit.
+func ScanRowSplit(r pgx.Rows, dests map[string]interface{}) error {
+	return defaultScanner.ScanRowSplit(r, dests)
+}
+
+// ScanRowSplit scans the first row of r into several structs passed by reference, one per
+// column prefix, to split the result of a join. A column aliased as "author.name" is scanned
+// into the name field of dests["author"]. Columns whose prefix has no destination, or without
+// a prefix, are scanned into dests[""], using their full name. AfterScan is called on each of
+// dests once the row is scanned.
+//
+// If there are no rows pgx.ErrNoRows is returned. Function call closes rows, so caller may skip it.
+func (s *Scanner) ScanRowSplit(r pgx.Rows, dests map[string]interface{}) error {
+	defer r.Close()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	destValues := make(map[string]reflect.Value, len(dests))
+	for prefix, dest := range dests {
+		v := reflect.ValueOf(dest)
+		if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return errors.Errorf("dest %q must be a non-nil pointer to a struct, got %T", prefix, dest)
+		}
+		destValues[prefix] = v
+	}
+
+	if !r.Next() {
+		if err := r.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+
+	// group the columns by destination, in column order
+	var prefixes []string
+	groups := make(map[string][]int)
+	names := make([]string, len(r.FieldDescriptions()))
+	for i, fieldDescription := range r.FieldDescriptions() {
+		column := string(fieldDescription.Name)
+		prefix, name, ok := strings.Cut(column, ".")
+		if _, found := destValues[prefix]; !ok || !found {
+			prefix, name = "", column
+		}
+		if _, ok := destValues[prefix]; !ok {
+			return errors.Errorf("no dest for column %q", column)
+		}
+
+		if _, ok := groups[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+		groups[prefix] = append(groups[prefix], i)
+		names[i] = name
+	}
+
+	d := newRowDecoder(columnOIDs(r))
+	conversions := make([]func() error, len(names))
+	scanErrors := make([]func(err error) error, len(names))
+	afterScans := make([]func() error, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		v, indexes := destValues[prefix], groups[prefix]
+
+		columns := make([]string, len(indexes))
+		oids := make([]uint32, len(indexes))
+		for j, i := range indexes {
+			columns[j] = names[i]
+			oids[j] = r.FieldDescriptions()[i].DataTypeOID
+		}
+
+		fields := s.traversalsByName(v.Type(), columns)
+		if f, err := missingFields(fields); err != nil && !s.unsafe {
+			return fmt.Errorf("missing column %q in dest %s", string(r.FieldDescriptions()[indexes[f]].Name), v.Type())
+		}
+
+		groupValues := make([]interface{}, len(indexes))
+		groupConversions, err := s.fieldsByTraversal(v, columns, oids, fields, groupValues)
+		if err != nil {
+			return err
+		}
+		for j, i := range indexes {
+			j := j
+			d.values[i], conversions[i] = groupValues[j], groupConversions[j]
+			scanErrors[i] = func(err error) error {
+				return s.scanError(err, 0, v, columns, oids, fields, j)
+			}
+		}
+		afterScans = append(afterScans, func() error {
+			if err := s.afterScan(v, columns, fields); err != nil {
+				return err
+			}
+			return afterScanHook(context.Background(), v)
+		})
+	}
+
+	if i, err := d.scan(r); err != nil {
+		if i < 0 {
+			return &ScanError{Err: err}
+		}
+		return scanErrors[i](err)
+	}
+
+	for i, convert := range conversions {
+		if convert == nil {
+			continue
+		}
+		if err := convert(); err != nil {
+			return scanErrors[i](err)
+		}
+	}
+	for _, afterScan := range afterScans {
+		if err := afterScan(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
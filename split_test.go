@@ -0,0 +1,85 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBook struct {
+	ID    string `db:"id"`
+	Title string `db:"title"`
+}
+
+func TestScanRowSplit(t *testing.T) {
+	conn := connect(t)
+
+	query := `
+		SELECT b.id AS "book.id", b.title AS "book.title", a.id AS "author.id", a.name AS "author.name"
+		FROM (VALUES ('book-1', 'Dune', 'author-1')) AS b (id, title, author_id)
+		JOIN (VALUES ('author-1', 'Frank Herbert')) AS a (id, name) ON a.id = b.author_id
+		WHERE b.id = $1
+	`
+
+	rows, err := conn.Query(context.Background(), query, "book-1")
+	require.NoError(t, err)
+
+	var book testBook
+	var author testAuthor
+	err = ScanRowSplit(rows, map[string]interface{}{"book": &book, "author": &author})
+	require.NoError(t, err)
+	assert.Equal(t, testBook{ID: "book-1", Title: "Dune"}, book)
+	assert.Equal(t, testAuthor{ID: "author-1", Name: "Frank Herbert"}, author)
+
+	// unprefixed columns go to the "" dest
+	rows, err = conn.Query(context.Background(), `SELECT 'book-2' AS id, 'Emma' AS title, 'author-2' AS "author.id", 'Jane Austen' AS "author.name"`)
+	require.NoError(t, err)
+
+	err = ScanRowSplit(rows, map[string]interface{}{"": &book, "author": &author})
+	require.NoError(t, err)
+	assert.Equal(t, testBook{ID: "book-2", Title: "Emma"}, book)
+	assert.Equal(t, testAuthor{ID: "author-2", Name: "Jane Austen"}, author)
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), query, "foo")
+	require.NoError(t, err)
+	err = ScanRowSplit(rows, map[string]interface{}{"book": &book, "author": &author})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+
+	rows, err = conn.Query(context.Background(), query, "book-1")
+	require.NoError(t, err)
+	err = ScanRowSplit(rows, map[string]interface{}{"book": &book})
+	require.Error(t, err)
+	assert.Equal(t, `no dest for column "author.id"`, err.Error())
+
+	rows, err = conn.Query(context.Background(), query, "book-1")
+	require.NoError(t, err)
+	err = ScanRowSplit(rows, map[string]interface{}{"book": &book, "author": new(testMissingField)})
+	require.Error(t, err)
+	assert.Equal(t, `missing column "author.name" in dest *pgxscan.testMissingField`, err.Error())
+
+	rows, err = conn.Query(context.Background(), query, "book-1")
+	require.NoError(t, err)
+	err = ScanRowSplit(rows, map[string]interface{}{"book": book, "author": &author})
+	require.Error(t, err)
+}
+
+func TestScanRowSplitFakeRows(t *testing.T) {
+	var entity testAfterScanEntity
+	err := ScanRowSplit(newFakeRows(1), map[string]interface{}{"": &entity})
+	require.NoError(t, err)
+	assert.Equal(t, "bench-0", entity.ID)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, entity.Words)
+
+	// test some fail cases
+	rows := newFakeRows(3)
+	rows.rows = rows.rows[2:]
+	err = ScanRowSplit(rows, map[string]interface{}{"": &entity})
+	require.Error(t, err)
+	assert.Equal(t, "AfterScan failed: invalid id", err.Error())
+}
@@ -0,0 +1,218 @@
+// Package sqlpgxscan provides the pgxscan functions for database/sql, with the pgx stdlib
+// driver or any other Postgres driver, so that code moving between database/sql and pgx
+// can share destination structs and scanning code.
+//
+// The *sql.Rows are adapted to the pgx v4 interface the scanning code works with, so the
+// scanning behavior is the same as pgxscan's. Wrap adapts rows to use a pgxscan.Scanner.
+package sqlpgxscan
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+// Querier is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Get works like pgxscan.Get.
+func Get(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return ScanStruct(rows, dest)
+}
+
+// Select works like pgxscan.Select.
+func Select(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return ScanStructs(rows, dest)
+}
+
+// ScanStruct works like pgxscan.ScanStruct, returning sql.ErrNoRows if there are no rows.
+func ScanStruct(r *sql.Rows, dest interface{}) error {
+	err := pgxscan.ScanStruct(Wrap(r), dest)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// ScanStructs works like pgxscan.ScanStructs.
+func ScanStructs(r *sql.Rows, dest interface{}) error {
+	return pgxscan.ScanStructs(Wrap(r), dest)
+}
+
+// Wrap adapts *sql.Rows to the pgx v4 pgx.Rows interface, to scan them with a pgxscan.Scanner.
+// The errors returned by the Scanner are the pgx v4 ones, such as its pgx.ErrNoRows.
+//
+// The type OIDs of the columns are looked up by the database type names the driver reports,
+// and are 0 for the names pgtype does not know about. CommandTag is always empty.
+func Wrap(r *sql.Rows) pgx.Rows {
+	return &rows{rows: r, ci: pgtype.NewConnInfo()}
+}
+
+// rows implements the pgx v4 pgx.Rows on top of *sql.Rows.
+type rows struct {
+	rows              *sql.Rows
+	fieldDescriptions []pgproto3.FieldDescription
+	err               error
+
+	// ci decodes the values scanned into pgtype decoders, which database/sql does not know
+	// about. Data types are decoded in place, so it is not shared between rows.
+	ci *pgtype.ConnInfo
+}
+
+func (r *rows) Close() {
+	r.rows.Close()
+}
+
+func (r *rows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.rows.Err()
+}
+
+func (r *rows) CommandTag() pgconn.CommandTag {
+	return nil
+}
+
+func (r *rows) FieldDescriptions() []pgproto3.FieldDescription {
+	if r.fieldDescriptions != nil {
+		return r.fieldDescriptions
+	}
+
+	columnTypes, err := r.rows.ColumnTypes()
+	if err != nil {
+		r.err = err
+		return nil
+	}
+	r.fieldDescriptions = make([]pgproto3.FieldDescription, len(columnTypes))
+	for i, ct := range columnTypes {
+		r.fieldDescriptions[i] = pgproto3.FieldDescription{
+			Name:        []byte(ct.Name()),
+			DataTypeOID: r.typeOID(ct.DatabaseTypeName()),
+			Format:      pgtype.TextFormatCode,
+		}
+	}
+	return r.fieldDescriptions
+}
+
+// typeOID returns the OID of the type named name by a driver, such as "INT4" or "_TEXT".
+// Drivers name the types unknown to them by their OID.
+func (r *rows) typeOID(name string) uint32 {
+	if dt, ok := r.ci.DataTypeForName(strings.ToLower(name)); ok {
+		return dt.OID
+	}
+	if oid, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(oid)
+	}
+	return 0
+}
+
+func (r *rows) Next() bool {
+	return r.rows.Next()
+}
+
+// Scan decodes the values scanned into pgtype decoders itself, from their text format, and
+// leaves the others to database/sql.
+func (r *rows) Scan(dest ...interface{}) error {
+	fieldDescriptions := r.FieldDescriptions()
+
+	values := make([]interface{}, len(dest))
+	for i, d := range dest {
+		if decoder, ok := d.(pgtype.TextDecoder); ok && i < len(fieldDescriptions) {
+			values[i] = &textScanner{ci: r.ci, oid: fieldDescriptions[i].DataTypeOID, decoder: decoder}
+		} else {
+			values[i] = d
+		}
+	}
+
+	return r.rows.Scan(values...)
+}
+
+func (r *rows) Values() ([]interface{}, error) {
+	values := make([]interface{}, len(r.FieldDescriptions()))
+	dest := make([]interface{}, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// RawValues returns the text format of the values of the current row, or nil if they can't be
+// scanned.
+func (r *rows) RawValues() [][]byte {
+	fieldDescriptions := r.FieldDescriptions()
+	values, err := r.Values()
+	if err != nil {
+		return nil
+	}
+
+	rawValues := make([][]byte, len(values))
+	for i, value := range values {
+		if rawValues[i], err = textValue(r.ci, fieldDescriptions[i].DataTypeOID, value); err != nil {
+			return nil
+		}
+	}
+	return rawValues
+}
+
+// textScanner is a sql.Scanner decoding the value scanned, of type oid, into decoder.
+type textScanner struct {
+	ci      *pgtype.ConnInfo
+	oid     uint32
+	decoder pgtype.TextDecoder
+}
+
+func (s *textScanner) Scan(src interface{}) error {
+	text, err := textValue(s.ci, s.oid, src)
+	if err != nil {
+		return err
+	}
+	return s.decoder.DecodeText(s.ci, text)
+}
+
+// textValue returns the text format of src, a value of type oid returned by a driver, or nil if
+// it is NULL. Drivers return values either in text format, such as the string of a numeric, kept
+// as is, or decoded, such as the time.Time of a timestamptz or the []byte of a bytea, encoded
+// back.
+func textValue(ci *pgtype.ConnInfo, oid uint32, src interface{}) ([]byte, error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(src), nil
+	case []byte:
+		if oid != pgtype.ByteaOID {
+			return src, nil
+		}
+	}
+
+	if dt, ok := ci.DataTypeForOID(oid); ok {
+		value := pgtype.NewValue(dt.Value)
+		if encoder, ok := value.(pgtype.TextEncoder); ok && value.Set(src) == nil {
+			return encoder.EncodeText(ci, nil)
+		}
+	}
+	return nil, errors.Errorf("can't get the text format of %T for type %d", src, oid)
+}
@@ -0,0 +1,189 @@
+package sqlpgxscan
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgtype"
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyr-sh/pgxscan/v2"
+)
+
+type testEntity struct {
+	ID        string      `db:"id"`
+	Note      *string     `db:"note"`
+	Label     pgtype.Text `db:"label"`
+	Amount    *big.Rat    `db:"amount"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+func TestGetSelect(t *testing.T) {
+	db := connect(t)
+
+	var result testEntity
+	err := Get(context.Background(), db, &result, "SELECT * FROM sqlpgxscan_test WHERE id = $1", "sql-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sql-1", result.ID)
+	require.NotNil(t, result.Note)
+	assert.Equal(t, "foo", *result.Note)
+	assert.Equal(t, pgtype.Text{String: "bar", Status: pgtype.Present}, result.Label)
+	assert.Equal(t, "3/2", result.Amount.String())
+	assert.Equal(t, int64(1577836800), result.CreatedAt.Unix())
+
+	var results []*testEntity
+	err = Select(context.Background(), db, &results, "SELECT * FROM sqlpgxscan_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "sql-2", results[1].ID)
+	assert.Nil(t, results[1].Note)
+	assert.Nil(t, results[1].Amount)
+	assert.Equal(t, pgtype.Null, results[1].Label.Status)
+
+	// test some fail cases
+	err = Get(context.Background(), db, &result, "SELECT * FROM sqlpgxscan_test WHERE id = $1", "foo")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+
+	var resultMissing struct {
+		ID string `db:"id"`
+	}
+	err = Get(context.Background(), db, &resultMissing, "SELECT * FROM sqlpgxscan_test WHERE id = $1", "sql-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing column "note"`)
+}
+
+func TestWrap(t *testing.T) {
+	db := sql.OpenDB(fakeConnector{})
+	defer db.Close()
+
+	rows, err := db.Query("fake")
+	require.NoError(t, err)
+
+	scanner := pgxscan.New(pgxscan.WithTimeLocation(time.UTC))
+
+	var results []testEntity
+	err = scanner.ScanStructs(Wrap(rows), &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "fake-1", results[0].ID)
+	require.NotNil(t, results[0].Note)
+	assert.Equal(t, "foo", *results[0].Note)
+	assert.Equal(t, "bar", results[0].Label.String)
+	assert.Equal(t, "3/2", results[0].Amount.String())
+	assert.Equal(t, time.UTC, results[0].CreatedAt.Location())
+	assert.Equal(t, int64(1577836800), results[0].CreatedAt.Unix())
+	assert.Nil(t, results[1].Note)
+	assert.Equal(t, pgtype.Null, results[1].Label.Status)
+	assert.Nil(t, results[1].Amount)
+
+	rows, err = db.Query("fake")
+	require.NoError(t, err)
+	defer rows.Close()
+	wrapped := Wrap(rows)
+	assert.Equal(t, uint32(pgtype.TimestamptzOID), wrapped.FieldDescriptions()[4].DataTypeOID)
+	require.True(t, wrapped.Next())
+	assert.Equal(t, []byte("1.5"), wrapped.RawValues()[3])
+	values, err := wrapped.Values()
+	require.NoError(t, err)
+	assert.Equal(t, "fake-1", values[0])
+
+	// test some fail cases
+	rows, err = db.Query("fake")
+	require.NoError(t, err)
+	var resultMissing []struct {
+		ID string `db:"id"`
+	}
+	err = ScanStructs(rows, &resultMissing)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing column "note"`)
+}
+
+// fakeConnector is a database/sql driver returning the same rows for every query, typed
+// like the pgx stdlib driver types them.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{
+		columns: []string{"id", "note", "label", "amount", "created_at"},
+		types:   []string{"TEXT", "TEXT", "TEXT", "NUMERIC", "TIMESTAMPTZ"},
+		rows: [][]driver.Value{
+			{"fake-1", "foo", "bar", "1.5", time.Unix(1577836800, 0)},
+			{"fake-2", nil, nil, nil, time.Unix(1577836800, 0)},
+		},
+	}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string                           { return r.columns }
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string { return r.types[index] }
+func (r *fakeRows) Close() error                                { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func connect(t *testing.T) *sql.DB {
+	t.Helper()
+
+	connString := os.Getenv("TEST_POSTGRES_URI")
+	require.NotEmpty(t, connString)
+
+	db, err := sql.Open("pgx", connString)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := db.Close()
+		assert.NoError(t, err)
+	})
+
+	_, err = db.Exec(`DROP TABLE IF EXISTS sqlpgxscan_test`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		CREATE TABLE sqlpgxscan_test (
+			id         text PRIMARY KEY,
+			note       text,
+			label      text,
+			amount     numeric,
+			created_at timestamptz not null
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		INSERT INTO sqlpgxscan_test (id, note, label, amount, created_at) VALUES
+			('sql-1', 'foo', 'bar', 1.5, '2020-01-01 00:00:00+00'),
+			('sql-2', NULL, NULL, NULL, '2020-01-01 00:00:00+00')
+	`)
+	require.NoError(t, err)
+
+	return db
+}
@@ -2,10 +2,13 @@ package pgxscan
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"reflect"
 
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
 	pgx "github.com/jackc/pgx/v4"
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/reflectx"
@@ -21,27 +24,51 @@ type Querier interface {
 }
 
 func Get(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
-	rows, err := querier.Query(ctx, query, args...)
-	if err != nil {
-		return err
-	}
-	return ScanStruct(rows, dest)
+	return defaultScanner.Get(ctx, querier, dest, query, args...)
 }
 
 func Select(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
-	rows, err := querier.Query(ctx, query, args...)
-	if err != nil {
-		return err
-	}
-	return ScanStructs(rows, dest)
+	return defaultScanner.Select(ctx, querier, dest, query, args...)
+}
+
+// GetOne works like Get, but returns ErrTooManyRows if the query returns more than one row.
+func GetOne(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	return defaultScanner.GetOne(ctx, querier, dest, query, args...)
 }
 
 func SelectFlat(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
-	rows, err := querier.Query(ctx, query, args...)
+	rows, err := defaultScanner.query(ctx, querier, query, args)
 	if err != nil {
 		return err
 	}
-	return ScanFlat(rows, dest)
+	return ScanFlatContext(ctx, rows, dest)
+}
+
+// AfterScanner is implemented by the structs scanned into which need to run code once each row
+// is scanned into them, to decode packed fields, compute derived ones or validate invariants.
+// Its error aborts the scan.
+type AfterScanner interface {
+	AfterScan(ctx context.Context) error
+}
+
+// afterScanHook calls AfterScan on v, a pointer to a struct freshly scanned, if it implements
+// AfterScanner.
+func afterScanHook(ctx context.Context, v reflect.Value) error {
+	if hook, ok := v.Interface().(AfterScanner); ok {
+		if err := hook.AfterScan(ctx); err != nil {
+			return errors.Wrap(err, "AfterScan failed")
+		}
+	}
+	return nil
+}
+
+// BeforeScanner is implemented by the structs scanned into which need to inspect the columns of
+// the result before its first row is scanned, to pre-allocate buffers or reject incompatible
+// projections. It is called once per result, on the struct the first row is scanned into, once
+// zeroed: what it sets is kept by that struct only, not by the structs of the next rows, nor
+// by the struct of ScanEach, zeroed again before each next row. Its error aborts the scan.
+type BeforeScanner interface {
+	BeforeScan(fields []pgproto3.FieldDescription) error
 }
 
 // ScanStruct scans a pgx.Rows into destination struct passed by reference based on the "db" fields tags.
@@ -50,10 +77,53 @@ func SelectFlat(ctx context.Context, querier Querier, dest interface{}, query st
 //
 // If there are no rows pgx.ErrNoRows is returned.
 // If there are more than one row in the result - they are ignored.
+// Columns aliased with a dot-separated path, such as "author.id", are scanned into the fields
+// of nested structs, so joined tables can be scanned into a struct per table.
+// Fields without a column in the result are left untouched, so the same struct can be scanned
+// from queries selecting different columns. Tagging them with the omitmissing option, as in
+// `db:"legacy_col,omitmissing"`, documents it and keeps them optional in strict mode.
 // Function call closes rows, so caller may skip it.
 func ScanStruct(r pgx.Rows, dest interface{}) error {
+	return defaultScanner.ScanStruct(r, dest)
+}
+
+// ScanStruct works like the package-level ScanStruct, using the Scanner options.
+func (s *Scanner) ScanStruct(r pgx.Rows, dest interface{}) error {
 	defer r.Close()
 
+	return s.scanStruct(context.Background(), r, dest)
+}
+
+// ScanStructStrict works like ScanStruct, but returns ErrTooManyRows if the result has
+// more than one row, for queries expected to match a single row by a unique key.
+func ScanStructStrict(r pgx.Rows, dest interface{}) error {
+	return defaultScanner.ScanStructStrict(r, dest)
+}
+
+// ScanStructStrict works like the package-level ScanStructStrict, using the Scanner options.
+func (s *Scanner) ScanStructStrict(r pgx.Rows, dest interface{}) error {
+	defer r.Close()
+
+	return s.scanStructStrict(context.Background(), r, dest)
+}
+
+// scanStructStrict scans the single row of r into dest, leaving r open.
+func (s *Scanner) scanStructStrict(ctx context.Context, r pgx.Rows, dest interface{}) error {
+	if err := s.scanStruct(ctx, r, dest); err != nil {
+		return err
+	}
+	if r.Next() {
+		return ErrTooManyRows
+	}
+	return r.Err()
+}
+
+// scanStruct scans the first row of r into dest, leaving r open.
+func (s *Scanner) scanStruct(ctx context.Context, r pgx.Rows, dest interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+
 	v := reflect.ValueOf(dest)
 	if v.Kind() != reflect.Ptr {
 		return errors.New("dest must be a pointer to a struct, not a value")
@@ -69,23 +139,36 @@ func ScanStruct(r pgx.Rows, dest interface{}) error {
 		return pgx.ErrNoRows
 	}
 
-	columns, err := rowMetadata(r, v)
-	if err != nil {
-		return err
+	if fn, ok := s.generatedScanner(v.Type().Elem(), r); ok {
+		return scanGenerated(ctx, r, 0, v, fn)
 	}
 
-	fields := DefaultMapper.TraversalsByName(v.Type(), columns)
-	values := make([]interface{}, len(columns))
-
-	err = fieldsByTraversal(v, fields, values)
+	columns, fields, err := s.rowMetadata(r, v)
 	if err != nil {
 		return err
 	}
 
-	return r.Scan(values...)
+	oids := columnOIDs(r)
+	return s.scanRow(ctx, r, 0, v, columns, oids, fields, newRowDecoder(oids))
 }
 
+// ScanFlat scans the single column of each row of r into the slice dest passed by reference.
 func ScanFlat(r pgx.Rows, dest interface{}) error {
+	return defaultScanner.ScanFlat(r, dest)
+}
+
+// ScanFlat works like the package-level ScanFlat, using the Scanner options.
+func (s *Scanner) ScanFlat(r pgx.Rows, dest interface{}) error {
+	return s.ScanFlatContext(context.Background(), r, dest)
+}
+
+// ScanFlatContext works like ScanFlat, but stops scanning once ctx is done, see ScanStructsContext.
+func ScanFlatContext(ctx context.Context, r pgx.Rows, dest interface{}) error {
+	return defaultScanner.ScanFlatContext(ctx, r, dest)
+}
+
+// ScanFlatContext works like the package-level ScanFlatContext, using the Scanner options.
+func (s *Scanner) ScanFlatContext(ctx context.Context, r pgx.Rows, dest interface{}) error {
 	defer r.Close()
 
 	valDest := reflect.ValueOf(dest)
@@ -96,9 +179,17 @@ func ScanFlat(r pgx.Rows, dest interface{}) error {
 	typDest := valDest.Type()
 	typSlice := typDest.Elem()
 	typElem := typSlice.Elem()
-	valSlice := reflect.MakeSlice(typSlice, 0, 0)
+	valSlice := reflect.MakeSlice(typSlice, 0, s.capacity)
 
 	for r.Next() {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "scan aborted")
+		}
+
+		if s.maxRows > 0 && valSlice.Len() >= s.maxRows {
+			return ErrTooManyRows
+		}
+
 		valRow := reflect.New(typElem)
 		if err := r.Scan(valRow.Interface()); err != nil {
 			return errors.Wrap(err, "failed to parse a row")
@@ -112,10 +203,35 @@ func ScanFlat(r pgx.Rows, dest interface{}) error {
 
 // ScanStructs scans a pgx.Rows into destination structs list passed by reference based on the "db" fields tags
 func ScanStructs(r pgx.Rows, dest interface{}) error {
+	return defaultScanner.ScanStructs(r, dest)
+}
+
+// ScanStructs works like the package-level ScanStructs, using the Scanner options.
+func (s *Scanner) ScanStructs(r pgx.Rows, dest interface{}) error {
+	return s.ScanStructsContext(context.Background(), r, dest)
+}
+
+// ScanStructsContext works like ScanStructs, but stops scanning once ctx is done, returning its
+// error wrapped and closing r, so that a cancelled caller doesn't keep reading a large result.
+// Select and SelectFlat scan with the context of their query.
+func ScanStructsContext(ctx context.Context, r pgx.Rows, dest interface{}) error {
+	return defaultScanner.ScanStructsContext(ctx, r, dest)
+}
+
+// ScanStructsContext works like the package-level ScanStructsContext, using the Scanner options.
+func (s *Scanner) ScanStructsContext(ctx context.Context, r pgx.Rows, dest interface{}) error {
 	defer r.Close()
 
+	if s.err != nil {
+		return s.err
+	}
+
+	// the columns, their mapping and the scan destinations buffer are the same for every row
 	var (
 		columns []string
+		oids    []uint32
+		fields  [][]int
+		decoder *rowDecoder
 		err     error
 	)
 
@@ -126,6 +242,9 @@ func ScanStructs(r pgx.Rows, dest interface{}) error {
 
 	sliceType := destType.Elem() // either []test or []*test
 	elementType := sliceType.Elem()
+	if elementType.Kind() == reflect.Interface {
+		return s.scanPolymorphic(ctx, r, reflect.ValueOf(dest).Elem())
+	}
 
 	var structTypeToCreate *reflect.Type
 	if elementType.Kind() == reflect.Ptr {
@@ -135,9 +254,21 @@ func ScanStructs(r pgx.Rows, dest interface{}) error {
 		structTypeToCreate = &elementType
 	}
 
-	resultSlice := reflect.MakeSlice(sliceType, 0, 0)
+	resultSlice := reflect.MakeSlice(sliceType, 0, s.capacity)
+	var (
+		generated   func(pgx.Rows, reflect.Value) error
+		isGenerated bool
+	)
 
 	for r.Next() {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "scan aborted")
+		}
+
+		if s.maxRows > 0 && resultSlice.Len() >= s.maxRows {
+			return ErrTooManyRows
+		}
+
 		destVal := reflect.New(*structTypeToCreate)
 		if destVal.Kind() != reflect.Ptr {
 			return errors.New("must return a pointer to a new struct, not a value, to ScanStructs destination")
@@ -146,23 +277,26 @@ func ScanStructs(r pgx.Rows, dest interface{}) error {
 			return errors.New("nil pointer returned to ScanStructs destination")
 		}
 
-		if len(columns) == 0 {
-			columns, err = rowMetadata(r, destVal)
-			if err != nil {
+		if resultSlice.Len() == 0 {
+			generated, isGenerated = s.generatedScanner(*structTypeToCreate, r)
+		}
+		if isGenerated {
+			if err := scanGenerated(ctx, r, resultSlice.Len(), destVal, generated); err != nil {
 				return err
 			}
-		}
-
-		fields := DefaultMapper.TraversalsByName(destVal.Type(), columns)
-		values := make([]interface{}, len(columns))
-
-		err := fieldsByTraversal(destVal, fields, values)
-		if err != nil {
-			return err
-		}
+		} else {
+			if columns == nil {
+				columns, fields, err = s.rowMetadata(r, destVal)
+				if err != nil {
+					return err
+				}
+				oids = columnOIDs(r)
+				decoder = newRowDecoder(oids)
+			}
 
-		if err := r.Scan(values...); err != nil {
-			return err
+			if err := s.scanRow(ctx, r, resultSlice.Len(), destVal, columns, oids, fields, decoder); err != nil {
+				return err
+			}
 		}
 
 		// pointers are only applied directly
@@ -178,23 +312,126 @@ func ScanStructs(r pgx.Rows, dest interface{}) error {
 	return r.Err()
 }
 
-func rowMetadata(r pgx.Rows, v reflect.Value) (columns []string, err error) {
+// scanRow scans the current row of r, of index row, into the struct v, columns of types oids
+// being mapped to fields. values is the buffer of scan destinations, of the length of columns.
+// ctx is passed to the AfterScan method of v, if any.
+func (s *Scanner) scanRow(ctx context.Context, r pgx.Rows, row int, v reflect.Value, columns []string, oids []uint32, fields [][]int, d *rowDecoder) error {
+	conversions, err := s.fieldsByTraversal(v, columns, oids, fields, d.values)
+	if err != nil {
+		return err
+	}
+
+	if s.defaults != nil {
+		s.defaults.setDefaults(r, v, columns, fields, d.values, conversions)
+	}
+
+	if i, err := d.scan(r); err != nil {
+		return s.scanError(err, row, v, columns, oids, fields, i)
+	}
+
+	for i, convert := range conversions {
+		if convert == nil {
+			continue
+		}
+		if err := convert(); err != nil {
+			return s.scanError(err, row, v, columns, oids, fields, i)
+		}
+	}
+
+	if err := s.afterScan(v, columns, fields); err != nil {
+		return err
+	}
+	return afterScanHook(ctx, v)
+}
+
+// scanError wraps err, the error scanning or converting the column of index i of the row of
+// index row into the struct v, in a ScanError describing the column and the field it maps to.
+// i is negative if the column is unknown.
+func (s *Scanner) scanError(err error, row int, v reflect.Value, columns []string, oids []uint32, fields [][]int, i int) error {
+	scanErr := &ScanError{Row: row, Err: err}
+	if i < 0 {
+		return scanErr
+	}
+
+	scanErr.Column = columns[i]
+	scanErr.OID = oids[i]
+	if len(fields[i]) > 0 {
+		if fi := s.mapper().TypeMap(reflect.Indirect(v).Type()).GetByTraversal(fields[i]); fi != nil {
+			scanErr.Field = fi.Field.Name
+			scanErr.FieldType = fi.Field.Type
+		}
+	}
+	return scanErr
+}
+
+// columnOIDs returns the type OIDs of the columns of r.
+func columnOIDs(r pgx.Rows) []uint32 {
+	fieldDescriptions := r.FieldDescriptions()
+	oids := make([]uint32, len(fieldDescriptions))
+	for i, fieldDescription := range fieldDescriptions {
+		oids[i] = fieldDescription.DataTypeOID
+	}
+	return oids
+}
+
+func (s *Scanner) rowMetadata(r pgx.Rows, v reflect.Value) (columns []string, fields [][]int, err error) {
 	fieldDescriptions := r.FieldDescriptions()
 	columns = make([]string, len(fieldDescriptions))
 	for i, fieldDescription := range fieldDescriptions {
 		columns[i] = string(fieldDescription.Name)
 	}
 
-	fields := DefaultMapper.TraversalsByName(v.Type(), columns)
+	if hook, ok := v.Interface().(BeforeScanner); ok {
+		if err := hook.BeforeScan(fieldDescriptions); err != nil {
+			return columns, nil, errors.Wrap(err, "BeforeScan failed")
+		}
+	}
+
+	fields = s.traversalsByName(v.Type(), columns)
 
 	// if we are not unsafe and are missing fields, return an error
-	if f, err := missingFields(fields); err != nil {
-		return columns, fmt.Errorf("missing column %q in dest %s", columns[f], v.Type())
+	if f, err := missingFields(fields); err != nil && !s.unsafe {
+		return columns, fields, fmt.Errorf("missing column %q in dest %s", columns[f], v.Type())
+	}
+
+	if s.strict {
+		if column, ok := s.unscannedColumn(v.Type(), columns); ok {
+			return columns, fields, fmt.Errorf("column %q of dest %s is missing from the result", column, v.Type())
+		}
 	}
 
 	return
 }
 
+// unscannedColumn returns the column of a tagged field of t missing from columns, unless
+// the field has the omitmissing option.
+func (s *Scanner) unscannedColumn(t reflect.Type, columns []string) (string, bool) {
+	scanned := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		scanned[column] = true
+	}
+
+	for _, fi := range s.columnFields(reflectx.Deref(t)) {
+		if _, ok := fi.Options["omitmissing"]; ok || !s.tagged(fi.Field) {
+			continue
+		}
+		if !scanned[fi.Path] {
+			return fi.Path, true
+		}
+	}
+	return "", false
+}
+
+// tagged reports whether f has one of the tags naming columns.
+func (s *Scanner) tagged(f reflect.StructField) bool {
+	for _, tag := range s.tags() {
+		if f.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func missingFields(traversals [][]int) (field int, err error) {
 	for i, t := range traversals {
 		if len(t) == 0 {
@@ -204,25 +441,106 @@ func missingFields(traversals [][]int) (field int, err error) {
 	return 0, nil
 }
 
-func fieldsByTraversal(v reflect.Value, traversals [][]int, values []interface{}) error {
-	v = reflect.Indirect(v)
-	if v.Kind() != reflect.Struct {
-		return errors.New("argument is not a struct")
+// fieldsByTraversal fills values with the scan destinations of the fields found by traversals,
+// for columns of types oids. Fields pgx can't scan into directly get a conversion to run once
+// the row is scanned. Nil pointers to structs along the traversals are allocated, and reset
+// to nil if all of their columns are NULL.
+func (s *Scanner) fieldsByTraversal(v reflect.Value, columns []string, oids []uint32, traversals [][]int, values []interface{}) (conversions []func() error, err error) {
+	if reflect.Indirect(v).Kind() != reflect.Struct {
+		return nil, errors.New("argument is not a struct")
+	}
+
+	depths := make([]int, len(traversals))
+	for i, traversal := range traversals {
+		depths[i] = nilStructDepth(v, traversal)
 	}
 
+	tm := s.mapper().TypeMap(v.Type())
+
+	conversions = make([]func() error, len(traversals))
 	for i, traversal := range traversals {
 		if len(traversal) == 0 {
-			values[i] = new(interface{})
+			values[i] = discard{}
 			continue
 		}
 
 		f := reflectx.FieldByIndexes(v, traversal)
-		if f.Kind() == reflect.Ptr {
-			values[i] = f.Interface()
-		} else {
-			values[i] = f.Addr().Interface()
+		if u, ok := s.unions[columns[i]]; ok {
+			values[i], conversions[i], err = u.scanTarget(v, f, columns, traversals)
+			if err != nil {
+				return nil, err
+			}
+			continue
 		}
+		if _, ok := tm.GetByTraversal(traversal).Options["json"]; ok {
+			values[i], conversions[i] = s.jsonTarget(f)
+			continue
+		}
+		if fn, ok := s.converterFunc(f.Type()); ok {
+			values[i], conversions[i] = convertTarget(f, fn)
+			continue
+		}
+		if target, convert, ok := s.compositeTarget(f, oids[i]); ok {
+			values[i], conversions[i] = target, convert
+			continue
+		}
+		values[i], conversions[i] = scanTarget(f, oids[i])
 	}
 
-	return nil
+	keepNilStructs(v, oids, traversals, depths, values, conversions)
+
+	return conversions, nil
+}
+
+// scanTarget returns the destination passed to pgx for the field f, scanned from a column
+// of type oid. Pointer fields are scanned through their address, so that NULL resets them
+// to nil and a value allocates a new one, unless the pointed type decodes itself (pgtype
+// decoders, sql.Scanner). Fields of types pgx can't assign to are scanned through a converter.
+func scanTarget(f reflect.Value, oid uint32) (interface{}, func() error) {
+	if target, convert, ok := rangeTarget(f, oid); ok {
+		return target, convert
+	}
+	if target, convert, ok := numericTarget(f, oid); ok {
+		return target, convert
+	}
+	if target, convert, ok := intervalTarget(f, oid); ok {
+		return target, convert
+	}
+	if target, convert, ok := inetTarget(f, oid); ok {
+		return target, convert
+	}
+	if target, convert, ok := uuidTarget(f, oid); ok {
+		return target, convert
+	}
+	if target, convert, ok := blobTarget(f, oid); ok {
+		return target, convert
+	}
+	if f.Kind() == reflect.Ptr && isDecoder(f.Interface()) {
+		return f.Interface(), nil
+	}
+	if isDecoder(f.Addr().Interface()) {
+		return f.Addr().Interface(), nil
+	}
+	if c := converterFor(f.Type()); c != nil {
+		holder := reflect.New(c.holder)
+		return holder.Interface(), func() error {
+			return c.convert(f, holder.Elem())
+		}
+	}
+	return f.Addr().Interface(), nil
+}
+
+// discard is the scan destination of the columns without a field, which ignores their values.
+type discard struct{}
+
+func (discard) DecodeBinary(*pgtype.ConnInfo, []byte) error { return nil }
+
+func (discard) DecodeText(*pgtype.ConnInfo, []byte) error { return nil }
+
+func isDecoder(v interface{}) bool {
+	switch v.(type) {
+	case pgtype.BinaryDecoder, pgtype.TextDecoder, sql.Scanner:
+		return true
+	}
+	return false
 }
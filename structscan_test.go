@@ -2,11 +2,18 @@ package pgxscan
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
 	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +29,13 @@ type testMissingField struct {
 	CreatedAt time.Time `db:"created_at"`
 }
 
+type testOmitMissing struct {
+	ID        string    `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	SomeData  string    `db:"some_data"`
+	Legacy    string    `db:"legacy_col,omitmissing"`
+}
+
 func TestScanStruct(t *testing.T) {
 	connString := initDB(t)
 
@@ -52,6 +66,36 @@ func TestScanStruct(t *testing.T) {
 	require.Equal(t, err, pgx.ErrNoRows)
 }
 
+func TestScanStructStrict(t *testing.T) {
+	conn := connect(t)
+
+	e1, e2 := prepareData(t, conn)
+
+	rows := selectRows(t, conn, e1.ID, "foo")
+	result := new(testEntity)
+	err := ScanStructStrict(rows, result)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+	assert.Equal(t, e1.SomeData, result.SomeData)
+
+	resultOne := new(testEntity)
+	err = GetOne(context.Background(), conn, resultOne, "SELECT * FROM structscan_test WHERE id = $1", e2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e2.ID, resultOne.ID)
+
+	// test some fail cases
+	rowsMany := selectRows(t, conn, e1.ID, e2.ID)
+	err = ScanStructStrict(rowsMany, new(testEntity))
+	require.Error(t, err)
+	assert.Equal(t, ErrTooManyRows, err)
+
+	err = GetOne(context.Background(), conn, new(testEntity), "SELECT * FROM structscan_test WHERE id IN ($1, $2)", e1.ID, e2.ID)
+	assert.Equal(t, ErrTooManyRows, err)
+
+	err = GetOne(context.Background(), conn, new(testEntity), "SELECT * FROM structscan_test WHERE id = $1", "foo")
+	assert.Equal(t, pgx.ErrNoRows, err)
+}
+
 func TestScanStructs(t *testing.T) {
 	connString := initDB(t)
 
@@ -97,6 +141,231 @@ func TestScanStructs(t *testing.T) {
 	rowsFailMissing.Close()
 }
 
+func TestScanStructOmitMissing(t *testing.T) {
+	conn := connect(t)
+
+	e1, _ := prepareData(t, conn)
+
+	rows, err := conn.Query(context.Background(), "SELECT * FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	result := &testOmitMissing{Legacy: "untouched"}
+	err = ScanStruct(rows, result)
+	require.NoError(t, err)
+	assert.Equal(t, e1.ID, result.ID)
+	assert.Equal(t, "untouched", result.Legacy)
+
+	rows, err = conn.Query(context.Background(), "SELECT *, 'legacy' AS legacy_col FROM structscan_test WHERE id = $1", e1.ID)
+	require.NoError(t, err)
+	result = new(testOmitMissing)
+	err = ScanStruct(rows, result)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", result.Legacy)
+}
+
+type testAuthor struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+type testBookEntity struct {
+	ID     string     `db:"id"`
+	Title  string     `db:"title"`
+	Author testAuthor `db:"author"`
+}
+
+func TestScanStructsNested(t *testing.T) {
+	conn := connect(t)
+
+	rows, err := conn.Query(context.Background(), `
+		SELECT b.id, b.title, a.id AS "author.id", a.name AS "author.name"
+		FROM (VALUES ('book-1', 'Dune', 'author-1'), ('book-2', 'Emma', 'author-2')) AS b (id, title, author_id)
+		JOIN (VALUES ('author-1', 'Frank Herbert'), ('author-2', 'Jane Austen')) AS a (id, name) ON a.id = b.author_id
+		ORDER BY b.id ASC
+	`)
+	require.NoError(t, err)
+
+	var result []testBookEntity
+	err = ScanStructs(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, []testBookEntity{
+		{ID: "book-1", Title: "Dune", Author: testAuthor{ID: "author-1", Name: "Frank Herbert"}},
+		{ID: "book-2", Title: "Emma", Author: testAuthor{ID: "author-2", Name: "Jane Austen"}},
+	}, result)
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), `SELECT 'book-1' AS id, 'Dune' AS title, 'author-1' AS "author.uuid"`)
+	require.NoError(t, err)
+	err = ScanStruct(rows, new(testBookEntity))
+	require.Error(t, err)
+	assert.Equal(t, `missing column "author.uuid" in dest *pgxscan.testBookEntity`, err.Error())
+}
+
+type testAddress struct {
+	City    string  `db:"city"`
+	Country *string `db:"country"`
+}
+
+type testPublisherEntity struct {
+	*testAddress
+	ID     string      `db:"id"`
+	Editor *testAuthor `db:"editor"`
+}
+
+func TestScanStructsNilNested(t *testing.T) {
+	conn := connect(t)
+
+	rows, err := conn.Query(context.Background(), `
+		SELECT p.id, p.city, p.country, e.id AS "editor.id", e.name AS "editor.name"
+		FROM (VALUES
+			('publisher-1', 'Paris', 'France', 'author-1'),
+			('publisher-2', NULL, NULL, 'author-3'),
+			('publisher-3', 'Berlin', NULL, NULL)
+		) AS p (id, city, country, editor_id)
+		LEFT JOIN (VALUES ('author-1', 'Frank Herbert')) AS e (id, name) ON e.id = p.editor_id
+		ORDER BY p.id ASC
+	`)
+	require.NoError(t, err)
+
+	var result []testPublisherEntity
+	err = ScanStructs(rows, &result)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	assert.Equal(t, "publisher-1", result[0].ID)
+	require.NotNil(t, result[0].testAddress)
+	assert.Equal(t, "Paris", result[0].City)
+	require.NotNil(t, result[0].Country)
+	assert.Equal(t, "France", *result[0].Country)
+	assert.Equal(t, &testAuthor{ID: "author-1", Name: "Frank Herbert"}, result[0].Editor)
+
+	// all the columns of a nil struct are NULL, it stays nil
+	assert.Equal(t, "publisher-2", result[1].ID)
+	assert.Nil(t, result[1].testAddress)
+	assert.Nil(t, result[1].Editor)
+
+	assert.Equal(t, "publisher-3", result[2].ID)
+	require.NotNil(t, result[2].testAddress)
+	assert.Equal(t, "Berlin", result[2].City)
+	assert.Nil(t, result[2].Country)
+	assert.Nil(t, result[2].Editor)
+
+	// test some fail cases
+	rows, err = conn.Query(context.Background(), `SELECT 'publisher-4' AS id, NULL AS city, 'Spain' AS country, NULL AS "editor.id", NULL AS "editor.name"`)
+	require.NoError(t, err)
+	err = ScanStruct(rows, new(testPublisherEntity))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to scan column "city" (oid 25) of row 0 into field City of type string`)
+}
+
+type testTagsEntity struct {
+	ID   string   `db:"id"`
+	Tags []string `db:"tags"`
+}
+
+func TestScanStructsArrayAgg(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "tags_test", `
+		item_id text not null,
+		tag     text
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO tags_test (item_id, tag) VALUES
+			('item-1', 'foo'),
+			('item-1', 'bar'),
+			('item-2', NULL),
+			('item-3', 'baz')
+	`)
+	require.NoError(t, err)
+
+	rows, err := conn.Query(context.Background(), `
+		SELECT item_id AS id, COALESCE(array_agg(tag ORDER BY tag) FILTER (WHERE tag IS NOT NULL), '{}') AS tags
+		FROM tags_test
+		GROUP BY item_id
+		ORDER BY item_id ASC
+	`)
+	require.NoError(t, err)
+
+	var result []testTagsEntity
+	err = ScanStructs(rows, &result)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	assert.Equal(t, "item-1", result[0].ID)
+	assert.Equal(t, []string{"bar", "foo"}, result[0].Tags)
+	assert.Equal(t, "item-2", result[1].ID)
+	assert.NotNil(t, result[1].Tags)
+	assert.Empty(t, result[1].Tags)
+	assert.Equal(t, "item-3", result[2].ID)
+	assert.Equal(t, []string{"baz"}, result[2].Tags)
+}
+
+type testLevel int
+
+func (l *testLevel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	switch name {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", name)
+	}
+	return nil
+}
+
+type testPayload struct {
+	Name string
+}
+
+func (p *testPayload) UnmarshalJSON(data []byte) error {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.Name = strings.ToUpper(raw["name"])
+	return nil
+}
+
+type testJSONEntity struct {
+	ID       string       `db:"id"`
+	Level    testLevel    `db:"level"`
+	Payload  testPayload  `db:"payload"`
+	Optional *testPayload `db:"optional"`
+}
+
+func TestScanStructJSONUnmarshaler(t *testing.T) {
+	conn := connect(t)
+
+	rows, err := conn.Query(
+		context.Background(),
+		`SELECT 'json-1' AS id, '"high"'::jsonb AS level, '{"name": "foo"}'::jsonb AS payload, NULL::jsonb AS optional`,
+	)
+	require.NoError(t, err)
+	result := new(testJSONEntity)
+	err = ScanStruct(rows, result)
+	require.NoError(t, err)
+
+	assert.Equal(t, "json-1", result.ID)
+	assert.Equal(t, testLevel(2), result.Level)
+	assert.Equal(t, "FOO", result.Payload.Name)
+	assert.Nil(t, result.Optional)
+
+	// test some fail cases
+	rowsFail, err := conn.Query(
+		context.Background(),
+		`SELECT 'json-2' AS id, '"none"'::jsonb AS level, '{}'::jsonb AS payload, '{}'::jsonb AS optional`,
+	)
+	require.NoError(t, err)
+	err = ScanStruct(rowsFail, new(testJSONEntity))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown level "none"`)
+}
+
 func prepareData(t *testing.T, conn *pgx.Conn) (testEntity, testEntity) {
 	t.Helper()
 
@@ -159,3 +428,277 @@ func initDB(t *testing.T) string {
 
 	return connString
 }
+
+func connect(t *testing.T) *pgx.Conn {
+	t.Helper()
+
+	conn, err := pgx.Connect(context.Background(), initDB(t))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := conn.Close(context.Background())
+		assert.NoError(t, err)
+	})
+
+	return conn
+}
+
+func createTable(t *testing.T, conn *pgx.Conn, name, columns string) {
+	t.Helper()
+
+	_, err := conn.Exec(context.Background(), `DROP TABLE IF EXISTS `+name)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(context.Background(), `CREATE TABLE `+name+` (`+columns+`)`)
+	require.NoError(t, err)
+}
+
+// fakeRows serves rows of text values without a database, for benchmarks.
+type fakeRows struct {
+	pgx.Rows
+	ci     *pgtype.ConnInfo
+	fields []pgproto3.FieldDescription
+	rows   [][][]byte
+	row    int
+}
+
+func newFakeRows(n int) *fakeRows {
+	r := &fakeRows{
+		ci: pgtype.NewConnInfo(),
+		fields: []pgproto3.FieldDescription{
+			{Name: []byte("id"), DataTypeOID: pgtype.TextOID},
+			{Name: []byte("created_at"), DataTypeOID: pgtype.TimestamptzOID},
+			{Name: []byte("some_data"), DataTypeOID: pgtype.TextOID},
+		},
+		row: -1,
+	}
+	for i := 0; i < n; i++ {
+		r.rows = append(r.rows, [][]byte{
+			[]byte(fmt.Sprintf("bench-%d", i)),
+			[]byte("2020-01-01 00:00:00+00"),
+			[]byte("foo bar baz"),
+		})
+	}
+	return r
+}
+
+func (r *fakeRows) Close()                                         {}
+func (r *fakeRows) Err() error                                     { return nil }
+func (r *fakeRows) FieldDescriptions() []pgproto3.FieldDescription { return r.fields }
+func (r *fakeRows) RawValues() [][]byte                            { return r.rows[r.row] }
+
+func (r *fakeRows) Next() bool {
+	r.row++
+	return r.row < len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	for i, d := range dest {
+		if d == nil {
+			continue
+		}
+		if err := r.ci.Scan(r.fields[i].DataTypeOID, pgtype.TextFormatCode, r.rows[r.row][i], d); err != nil {
+			// like pgx
+			return fmt.Errorf("can't scan into dest[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func TestScanStructsScanError(t *testing.T) {
+	type testWrongType struct {
+		ID        string    `db:"id"`
+		CreatedAt time.Time `db:"created_at"`
+		SomeData  int       `db:"some_data"`
+	}
+
+	rows := newFakeRows(3)
+	var result []testWrongType
+	err := ScanStructs(rows, &result)
+	require.Error(t, err)
+
+	var scanErr *ScanError
+	require.True(t, errors.As(err, &scanErr))
+	assert.Equal(t, 0, scanErr.Row)
+	assert.Equal(t, "some_data", scanErr.Column)
+	assert.Equal(t, uint32(pgtype.TextOID), scanErr.OID)
+	assert.Equal(t, "SomeData", scanErr.Field)
+	assert.Equal(t, reflect.TypeOf(0), scanErr.FieldType)
+	assert.Contains(t, err.Error(), `failed to scan column "some_data" (oid 25) of row 0 into field SomeData of type int: `)
+
+	rows = newFakeRows(3)
+	rows.rows[2][1] = []byte("2020-13-45 00:00:00+00")
+	err = ScanStructs(rows, &[]testEntity{})
+	require.True(t, errors.As(err, &scanErr))
+	assert.Equal(t, 2, scanErr.Row)
+	assert.Equal(t, "created_at", scanErr.Column)
+	assert.Equal(t, "CreatedAt", scanErr.Field)
+}
+
+func TestScanStructsContext(t *testing.T) {
+	var result []testEntity
+	err := ScanStructsContext(context.Background(), newFakeRows(3), &result)
+	require.NoError(t, err)
+	assert.Len(t, result, 3)
+
+	var ids []string
+	rows := newFakeRows(3)
+	rows.fields = rows.fields[:1]
+	err = ScanFlatContext(context.Background(), rows, &ids)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bench-0", "bench-1", "bench-2"}, ids)
+
+	// test some fail cases
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ScanStructsContext(ctx, newFakeRows(3), &result)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	rows = newFakeRows(3)
+	rows.fields = rows.fields[:1]
+	err = ScanFlatContext(ctx, rows, &ids)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+type testAfterScanEntity struct {
+	testEntity
+	Words []string
+}
+
+func (e *testAfterScanEntity) AfterScan(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if e.ID == "bench-2" {
+		return errors.New("invalid id")
+	}
+	e.Words = strings.Fields(e.SomeData)
+	return nil
+}
+
+func TestScanStructsAfterScan(t *testing.T) {
+	var result []*testAfterScanEntity
+	err := ScanStructs(newFakeRows(2), &result)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, result[0].Words)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, result[1].Words)
+
+	var one testAfterScanEntity
+	err = ScanStruct(newFakeRows(1), &one)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, one.Words)
+
+	// test some fail cases
+	err = ScanStructs(newFakeRows(3), &result)
+	require.Error(t, err)
+	assert.Equal(t, "AfterScan failed: invalid id", err.Error())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ScanEach(newFakeRows(1), &one, func() error { return nil })
+	require.NoError(t, err)
+	err = defaultScanner.scanEach(ctx, newFakeRows(1), &one, func() error { return nil })
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+type testBeforeScanEntity struct {
+	testEntity
+	Columns []string
+}
+
+func (e *testBeforeScanEntity) BeforeScan(fields []pgproto3.FieldDescription) error {
+	for _, field := range fields {
+		if field.DataTypeOID == pgtype.ByteaOID {
+			return errors.Errorf("unexpected bytea column %q", field.Name)
+		}
+		e.Columns = append(e.Columns, string(field.Name))
+	}
+	return nil
+}
+
+func TestScanStructsBeforeScan(t *testing.T) {
+	var result []testBeforeScanEntity
+	err := ScanStructs(newFakeRows(3), &result)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	// only called on the first struct
+	assert.Equal(t, []string{"id", "created_at", "some_data"}, result[0].Columns)
+	assert.Nil(t, result[1].Columns)
+
+	var one testBeforeScanEntity
+	err = ScanStruct(newFakeRows(1), &one)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "created_at", "some_data"}, one.Columns)
+
+	var each [][]string
+	err = ScanEach(newFakeRows(2), &one, func() error {
+		each = append(each, one.Columns)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"id", "created_at", "some_data"}, nil}, each)
+
+	// test some fail cases
+	rows := newFakeRows(1)
+	rows.fields[2].DataTypeOID = pgtype.ByteaOID
+	err = ScanStructs(rows, &result)
+	require.Error(t, err)
+	assert.Equal(t, `BeforeScan failed: unexpected bytea column "some_data"`, err.Error())
+}
+
+func BenchmarkScanStruct(b *testing.B) {
+	rows := newFakeRows(1)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rows.row = -1
+		var result testEntity
+		if err := ScanStruct(rows, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanStructs(b *testing.B) {
+	rows := newFakeRows(100)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rows.row = -1
+		var result []testEntity
+		if err := ScanStructs(rows, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestScanStructsAllocs(t *testing.T) {
+	rows := newFakeRows(100)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		rows.row = -1
+		var result []testEntity
+		if err := ScanStructs(rows, &result); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// the per-row work is limited to the scan destinations and the decoding itself
+	assert.Less(t, allocs/100, float64(6))
+}
+
+func BenchmarkScanStructsCapacityHint(b *testing.B) {
+	rows := newFakeRows(100)
+	scanner := New(WithCapacityHint(100))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rows.row = -1
+		var result []testEntity
+		if err := scanner.ScanStructs(rows, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
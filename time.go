@@ -0,0 +1,36 @@
+package pgxscan
+
+import (
+	"reflect"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// WithTimeLocation converts the time.Time fields to loc after each row is scanned.
+// Pointers to time.Time and slices of either are converted as well, element by element.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(s *Scanner) {
+		s.location = loc
+	}
+}
+
+// setLocation converts v, a time.Time or a pointer or slice of them, to loc.
+func setLocation(v reflect.Value, loc *time.Location) {
+	switch {
+	case v.Type() == timeType:
+		v.Set(reflect.ValueOf(v.Interface().(time.Time).In(loc)))
+	case v.Kind() == reflect.Ptr:
+		if !v.IsNil() {
+			setLocation(v.Elem(), loc)
+		}
+	case v.Kind() == reflect.Slice:
+		elemType := v.Type().Elem()
+		if elemType != timeType && (elemType.Kind() != reflect.Ptr || elemType.Elem() != timeType) {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			setLocation(v.Index(i), loc)
+		}
+	}
+}
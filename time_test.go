@@ -0,0 +1,54 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTimeArrayEntity struct {
+	ID     string      `db:"id"`
+	SeenAt []time.Time `db:"seen_at"`
+}
+
+func TestScanStructsTimestamptzArray(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "time_array_test", `
+		id      text PRIMARY KEY,
+		seen_at timestamptz[]
+	`)
+
+	t1 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	t2 := time.Date(2020, 6, 7, 8, 9, 10, 0, time.UTC)
+	_, err := conn.Exec(
+		context.Background(),
+		"INSERT INTO time_array_test (id, seen_at) VALUES ($1, $2), ($3, '{}'), ($4, NULL)",
+		"time-array-1", []time.Time{t1, t2},
+		"time-array-2",
+		"time-array-3",
+	)
+	require.NoError(t, err)
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	scanner := New(WithTimeLocation(loc))
+
+	var result []*testTimeArrayEntity
+	err = scanner.Select(context.Background(), conn, &result, "SELECT * FROM time_array_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	require.Len(t, result[0].SeenAt, 2)
+	assert.True(t, t1.Equal(result[0].SeenAt[0]))
+	assert.True(t, t2.Equal(result[0].SeenAt[1]))
+	for _, seenAt := range result[0].SeenAt {
+		assert.Equal(t, loc, seenAt.Location())
+	}
+
+	assert.NotNil(t, result[1].SeenAt)
+	assert.Empty(t, result[1].SeenAt)
+	assert.Nil(t, result[2].SeenAt)
+}
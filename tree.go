@@ -0,0 +1,154 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// SelectTree scans the query result of an adjacency list, such as categories with a parent_id,
+// into new Ts and returns the roots of the tree they form, see BuildTree.
+func SelectTree[T any](ctx context.Context, querier Querier, query string, args ...interface{}) ([]*T, error) {
+	var roots []*T
+	if err := defaultScanner.SelectTree(ctx, querier, &roots, query, args...); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// SelectTree works like the package-level SelectTree, using the Scanner options, dest being a
+// pointer to the []*T of the roots, replaced.
+func (s *Scanner) SelectTree(ctx context.Context, querier Querier, dest interface{}, query string, args ...interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("expected a pointer to a slice, got %T", dest)
+	}
+
+	nodes := reflect.New(v.Elem().Type())
+	if err := s.Select(ctx, querier, nodes.Interface(), query, args...); err != nil {
+		return err
+	}
+	return s.BuildTree(nodes.Elem().Interface(), dest)
+}
+
+// BuildTree appends each of nodes to the children of the node its parent field refers to, and
+// returns the nodes without a parent, in their order in nodes, as children are.
+//
+// The fields are mapped to the id and parent_id columns, and the children are the []*T field
+// mapped to children, unless fields are tagged with the treeid, treeparent and treechildren
+// options. The id and parent fields must be comparable, and the parent field may be a pointer,
+// nil for roots. The nodes which parent is not in nodes are roots too, so that a subtree can be
+// selected. A cycle fails the build. The children fields are reset, so that nodes can be built
+// into a tree again.
+func BuildTree[T any](nodes []*T) ([]*T, error) {
+	var roots []*T
+	if err := defaultScanner.BuildTree(nodes, &roots); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// BuildTree works like the package-level BuildTree, mapping the fields with the Scanner options,
+// nodes being a []*T and roots a pointer to the []*T of the roots, replaced.
+func (s *Scanner) BuildTree(nodes interface{}, roots interface{}) error {
+	nodesVal := reflect.ValueOf(nodes)
+	rootsVal := reflect.ValueOf(roots)
+	if nodesVal.Kind() != reflect.Slice || nodesVal.Type().Elem().Kind() != reflect.Ptr {
+		return errors.Errorf("expected a slice of pointers, got %T", nodes)
+	}
+	sliceType := nodesVal.Type()
+	if rootsVal.Kind() != reflect.Ptr || rootsVal.IsNil() || rootsVal.Elem().Type() != sliceType {
+		return errors.Errorf("expected a pointer to a %s, got %T", sliceType, roots)
+	}
+	t := sliceType.Elem().Elem()
+	if t.Kind() != reflect.Struct {
+		return errors.Errorf("expected a struct type, got %s", t)
+	}
+
+	tm := s.mapper().TypeMap(t)
+	id, err := treeField(tm, "treeid", "id")
+	if err != nil {
+		return err
+	}
+	parent, err := treeField(tm, "treeparent", "parent_id")
+	if err != nil {
+		return err
+	}
+	children, err := treeField(tm, "treechildren", "children")
+	if err != nil {
+		return err
+	}
+	if children.Field.Type != sliceType {
+		return errors.Errorf("children field %s of %s is a %s, not a %s", children.Field.Name, t, children.Field.Type, sliceType)
+	}
+	for _, fi := range []*reflectx.FieldInfo{id, parent} {
+		// the values of interfaces may not be comparable either
+		if ft := reflectx.Deref(fi.Field.Type); !ft.Comparable() || ft.Kind() == reflect.Interface {
+			return errors.Errorf("field %s of %s is a %s, which can't be compared", fi.Field.Name, t, fi.Field.Type)
+		}
+	}
+
+	// the children of a previous build are replaced
+	byID := make(map[interface{}]reflect.Value, nodesVal.Len())
+	for i := 0; i < nodesVal.Len(); i++ {
+		v := nodesVal.Index(i).Elem()
+		byID[treeKey(reflectx.FieldByIndexesReadOnly(v, id.Index))] = v
+		reflectx.FieldByIndexes(v, children.Index).Set(reflect.Zero(sliceType))
+	}
+
+	result := reflect.Zero(sliceType)
+	for i := 0; i < nodesVal.Len(); i++ {
+		node := nodesVal.Index(i)
+		parentID := treeKey(reflectx.FieldByIndexesReadOnly(node.Elem(), parent.Index))
+		p, ok := byID[parentID]
+		if parentID == nil || !ok {
+			result = reflect.Append(result, node)
+			continue
+		}
+		f := reflectx.FieldByIndexes(p, children.Index)
+		f.Set(reflect.Append(f, node))
+	}
+
+	// the nodes of a cycle are not reachable from the roots
+	reached := 0
+	stack := reflect.AppendSlice(reflect.Zero(sliceType), result)
+	for stack.Len() > 0 {
+		// the struct, as the slot of its pointer is reused by the append
+		node := stack.Index(stack.Len() - 1).Elem()
+		stack = stack.Slice(0, stack.Len()-1)
+		reached++
+		stack = reflect.AppendSlice(stack, reflectx.FieldByIndexesReadOnly(node, children.Index))
+	}
+	if reached != nodesVal.Len() {
+		return errors.Errorf("%d nodes of %s are in a cycle", nodesVal.Len()-reached, t)
+	}
+
+	rootsVal.Elem().Set(result)
+	return nil
+}
+
+// treeField returns the field of tm tagged with option, or mapped to the column named column.
+func treeField(tm *reflectx.StructMap, option, column string) (*reflectx.FieldInfo, error) {
+	for _, fi := range tm.Index {
+		if _, ok := fi.Options[option]; ok {
+			return fi, nil
+		}
+	}
+	if fi := tm.GetByPath(column); fi != nil {
+		return fi, nil
+	}
+	return nil, errors.Errorf("missing field for column %q or tagged with the %s option", column, option)
+}
+
+// treeKey returns the value of the id or parent field f as a map key, nil for a nil pointer.
+func treeKey(f reflect.Value) interface{} {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+	return f.Interface()
+}
@@ -0,0 +1,139 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCategory struct {
+	ID       int             `db:"id"`
+	ParentID *int            `db:"parent_id"`
+	Name     string          `db:"name"`
+	Children []*testCategory `db:"children"`
+}
+
+type testOrgUnit struct {
+	Code      string         `db:"code,treeid"`
+	Manager   string         `db:"manager,treeparent"`
+	Reports   []*testOrgUnit `db:"reports,treechildren"`
+	Headcount int            `db:"headcount"`
+}
+
+func TestSelectTree(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "tree_test", `
+		id        int PRIMARY KEY,
+		parent_id int REFERENCES tree_test (id),
+		name      text NOT NULL
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO tree_test (id, parent_id, name) VALUES
+			(1, NULL, 'Books'),
+			(2, 1, 'Fiction'),
+			(3, 2, 'Fantasy'),
+			(4, 1, 'Science'),
+			(5, NULL, 'Music')
+	`)
+	require.NoError(t, err)
+
+	roots, err := SelectTree[testCategory](context.Background(), conn, "SELECT * FROM tree_test ORDER BY id ASC")
+	require.NoError(t, err)
+	require.Len(t, roots, 2)
+	assert.Equal(t, "Books", roots[0].Name)
+	require.Len(t, roots[0].Children, 2)
+	assert.Equal(t, "Fiction", roots[0].Children[0].Name)
+	require.Len(t, roots[0].Children[0].Children, 1)
+	assert.Equal(t, "Fantasy", roots[0].Children[0].Children[0].Name)
+	assert.Equal(t, "Science", roots[0].Children[1].Name)
+	assert.Empty(t, roots[0].Children[1].Children)
+	assert.Equal(t, "Music", roots[1].Name)
+
+	// a subtree
+	roots, err = SelectTree[testCategory](context.Background(), conn, "SELECT * FROM tree_test WHERE id IN (2, 3)")
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	assert.Equal(t, "Fiction", roots[0].Name)
+	require.Len(t, roots[0].Children, 1)
+
+	roots, err = SelectTree[testCategory](context.Background(), conn, "SELECT * FROM tree_test WHERE id = 42")
+	require.NoError(t, err)
+	assert.Empty(t, roots)
+
+	// test some fail cases
+	_, err = SelectTree[testCategory](context.Background(), conn, "SELECT 'foo' AS id")
+	require.Error(t, err)
+}
+
+func TestBuildTree(t *testing.T) {
+	units := []*testOrgUnit{
+		{Code: "ceo"},
+		{Code: "cto", Manager: "ceo"},
+		{Code: "dev", Manager: "cto"},
+		{Code: "cfo", Manager: "ceo"},
+	}
+
+	roots, err := BuildTree(units)
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	assert.Equal(t, "ceo", roots[0].Code)
+	require.Len(t, roots[0].Reports, 2)
+	assert.Equal(t, "cto", roots[0].Reports[0].Code)
+	assert.Equal(t, []*testOrgUnit{units[2]}, roots[0].Reports[0].Reports)
+	assert.Equal(t, "cfo", roots[0].Reports[1].Code)
+
+	// building again doesn't append the children twice
+	units[3].Manager = "cto"
+	roots, err = BuildTree(units)
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	require.Len(t, roots[0].Reports, 1)
+	assert.Equal(t, []*testOrgUnit{units[2], units[3]}, roots[0].Reports[0].Reports)
+
+	roots, err = BuildTree([]*testOrgUnit{})
+	require.NoError(t, err)
+	assert.Empty(t, roots)
+
+	// the fields are mapped with the Scanner options
+	type node struct {
+		Key      string  `sql:"key,treeid"`
+		Parent   *string `sql:"parent,treeparent"`
+		Children []*node `sql:"children"`
+	}
+	root := "a"
+	nodes := []*node{{Key: "a"}, {Key: "b", Parent: &root}}
+	var nodeRoots []*node
+	err = New(WithTagName("sql")).BuildTree(nodes, &nodeRoots)
+	require.NoError(t, err)
+	assert.Equal(t, []*node{nodes[0]}, nodeRoots)
+	assert.Equal(t, []*node{nodes[1]}, nodes[0].Children)
+
+	// test some fail cases
+	_, err = BuildTree([]*testOrgUnit{{Code: "a", Manager: "b"}, {Code: "b", Manager: "a"}})
+	require.Error(t, err)
+	assert.Equal(t, "2 nodes of pgxscan.testOrgUnit are in a cycle", err.Error())
+
+	_, err = BuildTree([]*testBook{{ID: "book-1"}})
+	require.Error(t, err)
+	assert.Equal(t, `missing field for column "parent_id" or tagged with the treeparent option`, err.Error())
+
+	type wrongChildren struct {
+		ID       int            `db:"id"`
+		ParentID int            `db:"parent_id"`
+		Children []testCategory `db:"children"`
+	}
+	_, err = BuildTree([]*wrongChildren{})
+	require.Error(t, err)
+
+	type wrongID struct {
+		ID       []byte     `db:"id"`
+		ParentID []byte     `db:"parent_id"`
+		Children []*wrongID `db:"children"`
+	}
+	_, err = BuildTree([]*wrongID{{ID: []byte("a")}})
+	require.Error(t, err)
+	assert.Equal(t, "field ID of pgxscan.wrongID is a []uint8, which can't be compared", err.Error())
+}
@@ -0,0 +1,125 @@
+package pgxscan
+
+import (
+	"context"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+)
+
+// Beginner is implemented by pgx.Conn, pgxpool.Pool, and pgx.Tx with savepoints.
+type Beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithTx runs fn in a transaction begun with db, committed if fn succeeds, and rolled back
+// if fn returns an error or panics. The error of fn is returned as is, and a panic is
+// propagated once the transaction is rolled back.
+func WithTx(ctx context.Context, db Beginner, fn func(tx Querier) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	return runTx(ctx, tx, fn)
+}
+
+// TxBeginner is implemented by pgx.Conn and pgxpool.Pool.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// WithReadOnlyTx runs fn in a READ ONLY transaction begun with db like WithTx, for queries that
+// must not modify the database. The transaction has the isolation level isoLevel, or the default
+// one if it is empty, such as pgx.RepeatableRead for consistent reports over several queries.
+func WithReadOnlyTx(ctx context.Context, db TxBeginner, isoLevel pgx.TxIsoLevel, fn func(tx Querier) error) error {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: isoLevel, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	return runTx(ctx, tx, fn)
+}
+
+// runTx runs fn in tx, committing it if fn succeeds and rolling it back otherwise.
+func runTx(ctx context.Context, tx pgx.Tx, fn func(tx Querier) error) error {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// WithSavepoint runs fn within the transaction tx like WithTx, in a savepoint released if fn
+// succeeds and rolled back to otherwise, so that functions running in the transaction of their
+// caller can fail without aborting it.
+func WithSavepoint(ctx context.Context, tx pgx.Tx, fn func(tx Querier) error) error {
+	// pgx.Tx begins pseudo nested transactions with SAVEPOINT, commits them with RELEASE SAVEPOINT
+	// and rolls them back with ROLLBACK TO SAVEPOINT
+	return WithTx(ctx, tx, fn)
+}
+
+// RetryOption configures WithTxRetry.
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	attempts int
+	backoff  func(attempt int) time.Duration
+}
+
+// RetryAttempts sets the number of times WithTxRetry runs a transaction at most, 5 by default.
+func RetryAttempts(n int) RetryOption {
+	return func(c *retryConfig) {
+		c.attempts = n
+	}
+}
+
+// RetryBackoff sets the delay WithTxRetry waits before running a transaction again after
+// the given failed attempt, counted from 1. It doubles from 10ms by default.
+func RetryBackoff(fn func(attempt int) time.Duration) RetryOption {
+	return func(c *retryConfig) {
+		c.backoff = fn
+	}
+}
+
+// WithTxRetry runs fn in a transaction like WithTx, running it again in a new transaction
+// when it fails with a serialization failure or a deadlock, SQLSTATE 40001 or 40P01, as
+// SERIALIZABLE transactions are expected to. fn must be safe to run several times.
+// The error of the last attempt is returned, or the error of ctx if it is done while waiting.
+func WithTxRetry(ctx context.Context, db Beginner, fn func(tx Querier) error, opts ...RetryOption) error {
+	c := retryConfig{
+		attempts: 5,
+		backoff: func(attempt int) time.Duration {
+			return 10 * time.Millisecond << (attempt - 1)
+		},
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := WithTx(ctx, db, fn)
+		if err == nil || attempt >= c.attempts || !isRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryable reports whether err is a serialization failure or a deadlock.
+func isRetryable(err error) bool {
+	code := SQLState(err)
+	return code == "40001" || code == "40P01"
+}
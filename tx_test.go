@@ -0,0 +1,160 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTx(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+
+	err := WithTx(context.Background(), conn, func(tx Querier) error {
+		return Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "foo"}, "id", "created_at")
+	})
+	require.NoError(t, err)
+
+	count, err := Count(context.Background(), conn, "SELECT count(*) FROM crud_test")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// test some fail cases
+	errRollback := errors.New("rollback")
+	err = WithTx(context.Background(), conn, func(tx Querier) error {
+		require.NoError(t, Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "bar"}, "id", "created_at"))
+		return errRollback
+	})
+	assert.Equal(t, errRollback, err)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = WithTx(context.Background(), conn, func(tx Querier) error {
+			require.NoError(t, Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "baz"}, "id", "created_at"))
+			panic("boom")
+		})
+	})
+
+	count, err = Count(context.Background(), conn, "SELECT count(*) FROM crud_test")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestWithTxRetry(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+
+	attempts := 0
+	err := WithTxRetry(context.Background(), conn, func(tx Querier) error {
+		attempts++
+		if err := Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "foo"}, "id", "created_at"); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return errors.Wrap(&pgconn.PgError{Code: "40001"}, "failed")
+		}
+		return nil
+	}, RetryBackoff(func(int) time.Duration { return time.Millisecond }))
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	count, err := Count(context.Background(), conn, "SELECT count(*) FROM crud_test")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// test some fail cases
+	attempts = 0
+	err = WithTxRetry(context.Background(), conn, func(tx Querier) error {
+		attempts++
+		return &pgconn.PgError{Code: "40P01"}
+	}, RetryAttempts(2), RetryBackoff(func(int) time.Duration { return time.Millisecond }))
+	require.Error(t, err)
+	assert.Equal(t, 2, attempts)
+
+	attempts = 0
+	errOther := errors.New("other")
+	err = WithTxRetry(context.Background(), conn, func(tx Querier) error {
+		attempts++
+		return errOther
+	})
+	assert.Equal(t, errOther, err)
+	assert.Equal(t, 1, attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = WithTxRetry(ctx, conn, func(tx Querier) error {
+		cancel()
+		return &pgconn.PgError{Code: "40001"}
+	}, RetryBackoff(func(int) time.Duration { return time.Hour }))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestWithSavepoint(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+
+	errRollback := errors.New("rollback")
+	err := WithTx(context.Background(), conn, func(tx Querier) error {
+		require.NoError(t, Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "foo"}, "id", "created_at"))
+
+		err := WithSavepoint(context.Background(), tx.(pgx.Tx), func(tx Querier) error {
+			require.NoError(t, Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "bar"}, "id", "created_at"))
+			return nil
+		})
+		require.NoError(t, err)
+
+		// a failing savepoint leaves the transaction usable
+		err = WithSavepoint(context.Background(), tx.(pgx.Tx), func(tx Querier) error {
+			return Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "foo"}, "id", "created_at")
+		})
+		require.Error(t, err)
+
+		err = WithSavepoint(context.Background(), tx.(pgx.Tx), func(tx Querier) error {
+			require.NoError(t, Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "baz"}, "id", "created_at"))
+			return errRollback
+		})
+		assert.Equal(t, errRollback, err)
+		return nil
+	})
+	require.NoError(t, err)
+
+	var names []string
+	err = Pluck(context.Background(), conn, &names, "name", "SELECT * FROM crud_test ORDER BY name ASC")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar", "foo"}, names)
+}
+
+func TestWithReadOnlyTx(t *testing.T) {
+	conn := connect(t)
+	createCrudTable(t, conn)
+	require.NoError(t, Insert(context.Background(), conn, "crud_test", &testCrudEntity{Name: "foo"}, "id", "created_at"))
+
+	var count int64
+	err := WithReadOnlyTx(context.Background(), conn, pgx.RepeatableRead, func(tx Querier) error {
+		var err error
+		count, err = Count(context.Background(), tx, "SELECT count(*) FROM crud_test")
+		if err != nil {
+			return err
+		}
+
+		var isoLevel string
+		if err := GetFlat(context.Background(), tx, &isoLevel, "SHOW transaction_isolation"); err != nil {
+			return err
+		}
+		assert.Equal(t, "repeatable read", isoLevel)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// test some fail cases
+	err = WithReadOnlyTx(context.Background(), conn, "", func(tx Querier) error {
+		return Insert(context.Background(), tx, "crud_test", &testCrudEntity{Name: "bar"}, "id", "created_at")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read-only transaction")
+}
@@ -0,0 +1,119 @@
+package pgxscan
+
+import (
+	"context"
+
+	"github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// RegisterCitext registers the citext extension type on the connection, so citext columns
+// are decoded like text and scan into string and *string fields. Extension types get their
+// OID assigned at CREATE EXTENSION time, so it is resolved from the server on every call.
+//
+// The signature matches pgxpool.Config.AfterConnect, so it can be used to set up each pool connection.
+func RegisterCitext(ctx context.Context, conn *pgx.Conn) error {
+	var oid uint32
+	if err := conn.QueryRow(ctx, "SELECT 'citext'::regtype::oid").Scan(&oid); err != nil {
+		return errors.Wrap(err, "failed to resolve the citext type")
+	}
+
+	conn.ConnInfo().RegisterDataType(pgtype.DataType{
+		Value: &pgtype.Text{},
+		Name:  "citext",
+		OID:   oid,
+	})
+	return nil
+}
+
+// UserType names a user-defined type to register with RegisterTypes.
+type UserType struct {
+	// Name is the name of the type, optionally qualified by a schema.
+	Name string
+	// Value, if not nil, is a value of the Go type encoded as the type when passed as a query
+	// argument, such as a named string type for an enum.
+	Value interface{}
+}
+
+// RegisterTypes returns a function registering the user-defined enum, domain and composite types
+// on a connection, along with their array types, so columns of these types are decoded, and scan
+// into the fields of the corresponding Go types. Their OIDs are resolved from the server, as they
+// are assigned at CREATE TYPE time. Composite types must be registered after the user-defined
+// types of their attributes.
+//
+// The returned function matches pgxpool.Config.AfterConnect, so it can be used to set up each
+// pool connection.
+func RegisterTypes(types ...UserType) func(ctx context.Context, conn *pgx.Conn) error {
+	return func(ctx context.Context, conn *pgx.Conn) error {
+		for _, t := range types {
+			if err := registerType(ctx, conn, t); err != nil {
+				return errors.Wrapf(err, "failed to register the %s type", t.Name)
+			}
+		}
+		return nil
+	}
+}
+
+func registerType(ctx context.Context, conn *pgx.Conn, t UserType) error {
+	var (
+		oid, arrayOID, baseOID, relID uint32
+		kind                          string
+	)
+	err := conn.QueryRow(
+		ctx,
+		"SELECT oid, typarray, typtype::text, typbasetype, typrelid FROM pg_type WHERE oid = $1::regtype::oid",
+		t.Name,
+	).Scan(&oid, &arrayOID, &kind, &baseOID, &relID)
+	if err != nil {
+		return err
+	}
+
+	ci := conn.ConnInfo()
+	var value pgtype.Value
+	switch kind {
+	case "e":
+		var members []string
+		err := SelectFlat(ctx, conn, &members, "SELECT enumlabel FROM pg_enum WHERE enumtypid = $1 ORDER BY enumsortorder", oid)
+		if err != nil {
+			return err
+		}
+		value = pgtype.NewEnumType(t.Name, members)
+	case "d":
+		base, ok := ci.DataTypeForOID(baseOID)
+		if !ok {
+			return errors.Errorf("unknown base type oid %d", baseOID)
+		}
+		value = base.Value
+	case "c":
+		var fields []pgtype.CompositeTypeField
+		err := Select(
+			ctx, conn, &fields,
+			"SELECT attname AS name, atttypid AS oid FROM pg_attribute WHERE attrelid = $1 AND attnum > 0 AND NOT attisdropped ORDER BY attnum",
+			relID,
+		)
+		if err != nil {
+			return err
+		}
+		if value, err = pgtype.NewCompositeType(t.Name, fields, ci); err != nil {
+			return err
+		}
+	default:
+		return errors.New("not an enum, domain or composite type")
+	}
+
+	ci.RegisterDataType(pgtype.DataType{Value: value, Name: t.Name, OID: oid})
+	if element, ok := value.(pgtype.ValueTranscoder); ok && arrayOID != 0 {
+		ci.RegisterDataType(pgtype.DataType{
+			Value: pgtype.NewArrayType("_"+t.Name, oid, func() pgtype.ValueTranscoder {
+				return pgtype.NewValue(element).(pgtype.ValueTranscoder)
+			}),
+			Name: "_" + t.Name,
+			OID:  arrayOID,
+		})
+	}
+	if t.Value != nil {
+		ci.RegisterDefaultPgType(t.Value, t.Name)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCitextEntity struct {
+	ID       string  `db:"id"`
+	Email    string  `db:"email"`
+	Nickname *string `db:"nickname"`
+}
+
+func TestRegisterCitext(t *testing.T) {
+	conn := connect(t)
+
+	_, err := conn.Exec(context.Background(), `CREATE EXTENSION IF NOT EXISTS citext`)
+	require.NoError(t, err)
+	createTable(t, conn, "citext_test", `
+		id       text PRIMARY KEY,
+		email    citext not null,
+		nickname citext
+	`)
+	_, err = conn.Exec(
+		context.Background(),
+		"INSERT INTO citext_test (id, email, nickname) VALUES ($1, $2, $3), ($4, $5, NULL)",
+		"citext-1", "Foo@Example.com", "Foo",
+		"citext-2", "bar@example.com",
+	)
+	require.NoError(t, err)
+
+	err = RegisterCitext(context.Background(), conn)
+	require.NoError(t, err)
+
+	var result []*testCitextEntity
+	err = Select(
+		context.Background(), conn, &result,
+		"SELECT * FROM citext_test WHERE email IN ($1, $2) ORDER BY id ASC",
+		"foo@example.com", "BAR@EXAMPLE.COM",
+	)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, "Foo@Example.com", result[0].Email)
+	require.NotNil(t, result[0].Nickname)
+	assert.Equal(t, "Foo", *result[0].Nickname)
+
+	assert.Equal(t, "bar@example.com", result[1].Email)
+	assert.Nil(t, result[1].Nickname)
+}
+
+type testMood string
+
+type testUserTypesEntity struct {
+	ID      string     `db:"id"`
+	Mood    testMood   `db:"mood"`
+	Moods   []testMood `db:"moods"`
+	Email   string     `db:"email"`
+	Address string     `db:"address"`
+}
+
+func TestRegisterTypes(t *testing.T) {
+	conn := connect(t)
+
+	_, err := conn.Exec(context.Background(), `
+		DROP TABLE IF EXISTS user_types_test;
+		DROP TYPE IF EXISTS user_types_mood;
+		DROP DOMAIN IF EXISTS user_types_email;
+		DROP TYPE IF EXISTS user_types_address;
+		CREATE TYPE user_types_mood AS ENUM ('sad', 'ok', 'happy');
+		CREATE DOMAIN user_types_email AS text CHECK (VALUE LIKE '%@%');
+		CREATE TYPE user_types_address AS (street text, city text);
+	`)
+	require.NoError(t, err)
+	createTable(t, conn, "user_types_test", `
+		id      text PRIMARY KEY,
+		mood    user_types_mood NOT NULL,
+		moods   user_types_mood[] NOT NULL,
+		email   user_types_email NOT NULL,
+		address user_types_address NOT NULL
+	`)
+
+	register := RegisterTypes(
+		UserType{Name: "user_types_mood", Value: testMood("")},
+		UserType{Name: "user_types_email"},
+		UserType{Name: "user_types_address"},
+	)
+	err = register(context.Background(), conn)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(
+		context.Background(),
+		"INSERT INTO user_types_test (id, mood, moods, email, address) VALUES ($1, $2, $3, $4, ROW('Main St', 'Paris'))",
+		"user-types-1", testMood("happy"), []string{"sad", "ok"}, "foo@example.com",
+	)
+	require.NoError(t, err)
+
+	var result testUserTypesEntity
+	err = Get(context.Background(), conn, &result, "SELECT id, mood, moods, email, address::text AS address FROM user_types_test")
+	require.NoError(t, err)
+	assert.Equal(t, testUserTypesEntity{
+		ID:      "user-types-1",
+		Mood:    "happy",
+		Moods:   []testMood{"sad", "ok"},
+		Email:   "foo@example.com",
+		Address: "(\"Main St\",Paris)",
+	}, result)
+
+	var address struct {
+		Street string
+		City   string
+	}
+	err = conn.QueryRow(context.Background(), "SELECT address FROM user_types_test").Scan(&address)
+	require.NoError(t, err)
+	assert.Equal(t, "Main St", address.Street)
+	assert.Equal(t, "Paris", address.City)
+
+	// test some fail cases
+	err = RegisterTypes(UserType{Name: "user_types_missing"})(context.Background(), conn)
+	require.Error(t, err)
+
+	err = RegisterTypes(UserType{Name: "text"})(context.Background(), conn)
+	require.Error(t, err)
+	assert.Equal(t, "failed to register the text type: not an enum, domain or composite type", err.Error())
+}
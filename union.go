@@ -0,0 +1,79 @@
+package pgxscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// union describes an interface field decoded from a json column into a concrete type
+// picked by the value of a discriminator column.
+type union struct {
+	typeColumn string
+	registry   map[string]reflect.Type
+}
+
+// WithUnion decodes the json or jsonb column field into an interface field, instantiating
+// the type registered in registry for the value of typeColumn in the same row. The
+// discriminator column must be mapped to a field of the destination too.
+//
+// A registered type is assigned as a value if it implements the field interface, and as a
+// pointer otherwise. NULL sets the field to nil, and an unregistered type fails the scan.
+func WithUnion(typeColumn string, registry map[string]reflect.Type, field string) Option {
+	return func(s *Scanner) {
+		s.unions[field] = union{typeColumn: typeColumn, registry: registry}
+	}
+}
+
+// scanTarget returns the destination of the payload column mapped to the field f of v,
+// and the conversion decoding it once the row is scanned.
+func (u union) scanTarget(v, f reflect.Value, columns []string, traversals [][]int) (interface{}, func() error, error) {
+	var typeTraversal []int
+	for i, column := range columns {
+		if column == u.typeColumn {
+			typeTraversal = traversals[i]
+		}
+	}
+	if len(typeTraversal) == 0 {
+		return nil, nil, errors.Errorf("missing union type column %q in dest %s", u.typeColumn, v.Type())
+	}
+
+	payload := new([]byte)
+	return payload, func() error {
+		discriminator := reflect.Indirect(reflectx.FieldByIndexesReadOnly(v, typeTraversal))
+		if !discriminator.IsValid() {
+			return errors.Errorf("union type column %q is NULL", u.typeColumn)
+		}
+		return u.decode(f, fmt.Sprint(discriminator.Interface()), *payload)
+	}, nil
+}
+
+func (u union) decode(dst reflect.Value, discriminator string, payload []byte) error {
+	if payload == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	t, ok := u.registry[discriminator]
+	if !ok {
+		return errors.Errorf("no type registered for %s %q", u.typeColumn, discriminator)
+	}
+
+	ptr := reflect.New(t)
+	if err := json.Unmarshal(payload, ptr.Interface()); err != nil {
+		return errors.Wrapf(err, "failed to decode %s %q", u.typeColumn, discriminator)
+	}
+
+	switch {
+	case t.AssignableTo(dst.Type()):
+		dst.Set(ptr.Elem())
+	case ptr.Type().AssignableTo(dst.Type()):
+		dst.Set(ptr)
+	default:
+		return errors.Errorf("%s registered for %s %q is not assignable to %s", t, u.typeColumn, discriminator, dst.Type())
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package pgxscan
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent interface {
+	eventName() string
+}
+
+type testUserCreated struct {
+	UserID string `json:"user_id"`
+}
+
+func (testUserCreated) eventName() string {
+	return "user_created"
+}
+
+type testOrderPlaced struct {
+	OrderID string `json:"order_id"`
+	Total   int    `json:"total"`
+}
+
+func (*testOrderPlaced) eventName() string {
+	return "order_placed"
+}
+
+type testEventEntity struct {
+	ID      string    `db:"id"`
+	Type    string    `db:"type"`
+	Payload testEvent `db:"payload"`
+}
+
+func TestScannerUnion(t *testing.T) {
+	conn := connect(t)
+
+	createTable(t, conn, "union_test", `
+		id      text PRIMARY KEY,
+		type    text not null,
+		payload jsonb
+	`)
+	_, err := conn.Exec(context.Background(), `
+		INSERT INTO union_test (id, type, payload) VALUES
+			('union-1', 'user_created', '{"user_id": "u1"}'),
+			('union-2', 'order_placed', '{"order_id": "o1", "total": 42}'),
+			('union-3', 'user_created', NULL),
+			('union-4', 'user_deleted', '{"user_id": "u1"}')
+	`)
+	require.NoError(t, err)
+
+	scanner := New(WithUnion("type", map[string]reflect.Type{
+		"user_created": reflect.TypeOf(testUserCreated{}),
+		"order_placed": reflect.TypeOf(testOrderPlaced{}),
+	}, "payload"))
+
+	var result []*testEventEntity
+	err = scanner.Select(context.Background(), conn, &result, "SELECT * FROM union_test WHERE id <> $1 ORDER BY id ASC", "union-4")
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	assert.Equal(t, testUserCreated{UserID: "u1"}, result[0].Payload)
+	assert.Equal(t, &testOrderPlaced{OrderID: "o1", Total: 42}, result[1].Payload)
+	assert.Nil(t, result[2].Payload)
+
+	// test some fail cases
+	resultFail := new(testEventEntity)
+	err = scanner.Get(context.Background(), conn, resultFail, "SELECT * FROM union_test WHERE id = $1", "union-4")
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "payload" (oid 3802) of row 0 into field Payload of type pgxscan.testEvent: no type registered for type "user_deleted"`, err.Error())
+
+	err = scanner.Get(context.Background(), conn, resultFail, "SELECT id, payload FROM union_test WHERE id = $1", "union-1")
+	require.Error(t, err)
+	assert.Equal(t, `missing union type column "type" in dest *pgxscan.testEventEntity`, err.Error())
+}
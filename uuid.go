@@ -0,0 +1,32 @@
+package pgxscan
+
+import (
+	"reflect"
+
+	"github.com/jackc/pgtype"
+)
+
+// uuidTarget returns the scan destination of the field f and the conversion decoding it, if f
+// is a 16 byte array, such as a google/uuid or gofrs/uuid UUID, or a pointer to one, and oid the
+// uuid type. The bytes are copied as is, instead of being parsed back from the text of the uuid
+// when the array type implements sql.Scanner, or failing when it does not. pgtype assigns uuids
+// to string and []byte fields itself.
+func uuidTarget(f reflect.Value, oid uint32) (interface{}, func() error, bool) {
+	t := f.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if oid != pgtype.UUIDOID || t.Kind() != reflect.Array || t.Len() != 16 || t.Elem().Kind() != reflect.Uint8 {
+		return nil, nil, false
+	}
+
+	holder := new(pgtype.UUID)
+	return holder, func() error {
+		if holder.Status != pgtype.Present {
+			return setNull(f)
+		}
+
+		setValue(f, reflect.ValueOf(holder.Bytes).Convert(t))
+		return nil
+	}, true
+}
@@ -0,0 +1,80 @@
+package pgxscan
+
+import (
+	"context"
+	"testing"
+
+	gofrs "github.com/gofrs/uuid"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAccountID [16]byte
+
+type testUUIDEntity struct {
+	ID        uuid.UUID     `db:"id"`
+	AccountID testAccountID `db:"account_id"`
+	ParentID  *[16]byte     `db:"parent_id"`
+	OwnerID   *gofrs.UUID   `db:"owner_id"`
+	Ref       string        `db:"ref"`
+}
+
+func TestScanStructsUUID(t *testing.T) {
+	conn := connect(t)
+
+	id := uuid.New()
+	accountID := uuid.New()
+	ownerID := uuid.New()
+
+	var result []testUUIDEntity
+	err := Select(context.Background(), conn, &result, `
+		SELECT * FROM (VALUES
+			($1::uuid, $2::uuid, $1::uuid, $3::uuid, $1::uuid),
+			($2::uuid, $2::uuid, NULL::uuid, NULL::uuid, $2::uuid)
+		) AS t (id, account_id, parent_id, owner_id, ref)
+		ORDER BY ref = $1 DESC
+	`, id.String(), accountID.String(), ownerID.String())
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, id, result[0].ID)
+	assert.Equal(t, testAccountID(accountID), result[0].AccountID)
+	require.NotNil(t, result[0].ParentID)
+	assert.Equal(t, [16]byte(id), *result[0].ParentID)
+	require.NotNil(t, result[0].OwnerID)
+	assert.Equal(t, gofrs.UUID(ownerID), *result[0].OwnerID)
+	assert.Equal(t, id.String(), result[0].Ref)
+
+	assert.Equal(t, accountID, result[1].ID)
+	assert.Nil(t, result[1].ParentID)
+	assert.Nil(t, result[1].OwnerID)
+
+	// test some fail cases
+	var null testUUIDEntity
+	err = Get(context.Background(), conn, &null, `SELECT NULL::uuid AS id, $1::uuid AS account_id, NULL::uuid AS parent_id, NULL::uuid AS owner_id, $1::uuid AS ref`, id.String())
+	require.Error(t, err)
+	assert.Equal(t, `failed to scan column "id" (oid 2950) of row 0 into field ID of type uuid.UUID: cannot scan NULL into uuid.UUID`, err.Error())
+}
+
+func TestScanStructUUIDText(t *testing.T) {
+	type testUUIDData struct {
+		ID        string        `db:"id"`
+		CreatedAt testAccountID `db:"created_at"`
+		SomeData  uuid.UUID     `db:"some_data"`
+	}
+
+	id := uuid.New()
+	rows := newFakeRows(1)
+	rows.fields[1].DataTypeOID = pgtype.UUIDOID
+	rows.fields[2].DataTypeOID = pgtype.UUIDOID
+	rows.rows[0][1] = []byte(id.String())
+	rows.rows[0][2] = []byte(id.String())
+
+	var result testUUIDData
+	err := ScanStruct(rows, &result)
+	require.NoError(t, err)
+	assert.Equal(t, testAccountID(id), result.CreatedAt)
+	assert.Equal(t, id, result.SomeData)
+}
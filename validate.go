@@ -0,0 +1,161 @@
+package pgxscan
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// ValidateStruct checks the mapping of the struct prototype against table, see Scanner.ValidateStruct.
+func ValidateStruct(ctx context.Context, querier Querier, table string, prototype interface{}) error {
+	return defaultScanner.ValidateStruct(ctx, querier, table, prototype)
+}
+
+// ValidateStruct checks the fields of the struct prototype with a tag naming their column against
+// the columns of table, which may be qualified by a schema, as listed by information_schema, so
+// that a mapping drifting from the schema is caught at startup or in integration tests instead of
+// when scanning. Every tagged field must have a column, a nullable column must be scanned into a
+// field that can hold NULL, such as a pointer, and the Go type of the field must suit the type of
+// the column. The types pgx decodes themselves, such as sql.Scanner implementations, and the
+// fields with the json option are assumed to suit any column.
+//
+// The error lists every mismatch.
+func (s *Scanner) ValidateStruct(ctx context.Context, querier Querier, table string, prototype interface{}) error {
+	t := reflect.TypeOf(prototype)
+	if t == nil || reflectx.Deref(t).Kind() != reflect.Struct {
+		return errors.Errorf("expected a struct or a pointer to a struct, got %T", prototype)
+	}
+	t = reflectx.Deref(t)
+
+	schema, name := "", table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		schema, name = table[:i], table[i+1:]
+	}
+	rows, err := querier.Query(ctx, `
+		SELECT column_name, is_nullable = 'YES', data_type, udt_name
+		FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+	`, schema, name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the columns of table %q", table)
+	}
+	type column struct {
+		nullable         bool
+		dataType, udName string
+	}
+	columns := make(map[string]column)
+	for rows.Next() {
+		var name string
+		var c column
+		if err := rows.Scan(&name, &c.nullable, &c.dataType, &c.udName); err != nil {
+			rows.Close()
+			return err
+		}
+		columns[name] = c
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return errors.Errorf("table %q does not exist", table)
+	}
+
+	var mismatches []string
+	for _, fi := range s.columnFields(t) {
+		if !s.tagged(fi.Field) {
+			continue
+		}
+		c, ok := columns[fi.Path]
+		if !ok {
+			mismatches = append(mismatches, "no column "+fi.Path+" for field "+fi.Field.Name)
+			continue
+		}
+		if _, ok := fi.Options["json"]; ok || s.decodesItself(fi.Field.Type) {
+			continue
+		}
+		if c.nullable && !holdsNull(fi.Field.Type) {
+			mismatches = append(mismatches, "nullable column "+fi.Path+" is scanned into field "+fi.Field.Name+" of type "+fi.Field.Type.String())
+		}
+		if !suitsColumn(fi.Field.Type, c.dataType, c.udName) {
+			mismatches = append(mismatches, "column "+fi.Path+" of type "+c.udName+" is scanned into field "+fi.Field.Name+" of type "+fi.Field.Type.String())
+		}
+	}
+	if len(mismatches) > 0 {
+		return errors.Errorf("struct %s does not match table %q: %s", t, table, strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	bytesType   = reflect.TypeOf([]byte(nil))
+)
+
+// decodesItself reports whether the values of type t are decoded by themselves or by a
+// registered converter, which decides what they accept.
+func (s *Scanner) decodesItself(t reflect.Type) bool {
+	if _, ok := s.converterFunc(t); ok {
+		return true
+	}
+	t = reflectx.Deref(t)
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+	p := reflect.PtrTo(t)
+	return p.Implements(scannerType) || isDecoder(reflect.New(t).Interface())
+}
+
+// holdsNull reports whether a field of type t can be set to NULL.
+func holdsNull(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// suitsColumn reports whether a field of type t can be scanned from a column of the type named
+// dataType and udtName in information_schema. Unknown column types suit any field.
+func suitsColumn(t reflect.Type, dataType, udtName string) bool {
+	if t == writerType {
+		return udtName == "bytea"
+	}
+	t = reflectx.Deref(t)
+	if dataType == "ARRAY" {
+		return t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+	}
+
+	kind := t.Kind()
+	isInt := kind >= reflect.Int && kind <= reflect.Uint64
+	isFloat := kind == reflect.Float32 || kind == reflect.Float64
+	switch udtName {
+	case "int2", "int4", "int8", "oid":
+		return isInt || isFloat || kind == reflect.String
+	case "float4", "float8":
+		return isFloat || kind == reflect.String
+	case "numeric":
+		return isFloat || isInt || kind == reflect.String || t == ratType
+	case "bool":
+		return kind == reflect.Bool
+	case "text", "varchar", "bpchar", "name", "citext":
+		return kind == reflect.String || t == bytesType
+	case "bytea":
+		return t == bytesType || kind == reflect.String
+	case "uuid":
+		return kind == reflect.String || (kind == reflect.Array && t.Len() == 16) || t == bytesType
+	case "timestamp", "timestamptz", "date":
+		return t == timeType || kind == reflect.String
+	case "interval":
+		return t == intervalType || kind == reflect.Int64 || kind == reflect.String
+	case "inet", "cidr":
+		return t == ipType || t == ipNetType || t == addrType || t == prefixType || kind == reflect.String
+	case "json", "jsonb":
+		return true
+	}
+	return true
+}
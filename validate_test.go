@@ -0,0 +1,85 @@
+package pgxscan
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStruct(t *testing.T) {
+	conn := connect(t)
+	createTable(t, conn, "validate_test", `
+		id         serial PRIMARY KEY,
+		name       text        NOT NULL,
+		email      text,
+		score      numeric,
+		tags       text[],
+		data       jsonb,
+		created_at timestamptz NOT NULL DEFAULT now()
+	`)
+
+	type valid struct {
+		ID        int64          `db:"id"`
+		Name      string         `db:"name"`
+		Email     sql.NullString `db:"email"`
+		Score     *float64       `db:"score"`
+		Tags      []string       `db:"tags"`
+		Data      map[string]int `db:"data"`
+		CreatedAt time.Time      `db:"created_at"`
+		Ignored   string
+	}
+	err := ValidateStruct(context.Background(), conn, "validate_test", &valid{})
+	require.NoError(t, err)
+
+	err = ValidateStruct(context.Background(), conn, "public.validate_test", valid{})
+	require.NoError(t, err)
+
+	// test some fail cases
+	type invalid struct {
+		ID        string    `db:"id"`
+		Name      string    `db:"name"`
+		Email     string    `db:"email"`
+		Missing   int       `db:"missing"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	err = ValidateStruct(context.Background(), conn, "validate_test", &invalid{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nullable column email")
+	assert.Contains(t, err.Error(), "no column missing")
+	assert.NotContains(t, err.Error(), "column name")
+
+	err = ValidateStruct(context.Background(), conn, "validate_test_missing", &valid{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+
+	err = ValidateStruct(context.Background(), conn, "validate_test", 1)
+	require.Error(t, err)
+}
+
+func TestSuitsColumn(t *testing.T) {
+	for _, tc := range []struct {
+		value    interface{}
+		udtName  string
+		expected bool
+	}{
+		{int32(1), "int8", true},
+		{1.5, "numeric", true},
+		{"foo", "int4", true},
+		{"foo", "bool", false},
+		{true, "text", false},
+		{[]byte("foo"), "bytea", true},
+		{[16]byte{}, "uuid", true},
+		{time.Time{}, "timestamptz", true},
+		{time.Time{}, "int8", false},
+		{1, "tsvector", true},
+	} {
+		assert.Equal(t, tc.expected, suitsColumn(reflect.TypeOf(tc.value), "", tc.udtName), "%T %s", tc.value, tc.udtName)
+	}
+	assert.True(t, suitsColumn(reflect.TypeOf([]string{}), "ARRAY", "_text"))
+	assert.False(t, suitsColumn(reflect.TypeOf(""), "ARRAY", "_text"))
+}